@@ -0,0 +1,138 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// sourceCredit says where the door's entries come from, as Wikipedia's
+// license asks.
+const sourceCredit = "Events, births, deaths, holidays, the featured article and the news come " +
+	"from Wikipedia, through the Wikimedia API. Wikipedia's text is available under the " +
+	"Creative Commons Attribution-ShareAlike 4.0 license (CC BY-SA 4.0)."
+
+// aboutScreen is the screen saying which build of the door this is, where
+// its entries come from and how it is set up for the session, for callers
+// curious about it and sysops asked about it.
+func aboutScreen(termCfg terminal.TerminalConfig, bindings *keymap.Map, date time.Time, save func(terminal.Page)) pluginScreen {
+	return pluginScreen{
+		action: keymap.About,
+		label:  "About",
+		help:   "About this door: its version and sources",
+		open: func([]terminal.Page) view {
+			return &aboutView{termCfg: termCfg, bindings: bindings, save: save, page: aboutPage(termCfg, date)}
+		},
+	}
+}
+
+// aboutPage is the about screen's page.
+func aboutPage(termCfg terminal.TerminalConfig, date time.Time) terminal.Page {
+	rev, built := buildStamp()
+	ver := version
+	if rev != "" {
+		ver += " (" + rev + ")"
+	}
+	field := func(name, value string) string {
+		return fmt.Sprintf("%-9s %s", name, value)
+	}
+	lines := []string{
+		"Glimpse In Time, a This Day in History door by <PHEN0M>",
+		"",
+		field("Version", ver+" "+runtime.GOOS+"/"+runtime.GOARCH),
+		field("Built", cmp.Or(built, "unknown")),
+		field("BBS", displayText(cmp.Or(termCfg.BbsName, "unknown"), termCfg.Charset)),
+		field("Theme", themeName(termCfg)),
+		"",
+	}
+	lines = append(lines, textwrap.Wrap(sourceCredit, terminal.LinesWidth)...)
+	lines = append(lines, "", "Source: https://github.com/robbiew/This-Day-in-History-Door")
+	return terminal.Page{Kind: terminal.KindPlugin, Date: date, Title: "About", Lines: lines}
+}
+
+// themeName describes how the session's screens are drawn.
+func themeName(termCfg terminal.TerminalConfig) string {
+	years := "era"
+	if termCfg.GradientYears {
+		years = "gradient"
+	}
+	colors := "16"
+	if termCfg.ExtendedPalette {
+		colors = "256"
+	}
+	return fmt.Sprintf("%s year colors, %s characters, %s-color palette", years, strings.ToUpper(string(termCfg.Charset)), colors)
+}
+
+// aboutView shows the about screen; any key without a binding goes back.
+type aboutView struct {
+	termCfg  terminal.TerminalConfig
+	bindings *keymap.Map
+	save     func(terminal.Page)
+	page     terminal.Page
+}
+
+func (v *aboutView) draw() {
+	page := v.page
+	page.Commands = v.commands()
+	terminal.RenderEvents(v.termCfg, page)
+}
+
+func (v *aboutView) handle(_ input.Event, action keymap.Action) step {
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Save:
+		if v.save == nil {
+			return back
+		}
+		v.save(v.page)
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: v.help()})
+	}
+	return back
+}
+
+// aboutActions are the actions the about screen answers to, with their
+// labels there; any other key goes back.
+var aboutActions = []struct {
+	action keymap.Action
+	label  string
+	help   string
+}{
+	{keymap.Next, "Back", "Back to the day's screens"},
+	{keymap.Save, "Save", "Save this screen to your downloads"},
+	{keymap.Help, "Help", "This help"},
+	{keymap.Quit, "Quit", "Back to the BBS"},
+}
+
+func (v *aboutView) commands() []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range aboutActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		if key := barKey(v.bindings.Keys(a.action), a.label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.label})
+		}
+	}
+	return commands
+}
+
+func (v *aboutView) help() []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range aboutActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: v.bindings.Keys(a.action), Text: a.help})
+	}
+	return entries
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/webhook"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// newAlerts returns the notifier for the sysop's webhook, naming session's
+// board and node as where the trouble is, or nil without a webhook.
+func newAlerts(url, format string, session *dropfile.DoorSession) *webhook.Notifier {
+	return webhook.New(url, format, session.BbsName+" node "+strconv.Itoa(session.Node))
+}
+
+// watchClient has alerts told when wikiClient gives up on the API after
+// repeated failures and when it finds a cached response damaged.
+func watchClient(wikiClient *wikimedia.Client, alerts *webhook.Notifier) {
+	if alerts == nil {
+		return
+	}
+	wikiClient.OnCircuitOpen(func(failures int, until time.Time) {
+		alerts.Notify(webhook.APIFailures, fmt.Sprintf("%d fetches in a row from the Wikimedia API failed; callers are served from the cache until %s.", failures, until.Format(time.Kitchen)))
+	})
+	wikiClient.OnCacheDamage(func(path string, err error) {
+		alerts.Notify(webhook.CacheDamage, fmt.Sprintf("Cached response %s was damaged and is being fetched again: %v", filepath.Base(path), err))
+	})
+}
+
+// alertPanic, deferred, tells alerts about a panic before letting it carry on
+// and crash the door as usual.
+func alertPanic(alerts *webhook.Notifier) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if alerts != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		alerts.Send(ctx, webhook.Panic, fmt.Sprintf("The door crashed: %v\n\n%s", r, debug.Stack()))
+		cancel()
+	}
+	panic(r)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// anniversaryStep is the smallest round anniversary: 25 years, then 50, 75,
+// 100 and on.
+const anniversaryStep = 25
+
+// roundAnniversary returns how many years ago year was in thisYear when
+// that is a round anniversary, a multiple of anniversaryStep, and 0 when
+// it is not. BC years are never round: the feed's count of them is off by
+// one across the missing year 0, and a round number of millennia reads as
+// a coincidence rather than an anniversary anyway.
+func roundAnniversary(year, thisYear int) int {
+	ago := thisYear - year
+	if year <= 0 || ago <= 0 || ago%anniversaryStep != 0 {
+		return 0
+	}
+	return ago
+}
+
+// roundness ranks an anniversary: hundreds above fifties above the rest.
+func roundness(ago int) int {
+	switch {
+	case ago%100 == 0:
+		return 3
+	case ago%50 == 0:
+		return 2
+	case ago > 0:
+		return 1
+	}
+	return 0
+}
+
+// selectAnniversaries picks up to five events, the roundest anniversaries
+// in thisYear first, ties broken by rng, and makes up the rest as the
+// era-based strategy does. They come back sorted by year.
+func selectAnniversaries(events []wikimedia.Event, thisYear int, rng *rand.Rand) []wikimedia.Event {
+	var round, rest []wikimedia.Event
+	for _, e := range events {
+		if roundAnniversary(e.Year, thisYear) > 0 {
+			round = append(round, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	rng.Shuffle(len(round), func(i, j int) { round[i], round[j] = round[j], round[i] })
+	sort.SliceStable(round, func(i, j int) bool {
+		return roundness(roundAnniversary(round[i].Year, thisYear)) > roundness(roundAnniversary(round[j].Year, thisYear))
+	})
+	selected := round[:min(len(round), 5)]
+	if len(selected) < 5 {
+		filler := selectEventsByEra(rest, rng)
+		selected = append(selected, filler[:min(len(filler), 5-len(selected))]...)
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Year < selected[j].Year })
+	return selected
+}
+
+// markAnniversaries tags the events that are a round anniversary in
+// thisYear, for the screens to call out.
+func markAnniversaries(events []terminal.Event, thisYear int) {
+	for i := range events {
+		events[i].Anniversary = roundAnniversary(events[i].Year, thisYear)
+	}
+}
+
+// calendarYear is day's year, or this year for a day with none, as from
+// -date.
+func calendarYear(day time.Time) int {
+	if day.Year() == 0 {
+		return time.Now().Year()
+	}
+	return day.Year()
+}
+
+// datedDay is day in calendarYear, for what shows or passes on a whole
+// date. A -date of 02-29 in a year without one is dated the last leap year
+// rather than rolled over to March 1, as time.Date would.
+func datedDay(day time.Time) time.Time {
+	if day.Year() != 0 {
+		return day
+	}
+	year := calendarYear(day)
+	for isLeapDay(day) && !isLeapDay(time.Date(year, time.February, 29, 0, 0, 0, 0, time.Local)) {
+		year--
+	}
+	return time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// marksAnniversaries reports whether round anniversaries are called out:
+// always with the anniversary strategy, and with any other when the sysop
+// asks.
+func marksAnniversaries(strategy string, always bool) bool {
+	return always || strategy == "anniversary"
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// runBench implements "history bench": it draws each kind of screen n times
+// from sample events, discarding the output, and reports how fast and how
+// many allocations each took, so renderer changes can be compared across
+// releases and boards. It returns the process exit code.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	n := fs.Int("n", 1000, "screens to draw of each kind")
+	charset := fs.String("charset", "cp437", "charset to draw in: "+strings.Join(config.Charsets[1:], "|"))
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *n <= 0 || !slices.Contains(config.Charsets[1:], *charset) {
+		fs.Usage()
+		return 2
+	}
+	bench(os.Stdout, terminal.TerminalConfig{Charset: terminal.Charset(*charset), ShowLinks: true}, *n)
+	return 0
+}
+
+func bench(w io.Writer, termCfg terminal.TerminalConfig, n int) {
+	fmt.Fprintf(w, "%d screens of each kind in %s, %s %s/%s\n\n", n, termCfg.Charset, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, "%-8s %12s %10s %10s %12s %10s\n", "screen", "per screen", "screens/s", "allocs", "alloc bytes", "output")
+	for _, s := range sampleScreens(termCfg, time.Date(2000, time.October, 16, 0, 0, 0, 0, time.Local))[:3] {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		size := 0
+		for range n {
+			size = len(terminal.Draw(termCfg, s.page))
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		fmt.Fprintf(w, "%-8s %12v %10.0f %10d %12d %10d\n", s.name,
+			(elapsed / time.Duration(n)).Round(100*time.Nanosecond),
+			float64(n)/elapsed.Seconds(),
+			(after.Mallocs-before.Mallocs)/uint64(n),
+			(after.TotalAlloc-before.TotalAlloc)/uint64(n),
+			size)
+	}
+}
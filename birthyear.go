@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/robbiew/history/internal/birthyears"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/notice"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// openBirthYears opens the callers' birth years at path. It returns nil,
+// which leaves the birth year screen off, when path is empty. A file that
+// can't be read is logged and starts empty.
+func openBirthYears(path string) *birthyears.File {
+	years, err := birthyears.Open(path)
+	if err != nil {
+		slog.Warn("could not read birth years", "file", path, "error", err)
+	}
+	return years
+}
+
+// birthYearScreen is the screen of the day in the year user was born, which
+// asks them for the year the first time and keeps it in years.
+func birthYearScreen(termCfg terminal.TerminalConfig, bindings *keymap.Map, notices *notice.Set, years *birthyears.File, user string, date time.Time, save func(terminal.Page), summarize summaryFunc) pluginScreen {
+	return pluginScreen{
+		action: keymap.BirthYear,
+		label:  "Your year",
+		help:   "This day in the year you were born",
+		open: func(pages []terminal.Page) view {
+			v := &birthYearView{termCfg: termCfg, bindings: bindings, notices: notices, years: years, user: user, date: date, save: save, summarize: summarize, pages: pages}
+			v.year, _ = years.Get(user)
+			return v
+		},
+	}
+}
+
+// birthYearView shows the day's events, births and deaths from the year the
+// caller was born. Until it knows the year it asks for it: digits type it,
+// Backspace takes one back and Enter looks it up. Then the detail keys open
+// an entry, Refresh asks again, and any key without a binding goes back.
+type birthYearView struct {
+	termCfg   terminal.TerminalConfig
+	bindings  *keymap.Map
+	notices   *notice.Set
+	years     *birthyears.File
+	user      string
+	date      time.Time
+	save      func(terminal.Page)
+	summarize summaryFunc
+	pages     []terminal.Page // the day's, whose entries are looked through
+
+	year    int    // 0 while asking
+	typed   string // the year typed so far
+	problem string // why the year typed last was turned down
+	page    terminal.Page
+}
+
+// maxYearDigits is the most digits a typed year takes.
+const maxYearDigits = 4
+
+func (v *birthYearView) draw() {
+	if v.year == 0 {
+		v.page = v.prompt()
+	} else {
+		v.page = v.lookUp()
+		v.page.Commands = v.commands()
+	}
+	terminal.RenderEvents(v.termCfg, v.page)
+}
+
+// prompt is the page asking for the year.
+func (v *birthYearView) prompt() terminal.Page {
+	lines := []string{
+		"What year were you born?",
+		"",
+		"Year: " + v.typed + "_",
+		"",
+		"Type it and press Enter, and it's remembered for your next visit. Any other key goes back.",
+	}
+	if v.problem != "" {
+		lines = append(lines, "", v.problem)
+	}
+	commands := []terminal.Command{{Key: "Enter", Label: "Show"}}
+	if key := barKey(v.bindings.Keys(keymap.Quit), "Quit"); key != "" {
+		commands = append(commands, terminal.Command{Key: key, Label: "Quit"})
+	}
+	return terminal.Page{Kind: terminal.KindNotice, Date: v.date, Title: "The Year You Were Born", Lines: lines, Commands: commands}
+}
+
+// lookUp is the page of the day's entries from v.year, or the notice saying
+// there are none.
+func (v *birthYearView) lookUp() terminal.Page {
+	page := terminal.Page{Kind: terminal.KindBirthYear, Date: v.date, Title: strconv.Itoa(v.year)}
+	for _, p := range v.pages {
+		prefix := ""
+		switch p.Kind {
+		case terminal.KindEvents, "":
+		case terminal.KindBirths:
+			prefix = "Born: "
+		case terminal.KindDeaths:
+			prefix = "Died: "
+		default:
+			continue
+		}
+		entries := p.Pool
+		if len(entries) == 0 {
+			entries = p.Events
+		}
+		for _, e := range entries {
+			if e.Year == v.year {
+				e.Text = prefix + e.Text
+				page.Events = append(page.Events, e)
+			}
+		}
+	}
+	if len(page.Events) == 0 {
+		return noticePage(v.termCfg, v.notices, notice.BirthYear, notice.Data{
+			Date:       v.date,
+			Year:       v.year,
+			RefreshKey: barKey(v.bindings.Keys(keymap.Refresh), "Other year"),
+			Now:        time.Now(),
+		})
+	}
+	return page
+}
+
+func (v *birthYearView) handle(ev input.Event, action keymap.Action) step {
+	if v.year == 0 {
+		return v.handlePrompt(ev, action)
+	}
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Refresh:
+		v.year, v.typed, v.problem = 0, "", ""
+		return redraw
+	case keymap.Save:
+		if v.save == nil {
+			return back
+		}
+		v.save(v.page)
+		return stay
+	case keymap.Detail:
+		shown := terminal.Shown()
+		if i := slices.Index(v.bindings.Keys(keymap.Detail), ev.String()); i >= 0 && i < len(shown) {
+			return open(newDetailView(v.termCfg, v.bindings, v.page, shown[i], v.save, nil, v.summarize))
+		}
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: v.help()})
+	}
+	return back
+}
+
+// handlePrompt acts on a key while the year is being asked for.
+func (v *birthYearView) handlePrompt(ev input.Event, action keymap.Action) step {
+	switch {
+	case ev.Key == input.KeyRune && ev.Rune >= '0' && ev.Rune <= '9':
+		if len(v.typed) < maxYearDigits {
+			v.typed += string(ev.Rune)
+		}
+		return redraw
+	case ev.Key == input.KeyBackspace:
+		v.typed = v.typed[:max(len(v.typed)-1, 0)]
+		return redraw
+	case ev.Key == input.KeyEnter:
+		year, _ := strconv.Atoi(v.typed)
+		if this := calendarYear(v.date); year < 1 || year > this {
+			v.problem = fmt.Sprintf("Give a year from 1 to %d.", this)
+			v.typed = ""
+			return redraw
+		}
+		v.year = year
+		if err := v.years.Set(v.user, year); err != nil {
+			slog.Warn("could not save birth year", "user", v.user, "error", err)
+		}
+		return redraw
+	case action == keymap.Quit:
+		return quit
+	}
+	return back
+}
+
+// birthYearActions are the actions the birth year screen answers to once
+// it has the year, with their labels there; any other key goes back.
+var birthYearActions = []struct {
+	action keymap.Action
+	label  string
+	help   string
+}{
+	{keymap.Next, "Back", "Back to the day's screens"},
+	{keymap.Refresh, "Other year", "Look up another year"},
+	{keymap.Save, "Save", "Save this screen to your downloads"},
+	{keymap.Help, "Help", "This help"},
+	{keymap.Quit, "Quit", "Back to the BBS"},
+}
+
+func (v *birthYearView) commands() []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range birthYearActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		if key := barKey(v.bindings.Keys(a.action), a.label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.label})
+		}
+	}
+	return commands
+}
+
+func (v *birthYearView) help() []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range birthYearActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: v.bindings.Keys(a.action), Text: a.help})
+	}
+	return entries
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// browse runs the door's screens until the caller leaves, starting with
+// the day's pages. A nil save turns the save key into an ordinary key, and a
+// nil rate does the same to the like and dislike keys on detail pages, and
+// summarize, when set, gives detail pages their article's summary; record
+// is told the events that fit on each page drawn; plugins can be opened
+// from any of the day's pages, and so can egg, when there is one. Every
+// key winds idle back. It
+// returns nil when the caller quits or pages past the end, or once ctx,
+// the session's, has ended.
+func browse(ctx context.Context, termCfg terminal.TerminalConfig, keys *input.Decoder, bindings *keymap.Map, idle *idleClock, load func() []terminal.Page, save func(terminal.Page), rate rateFunc, summarize summaryFunc, record func(string, []terminal.Event), plugins []pluginScreen, egg *easterEgg) error {
+	return runViews(ctx, keys, bindings, idle, &pagesView{termCfg: termCfg, bindings: bindings, load: load, save: save, rate: rate, summarize: summarize, record: record, plugins: plugins, egg: egg})
+}
+
+// pagesView is the day's pages: keys move through the pages that load
+// returns, refresh loads them again, save hands the current page to save,
+// help opens the help screen, and a plugin's keys open its screen, as
+// typing egg's run of keys opens it. Events that fit on a page are passed
+// to record as it is drawn. Paging past the last page closes it.
+type pagesView struct {
+	termCfg   terminal.TerminalConfig
+	bindings  *keymap.Map
+	load      func() []terminal.Page
+	save      func(terminal.Page)
+	rate      rateFunc
+	summarize summaryFunc
+	record    func(string, []terminal.Event)
+	plugins   []pluginScreen
+	egg       *easterEgg
+
+	pages []terminal.Page
+	cur   int
+}
+
+func (v *pagesView) draw() {
+	if len(v.pages) == 0 {
+		v.pages, v.cur = v.load(), 0
+		if len(v.pages) == 0 {
+			return
+		}
+	}
+	page := v.pages[v.cur]
+	if page.Kind == terminal.KindNotice {
+		page.Commands = noticeBar(v.bindings, len(v.pages) > 1)
+	} else {
+		page.Commands = commandBar(v.bindings, v.pages, v.cur, v.save != nil, v.plugins)
+	}
+	terminal.RenderEvents(v.termCfg, page)
+	v.record(page.Kind, terminal.Shown())
+}
+
+func (v *pagesView) handle(ev input.Event, action keymap.Action) step {
+	if len(v.pages) > 0 && v.pages[v.cur].Kind == terminal.KindNotice {
+		return v.handleNotice(action)
+	}
+	if v.egg != nil {
+		// The egg's keys are unbound, so typing them doesn't page on
+		switch typing, done := v.egg.press(ev); {
+		case done:
+			return open(v.egg.open())
+		case typing:
+			return stay
+		}
+	}
+	if action == keymap.Save && (v.save == nil || len(v.pages) == 0) {
+		action = keymap.Next
+	}
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Refresh:
+		v.pages, v.cur = nil, 0
+		return redraw
+	case keymap.Save:
+		v.save(v.pages[v.cur])
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: helpEntries(v.bindings, v.pages, v.save != nil, v.plugins)})
+	case keymap.Events, keymap.Births, keymap.Deaths, keymap.Holidays, keymap.Featured, keymap.News:
+		// Jump to that screen if today has one
+		if i := slices.IndexFunc(v.pages, func(p terminal.Page) bool { return p.Kind == string(action) }); i >= 0 {
+			v.cur = i
+			return redraw
+		}
+		return stay
+	case keymap.Detail:
+		// The key's place among the detail keys picks the event by its number
+		shown := terminal.Shown()
+		if i := slices.Index(v.bindings.Keys(keymap.Detail), ev.String()); i >= 0 && i < len(shown) {
+			return open(newDetailView(v.termCfg, v.bindings, v.pages[v.cur], shown[i], v.save, v.rate, v.summarize))
+		}
+		return stay
+	case keymap.More:
+		if more := newMoreView(v.termCfg, v.bindings, v.pages[v.cur], terminal.Shown(), v.save, v.record); more != nil {
+			return open(more)
+		}
+		return stay
+	}
+	if i := slices.IndexFunc(v.plugins, func(p pluginScreen) bool { return p.action == action }); i >= 0 {
+		return open(v.plugins[i].open(v.pages))
+	}
+	if v.cur+1 >= len(v.pages) {
+		return back
+	}
+	v.cur++
+	return redraw
+}
+
+// handleNotice acts on a key on a notice page, which stands in for the
+// day's pages when fetching them failed or found nothing. The caller can
+// try again, go on to the saved copy that follows the notice when there is
+// one, or quit; any other key leaves, as paging past the end does.
+func (v *pagesView) handleNotice(action keymap.Action) step {
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Refresh:
+		v.pages, v.cur = nil, 0
+		return redraw
+	case keymap.Cached:
+		if len(v.pages) > 1 {
+			v.pages = v.pages[1:]
+			return redraw
+		}
+		return stay
+	}
+	return back
+}
+
+// noticeBar lists the actions open to the caller on a notice page: trying
+// again, the saved copy when there is one, and quitting.
+func noticeBar(bindings *keymap.Map, cached bool) []terminal.Command {
+	commands := []terminal.Command{{Key: barKey(bindings.Keys(keymap.Refresh), "Retry"), Label: "Retry"}}
+	if cached {
+		commands = append(commands, terminal.Command{Key: barKey(bindings.Keys(keymap.Cached), "Cached copy"), Label: "Cached copy"})
+	}
+	commands = append(commands, terminal.Command{Key: barKey(bindings.Keys(keymap.Quit), "Quit"), Label: "Quit"})
+	return slices.DeleteFunc(commands, func(c terminal.Command) bool { return c.Key == "" })
+}
+
+// helpEntries lists the bindings for the help screen, leaving out screens
+// the caller doesn't have today, and saving when it is off. Plugins come
+// after the day's screens.
+func helpEntries(bindings *keymap.Map, pages []terminal.Page, saving bool, plugins []pluginScreen) []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range keymap.Actions {
+		switch a.Action {
+		case keymap.Refresh:
+			for _, p := range plugins {
+				entries = append(entries, terminal.HelpEntry{Keys: bindings.Keys(p.action), Text: cmp.Or(p.help, p.label)})
+			}
+		case keymap.Save:
+			if !saving {
+				continue
+			}
+		case keymap.Events, keymap.Births, keymap.Deaths, keymap.Holidays, keymap.Featured, keymap.News:
+			if !slices.ContainsFunc(pages, func(p terminal.Page) bool { return p.Kind == string(a.Action) }) {
+				continue
+			}
+		case keymap.More:
+			if !slices.ContainsFunc(pages, hasMore) {
+				continue
+			}
+		case keymap.Up, keymap.Down, keymap.Like, keymap.Dislike, keymap.Cached:
+			// Only for the list the more key opens and detail pages, which
+			// have their own help
+			continue
+		case keymap.BirthYear, keymap.About:
+			// Listed with the plugins when they are on
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: bindings.Keys(a.Action), Text: a.Help})
+	}
+	return entries
+}
+
+// commandBar lists the actions open to the caller on pages[cur]: Next while
+// there is a next page, jumps to the other screens of the day, the detail
+// keys when the page has events, More when it has more, plugins, Save when
+// saving is on, and the rest.
+func commandBar(bindings *keymap.Map, pages []terminal.Page, cur int, saving bool, plugins []pluginScreen) []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range keymap.Actions {
+		switch a.Action {
+		case keymap.Refresh:
+			for _, p := range plugins {
+				if key := barKey(bindings.Keys(p.action), p.label); key != "" {
+					commands = append(commands, terminal.Command{Key: key, Label: p.label})
+				}
+			}
+		case keymap.Save:
+			if !saving {
+				continue
+			}
+		case keymap.Next:
+			if cur+1 >= len(pages) {
+				continue
+			}
+		case keymap.Detail:
+			// One entry for the events drawn, as "[1-5] Detail"; only the
+			// renderer knows how many fit
+			keys := bindings.Keys(keymap.Detail)
+			if len(pages[cur].Events) > 0 && len(keys) > 0 {
+				commands = append(commands, terminal.Command{Numbered: keys, Label: a.Label})
+			}
+			continue
+		case keymap.More:
+			if !hasMore(pages[cur]) {
+				continue
+			}
+		case keymap.Up, keymap.Down, keymap.Like, keymap.Dislike, keymap.Cached:
+			continue
+		case keymap.BirthYear, keymap.About:
+			// Listed with the plugins when they are on
+			continue
+		case keymap.Events, keymap.Births, keymap.Deaths, keymap.Holidays, keymap.Featured, keymap.News:
+			if pages[cur].Kind == string(a.Action) || !slices.ContainsFunc(pages, func(p terminal.Page) bool { return p.Kind == string(a.Action) }) {
+				continue
+			}
+		}
+		if key := barKey(bindings.Keys(a.Action), a.Label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.Label})
+		}
+	}
+	return commands
+}
+
+// hasMore reports whether page has fetched entries it didn't pick, for the
+// more key to list.
+func hasMore(page terminal.Page) bool {
+	return len(page.Pool) > len(page.Events)
+}
+
+// barKey picks the key to show for an action: a letter of its label if one is
+// bound, so it reads as "[N]ext", otherwise the first key bound.
+func barKey(keys []string, label string) string {
+	for _, k := range keys {
+		if len(k) == 1 && strings.Contains(strings.ToLower(label), strings.ToLower(k)) {
+			return k
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
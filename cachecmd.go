@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// runCache implements "history cache export|import": moving a filled cache
+// to a board with no way out to the internet. It returns the process exit
+// code.
+func runCache(args []string) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprintln(os.Stderr, "usage: history cache export [-o file] | history cache import file")
+		return 2
+	}
+	verb, args := args[0], args[1:]
+	cfg, configPath, err := loadConfig(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("cache "+verb, flag.ContinueOnError)
+	outPtr := fs.String("o", "", "file to write the export to (default history-cache-YYYYMMDD.tar.gz, - for stdout)")
+	registerConfigFlags(fs, &cfg, configPath)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	dir := cacheDir(cfg)
+
+	if verb == "export" {
+		name := *outPtr
+		if name == "" {
+			name = "history-cache-" + time.Now().Format("20060102") + ".tar.gz"
+		}
+		var w io.Writer = os.Stdout
+		if name != "-" {
+			f, err := os.Create(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "export: %v\n", err)
+				return 1
+			}
+			defer f.Close()
+			w = f
+		}
+		n, err := wikimedia.ExportCache(dir, w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			if name != "-" {
+				os.Remove(name)
+			}
+			return 1
+		}
+		if name != "-" {
+			fmt.Printf("exported %d cached day(s) from %s to %s\n", n, dir, name)
+		}
+		return 0
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: history cache import file (- for stdin)")
+		return 2
+	}
+	var r io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+	n, err := wikimedia.ImportCache(dir, r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v (%d imported before the error)\n", err, n)
+		return 1
+	}
+	fmt.Printf("imported %d cached day(s) into %s\n", n, dir)
+	return 0
+}
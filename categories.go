@@ -0,0 +1,39 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/robbiew/history/internal/category"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// categoryOf sorts e into a category by its text and the descriptions of
+// the articles it links to.
+func categoryOf(e wikimedia.Event) (category.Category, bool) {
+	descriptions := make([]string, 0, len(e.Pages))
+	for _, p := range e.Pages {
+		if p.Description != "" {
+			descriptions = append(descriptions, p.Description)
+		}
+	}
+	return category.Of(e.Text, descriptions...)
+}
+
+// inCategories returns the events in any of the categories called names.
+// With no names, or when none of the events is in them, every event is
+// returned, so a day quiet on the board's subjects still shows something.
+func inCategories(events []wikimedia.Event, names []string) []wikimedia.Event {
+	if len(names) == 0 {
+		return events
+	}
+	var in []wikimedia.Event
+	for _, e := range events {
+		if c, ok := categoryOf(e); ok && slices.Contains(names, c.Name) {
+			in = append(in, e)
+		}
+	}
+	if len(in) == 0 {
+		return events
+	}
+	return in
+}
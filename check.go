@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/logsink"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// checkResult is one line of the "history check" report.
+type checkResult struct {
+	Name   string
+	Status string // PASS, WARN or FAIL
+	Detail string
+}
+
+// runCheck implements "history check": it validates an install without a caller
+// connected and prints a pass/fail report. It returns the process exit code.
+func runCheck(args []string) int {
+	cfg, configPath, loadErr := loadConfig(args)
+
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	pathPtr := fs.String("path", "", "node directory or dropfile to validate")
+	skipNetPtr := fs.Bool("no-network", false, "skip the network reachability check")
+	registerConfigFlags(fs, &cfg, configPath)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var results []checkResult
+	add := func(name, status, format string, a ...any) {
+		results = append(results, checkResult{Name: name, Status: status, Detail: fmt.Sprintf(format, a...)})
+	}
+
+	// Config file
+	switch {
+	case loadErr != nil:
+		add("config", "FAIL", "%v", loadErr)
+	case fileExists(configPath):
+		add("config", "PASS", "%s loaded", configPath)
+	default:
+		add("config", "PASS", "%s not found, using built-in defaults", configPath)
+	}
+	if err := cfg.Validate(); err != nil {
+		add("config", "FAIL", "%v", err)
+	}
+	if !slices.Contains(knownStrategies, cfg.Strategy) {
+		add("strategy", "WARN", "unknown strategy %q, era-based will be used", cfg.Strategy)
+	} else {
+		add("strategy", "PASS", "%s", cfg.Strategy)
+	}
+
+	// Dropfile
+	if *pathPtr == "" {
+		add("dropfile", "WARN", "no -path given, dropfile not checked")
+	} else if session, err := dropfile.Load(*pathPtr); err != nil {
+		add("dropfile", "FAIL", "%v", err)
+	} else {
+		add("dropfile", "PASS", "%s: node %d on %q, user %q", session.Format, session.Node, session.BbsName, session.UserName)
+	}
+
+	// Cache directory, as it is before anything here touches it
+	status, detail := checkCacheDir(cacheDir(cfg))
+	add("cache", status, "%s", detail)
+
+	// Log and stats files
+	logFile := cfg.LogFile
+	if cfg.LogOutput != "file" {
+		if err := checkLogSink(cfg.LogOutput); err != nil {
+			add("log", "FAIL", "%s: %v", cfg.LogOutput, err)
+		} else {
+			add("log", "PASS", "%s reachable", cfg.LogOutput)
+		}
+		logFile = ""
+	}
+	for _, f := range []struct{ name, path string }{{"log", logFile}, {"stats", cfg.StatsFile}} {
+		if f.path == "" {
+			continue
+		}
+		if err := checkAppendable(f.path); err != nil {
+			add(f.name, "FAIL", "%v", err)
+		} else {
+			add(f.name, "PASS", "%s writable", f.path)
+		}
+	}
+
+	// Screens
+	if err := checkScreens(cfg); err != nil {
+		add("screens", "FAIL", "%v", err)
+	} else {
+		add("screens", "PASS", "every kind of screen fits 80x25 in each charset")
+	}
+
+	// Network
+	if *skipNetPtr {
+		add("network", "WARN", "skipped (-no-network)")
+	} else if cfg.Offline {
+		add("network", "PASS", "skipped, the door is offline")
+	} else {
+		opts := clientOptions(cfg)
+		opts.LeaveCache = true
+		wikiClient := wikimedia.New(opts)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		err := wikiClient.Ping(ctx)
+		cancel()
+		if err != nil {
+			add("network", "FAIL", "%v", err)
+		} else {
+			add("network", "PASS", "Wikimedia API reachable in %v", time.Since(start).Round(time.Millisecond))
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		fmt.Printf(" %-4s  %-9s %s\n", r.Status, r.Name, r.Detail)
+		if r.Status == "FAIL" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		return 1
+	}
+	fmt.Println("\nall checks passed")
+	return 0
+}
+
+// checkScreens draws a sample screen of each kind in each charset, with
+// the configured links setting, and checks each one looks as a screen of
+// the door should.
+func checkScreens(cfg config.Config) error {
+	var errs []error
+	for _, charset := range terminal.Charsets {
+		termCfg := terminal.TerminalConfig{Charset: charset, ShowLinks: cfg.Links, Attribution: cfg.Attribution, Theme: themeFor(cfg.Theme)}
+		for _, s := range sampleScreens(termCfg, time.Now()) {
+			if err := terminal.Verify(termCfg, s.page); err != nil {
+				errs = append(errs, fmt.Errorf("%s screen in %s: %w", s.name, charset, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkCacheDir verifies the cache directory exists and is writable.
+func checkCacheDir(dir string) (status, detail string) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return "FAIL", fmt.Sprintf("%s: %v", dir, err)
+	}
+	if !fi.IsDir() {
+		return "FAIL", fmt.Sprintf("%s is not a directory", dir)
+	}
+	f, err := os.CreateTemp(dir, "check-*")
+	if err != nil {
+		return "FAIL", fmt.Sprintf("%s is not writable: %v", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	entries, _ := os.ReadDir(dir)
+	return "PASS", fmt.Sprintf("%s writable, %d cached file(s)", dir, len(entries))
+}
+
+// checkAppendable makes sure path can be opened for appending, creating it if needed.
+func checkAppendable(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// checkLogSink makes sure the log output, syslog or journald, can be
+// connected to.
+func checkLogSink(output string) error {
+	h, err := logsink.Open(output, logTag)
+	if err != nil {
+		return err
+	}
+	return h.Close()
+}
+
+// fileExists reports whether path names an existing file.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
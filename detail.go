@@ -0,0 +1,128 @@
+package main
+
+import (
+	"cmp"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// detailTitleLen caps how much of an article title names a detail page in
+// its header.
+const detailTitleLen = 30
+
+// detailView shows one event of a page on its own, with all of its text and
+// articles and the summary of its article, and takes the caller's rating of
+// it; any key without a binding goes back to the page.
+type detailView struct {
+	termCfg   terminal.TerminalConfig
+	bindings  *keymap.Map
+	kind      string // of the page it came from
+	page      terminal.Page
+	save      func(terminal.Page)
+	rate      rateFunc
+	summarize summaryFunc
+	summed    bool // the summary has been asked for
+}
+
+// newDetailView opens e, one of from's events. The header names it by its
+// year, or by its article for holidays and news, which have none. A nil
+// summarize shows no summary.
+func newDetailView(termCfg terminal.TerminalConfig, bindings *keymap.Map, from terminal.Page, e terminal.Event, save func(terminal.Page), rate rateFunc, summarize summaryFunc) *detailView {
+	title := textwrap.Cut(cmp.Or(e.Title, "this entry"), detailTitleLen)
+	if e.Year != 0 {
+		title = terminal.FormatYear(e.Year)
+	}
+	page := terminal.Page{Kind: terminal.KindDetail, Date: from.Date, Events: []terminal.Event{e}, CachedAt: from.CachedAt, Title: title}
+	return &detailView{termCfg: termCfg, bindings: bindings, kind: cmp.Or(from.Kind, terminal.KindEvents), page: page, save: save, rate: rate, summarize: summarize}
+}
+
+func (v *detailView) draw() {
+	if !v.summed && v.summarize != nil {
+		if summary := v.summarize(v.page.Events[0].URL); summary != "" {
+			v.page.Lines = []string{summary}
+		}
+		v.summed = true
+	}
+	page := v.page
+	page.Commands = v.commands()
+	terminal.RenderEvents(v.termCfg, page)
+}
+
+func (v *detailView) handle(_ input.Event, action keymap.Action) step {
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Save:
+		if v.save == nil {
+			return back
+		}
+		v.save(v.page)
+		return stay
+	case keymap.Like, keymap.Dislike:
+		if v.rate == nil {
+			return back
+		}
+		vote := 1
+		if action == keymap.Dislike {
+			vote = -1
+		}
+		v.rate(v.kind, v.page.Events[0], vote)
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: v.help()})
+	}
+	return back
+}
+
+// detailActions are the actions a detail page answers to, with their labels
+// there; any other key goes back.
+var detailActions = []struct {
+	action keymap.Action
+	label  string
+	help   string
+}{
+	{keymap.Next, "Back", "Back to the list"},
+	{keymap.Like, "Like", "Rate this up"},
+	{keymap.Dislike, "Dislike", "Rate this down"},
+	{keymap.Save, "Save", "Save this screen to your downloads"},
+	{keymap.Help, "Help", "This help"},
+	{keymap.Quit, "Quit", "Back to the BBS"},
+}
+
+// off reports whether a is switched off here, for the bar and help.
+func (v *detailView) off(a keymap.Action) bool {
+	switch a {
+	case keymap.Save:
+		return v.save == nil
+	case keymap.Like, keymap.Dislike:
+		return v.rate == nil
+	}
+	return false
+}
+
+func (v *detailView) commands() []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range detailActions {
+		if v.off(a.action) {
+			continue
+		}
+		if key := barKey(v.bindings.Keys(a.action), a.label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.label})
+		}
+	}
+	return commands
+}
+
+func (v *detailView) help() []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range detailActions {
+		if v.off(a.action) {
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: v.bindings.Keys(a.action), Text: a.help})
+	}
+	return entries
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/internal/sauce"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// outputFormats are the values -format accepts: the interactive door, or a
+// digest of the day printed to stdout.
+var outputFormats = []string{"door", "markdown", "banner", "plain", "html"}
+
+// digestOptions control runDigest.
+type digestOptions struct {
+	Format      string // one of outputFormats other than "door"
+	BypassCache bool
+	// BannerRows is the banner's height, one of terminal.BannerRows.
+	BannerRows int
+}
+
+// runDigest prints the day's events and configured sections to stdout in
+// opts.Format, without a caller or dropfile, picking events the same way
+// the door does. It returns the process exit code.
+func runDigest(cfg config.Config, date time.Time, opts digestOptions) int {
+	wikiClient := wikimedia.New(clientOptions(cfg))
+	defer wikiClient.Wait(backgroundGrace)
+
+	month, day := fmt.Sprintf("%02d", int(date.Month())), fmt.Sprintf("%02d", date.Day())
+	res, sections, err := fetchDay(context.Background(), wikiClient, month, day, cfg.Sections, opts.BypassCache, time.Duration(cfg.FetchDeadline))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to fetch events: %v\n", err)
+		return 1
+	}
+
+	// A -date override has no year; the digest is dated this year
+	date = datedDay(date)
+	// There is no caller's BBS here, so daily_by_bbs has nothing to add
+	rng := sessionRand(0)
+	d := digest{Date: date, Site: wikipediaSite(cfg.Language), Events: chronological(selectEvents(inCategories(res.Events, cfg.Categories), cfg.Strategy, cfg.Shuffle, date.Year(), selectionRand(cfg.Strategy, date, wikimedia.SectionEvents, "", rng)))}
+	d.Anniversaries = marksAnniversaries(cfg.Strategy, cfg.Anniversaries)
+	for _, name := range cfg.Sections {
+		if s, ok := sections[name]; ok && len(s.Events) > 0 {
+			entries := s.Events
+			switch name {
+			case wikimedia.SectionHolidays, wikimedia.SectionNews, wikimedia.SectionFeatured:
+				// Kept whole, in the feed's order
+			default:
+				entries = chronological(selectEvents(inCategories(entries, cfg.Categories), cfg.Strategy, cfg.Shuffle, date.Year(), selectionRand(cfg.Strategy, date, name, "", rng)))
+			}
+			d.Sections = append(d.Sections, digestSection{Name: name, Events: entries})
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	switch opts.Format {
+	case "markdown":
+		writeMarkdown(w, d, localeFor(cfg.Locale, terminal.UTF8))
+	case "banner":
+		// A display file has no caller to detect, so auto means CP437
+		charset := terminal.CP437
+		if cfg.Charset != "auto" {
+			charset = terminal.Charset(cfg.Charset)
+		}
+		err = writeBanner(w, d, opts.BannerRows, charset, localeFor(cfg.Locale, charset))
+	case "plain":
+		err = writeDocument(w, d, terminal.Plain{Locale: localeFor(cfg.Locale, terminal.ASCII)})
+	case "html":
+		err = writeDocument(w, d, terminal.HTML{Locale: localeFor(cfg.Locale, terminal.ASCII), Site: d.Site})
+	}
+	if err = cmp.Or(err, w.Flush()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write digest: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeBanner writes the day's first three events as a login banner, a
+// static ANSI screen with a SAUCE record, rows lines tall, its art drawn in
+// charset, with its dates in loc.
+func writeBanner(w io.Writer, d digest, rows int, charset terminal.Charset, loc *locale.Locale) error {
+	page := terminal.Page{Kind: terminal.KindEvents, Date: d.Date, Events: d.terminalEvents(d.Events, charset)}
+	return sauce.Append(w, terminal.Banner(page, rows, d.Date, charset, loc), sauce.Record{
+		Title:  "This Day in History " + d.Date.Format("01-02"),
+		Author: "history",
+		Date:   d.Date,
+		Width:  terminal.BannerWidth,
+		Height: rows,
+		Font:   "IBM VGA",
+	})
+}
+
+// writeDocument writes d through r as one document, a page per section.
+func writeDocument(w io.Writer, d digest, r terminal.Renderer) error {
+	pages := []terminal.Page{{Kind: terminal.KindEvents, Date: d.Date, Events: d.terminalEvents(d.Events, terminal.ASCII)}}
+	for _, s := range d.Sections {
+		pages = append(pages, terminal.Page{Kind: s.Name, Date: d.Date, Events: d.terminalEvents(s.Events, terminal.ASCII)})
+	}
+	return terminal.Document(w, r, pages, d.Date, 78)
+}
+
+// digest is one day's worth of output: the events, then any sections in
+// the configured order.
+type digest struct {
+	Date time.Time
+	// Site is the home page of the Wikipedia edition the entries are from,
+	// credited at the end.
+	Site     string
+	Events   []wikimedia.Event
+	Sections []digestSection
+	// Anniversaries calls out the entries that are a round anniversary in
+	// Date's year.
+	Anniversaries bool
+}
+
+// terminalEvents converts events for the renderers, as toTerminalEvents,
+// with round anniversaries tagged when d calls them out.
+func (d digest) terminalEvents(events []wikimedia.Event, charset terminal.Charset) []terminal.Event {
+	tevents := toTerminalEvents(events, charset)
+	if d.Anniversaries {
+		markAnniversaries(tevents, d.Date.Year())
+	}
+	return tevents
+}
+
+type digestSection struct {
+	Name   string
+	Events []wikimedia.Event
+}
+
+// wikipediaSite is the home page of the Wikipedia language edition lang.
+func wikipediaSite(lang string) string {
+	return "https://" + cmp.Or(lang, wikimedia.DefaultLanguage) + ".wikipedia.org/"
+}
+
+// chronological sorts events oldest first, which reads better in a digest
+// than the door's mix.
+func chronological(events []wikimedia.Event) []wikimedia.Event {
+	return slices.SortedStableFunc(slices.Values(events), func(a, b wikimedia.Event) int {
+		return cmp.Compare(a.Year, b.Year)
+	})
+}
+
+// digestHeadings title each section in a digest.
+var digestHeadings = map[string]string{
+	wikimedia.SectionEvents:   "Events",
+	wikimedia.SectionBirths:   "Births",
+	wikimedia.SectionDeaths:   "Deaths",
+	wikimedia.SectionHolidays: "Holidays and observances",
+	wikimedia.SectionFeatured: "Featured article",
+	wikimedia.SectionNews:     "In the news",
+}
+
+// writeMarkdown writes d as a Markdown document: a title dated in loc, then
+// a list per section with each entry's year in bold and its article linked.
+// Text from the feed is escaped so it can't be read as Markdown.
+func writeMarkdown(w io.Writer, d digest, loc *locale.Locale) {
+	fmt.Fprintf(w, "# This Day in History: %s\n", escapeMarkdown(loc.Day(d.Date)))
+	fmt.Fprintf(w, "\n*%s*\n", escapeMarkdown(loc.FullDate(d.Date)))
+
+	year := 0
+	if d.Anniversaries {
+		year = d.Date.Year()
+	}
+	writeMarkdownList(w, wikimedia.SectionEvents, d.Events, year)
+	for _, s := range d.Sections {
+		if s.Name == wikimedia.SectionFeatured {
+			e := s.Events[0]
+			fmt.Fprintf(w, "\n## %s\n\n", digestHeadings[s.Name])
+			if len(e.Pages) > 0 {
+				fmt.Fprintf(w, "**%s**\n\n", markdownLink(e.Pages[0]))
+			}
+			fmt.Fprintf(w, "%s\n", escapeMarkdown(e.Text))
+			continue
+		}
+		writeMarkdownList(w, s.Name, s.Events, year)
+	}
+	fmt.Fprintf(w, "\n---\n\nFrom [Wikipedia](%s), available under [CC BY-SA 4.0](https://creativecommons.org/licenses/by-sa/4.0/).\n", d.Site)
+}
+
+// writeMarkdownList writes one section as a bulleted list. Holidays and news
+// have no year. Entries that are a round anniversary in thisYear are called
+// out; a thisYear of 0 calls out none.
+func writeMarkdownList(w io.Writer, section string, events []wikimedia.Event, thisYear int) {
+	fmt.Fprintf(w, "\n## %s\n\n", digestHeadings[section])
+	dated := section != wikimedia.SectionHolidays && section != wikimedia.SectionNews
+	for _, e := range events {
+		line := "- "
+		if dated {
+			line += "**" + terminal.FormatYear(e.Year) + "**: "
+			if ago := roundAnniversary(e.Year, thisYear); thisYear != 0 && ago > 0 {
+				line += "*" + terminal.AnniversaryTag(ago) + "* "
+			}
+		}
+		line += escapeMarkdown(e.Text)
+		if len(e.Pages) > 0 && e.Pages[0].URL != "" {
+			line += " (" + markdownLink(e.Pages[0]) + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// markdownLink links an article by its title.
+func markdownLink(p wikimedia.Page) string {
+	if p.URL == "" {
+		return escapeMarkdown(p.Title)
+	}
+	return "[" + escapeMarkdown(p.Title) + "](" + strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(p.URL) + ")"
+}
+
+// markdownEscaper backslash-escapes the characters that can start Markdown
+// formatting inside a line.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// escapeMarkdown makes feed text safe to put in a Markdown line.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(strings.Join(strings.Fields(s), " "))
+}
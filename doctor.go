@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/era"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// apiHost is the Wikimedia API's host, looked up by the doctor.
+const apiHost = "api.wikimedia.org"
+
+// doctorRenders is how many screens the doctor draws to time the renderer.
+const doctorRenders = 200
+
+// runDoctor implements "history doctor": it reports the environment the
+// door runs in, what it makes of the dropfile given with -path, whether the
+// API can be reached, the cache, and how fast screens render, as a block to
+// paste into a bug report. Unlike check it passes no judgement and always
+// exits 0 once its flags parse.
+func runDoctor(args []string) int {
+	cfg, configPath, loadErr := loadConfig(args)
+
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	pathPtr := fs.String("path", "", "node directory or dropfile to report on")
+	registerConfigFlags(fs, &cfg, configPath)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	doctor(os.Stdout, cfg, configPath, loadErr, *pathPtr)
+	return 0
+}
+
+func doctor(w io.Writer, cfg config.Config, configPath string, loadErr error, path string) {
+	line := func(name, format string, a ...any) {
+		fmt.Fprintf(w, "%-9s %s\n", name+":", fmt.Sprintf(format, a...))
+	}
+	fmt.Fprintln(w, "```")
+	line("version", "%s", versionString())
+	line("go", "%s %s/%s, %d CPUs", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+
+	env := func(name string) string { return name + "=" + os.Getenv(name) }
+	line("terminal", "%s %s %s %s %s", env("TERM"), env("TERM_PROGRAM"), env("COLUMNS"), env("LINES"), env("LANG"))
+	termType, _, xtendPalette, cols, rows := DetectTerminalCapabilities()
+	line("detected", "%s, %dx%d, 256 colors %v, stdin a terminal %v", termType, cols, rows, xtendPalette, stdinIsTerminal())
+
+	switch {
+	case loadErr != nil:
+		line("config", "%s: %v", configPath, loadErr)
+	case fileExists(configPath):
+		line("config", "%s", configPath)
+	default:
+		line("config", "%s not found, built-in defaults", configPath)
+	}
+	if err := cfg.Validate(); err != nil {
+		line("config", "%v", err)
+	}
+
+	charset := terminal.Charset(cfg.Charset)
+	if path == "" {
+		line("dropfile", "no -path given")
+	} else if session, err := dropfile.Load(path); err != nil {
+		line("dropfile", "%v", err)
+	} else {
+		line("dropfile", "%s %s: node %d on %q, user %q #%d, level %d, %d min left, emulation %d, comm type %d",
+			session.Format, session.Path, session.Node, session.BbsName, session.UserName, session.UserNumber,
+			session.SecLevel, session.TimeLeft, session.Emulation, session.CommType)
+		charset = charsetFor(cfg.Charset, session)
+	}
+	if cfg.Charset == "auto" && path == "" {
+		charset = terminal.CP437
+	}
+
+	wikiClient := wikimedia.New(clientOptions(cfg))
+	if cfg.Offline {
+		line("dns", "skipped, offline")
+		line("https", "skipped, offline")
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		addrs, err := net.DefaultResolver.LookupHost(ctx, apiHost)
+		cancel()
+		if err != nil {
+			line("dns", "%v", err)
+		} else {
+			line("dns", "%s is %s in %v", apiHost, strings.Join(addrs, ", "), time.Since(start).Round(time.Millisecond))
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		start = time.Now()
+		err = wikiClient.Ping(ctx)
+		cancel()
+		if err != nil {
+			line("https", "%v", err)
+		} else {
+			line("https", "reachable in %v", time.Since(start).Round(time.Millisecond))
+		}
+	}
+	status, detail := checkCacheDir(wikiClient.CacheDir())
+	line("cache", "%s %s", status, detail)
+
+	termCfg := terminal.TerminalConfig{Charset: charset, ShowLinks: cfg.Links, Attribution: cfg.Attribution, ScreenDiff: cfg.ScreenDiff, Theme: themeFor(cfg.Theme)}
+	page := samplePage(time.Now())
+	start := time.Now()
+	size := 0
+	for range doctorRenders {
+		size = len(terminal.Draw(termCfg, page))
+	}
+	each := time.Since(start) / doctorRenders
+	line("render", "%v a screen of %d bytes in %s, over %d screens", each.Round(time.Microsecond), size, charset, doctorRenders)
+	fmt.Fprintln(w, "```")
+}
+
+// sampleEvents are made-up events from every era, some long enough to wrap,
+// for timing the renderer without the network.
+var sampleEvents = []struct {
+	year int
+	text string
+}{
+	{-480, "The Battle of Thermopylae is fought between the Greek city-states and the Persian Empire, whose army is held at the pass for three days."},
+	{1066, "The Battle of Hastings takes place in England."},
+	{1605, "The first part of a famous novel is published in Madrid, and is soon translated into several other European languages by enthusiastic readers."},
+	{1903, "The first powered, controlled flight lasts twelve seconds."},
+	{1969, "Astronauts land on the Moon and walk on its surface, watched on television by hundreds of millions of people around the world."},
+	{1985, "A bulletin board system goes online with a single phone line."},
+}
+
+// samplePage is an events page of sampleEvents for day, with the first five
+// on it and the rest of them in its pool.
+func samplePage(day time.Time) terminal.Page {
+	var events []terminal.Event
+	for _, s := range sampleEvents {
+		title := fmt.Sprintf("Sample_%d", s.year)
+		events = append(events, terminal.Event{
+			Year: s.year, Text: s.text, Era: era.Of(s.year),
+			Title: title, URL: "https://en.wikipedia.org/wiki/" + title,
+		})
+	}
+	return terminal.Page{Kind: terminal.KindEvents, Date: day, Events: events[:5], Pool: events}
+}
+
+// sampleScreen is a screen drawn from sample events.
+type sampleScreen struct {
+	name string
+	page terminal.Page
+}
+
+// sampleScreens are a screen of each kind for day, drawn from sampleEvents:
+// the events screen, a detail and a continuation page first, as bench times
+// them, then the other kinds.
+func sampleScreens(termCfg terminal.TerminalConfig, day time.Time) []sampleScreen {
+	events := samplePage(day)
+	detail := terminal.Page{Kind: terminal.KindDetail, Date: day, Events: events.Events[:1], Title: terminal.FormatYear(events.Events[0].Year)}
+	lines := terminal.ListLines(termCfg, terminal.KindEvents, events.Pool)
+	more := terminal.Page{Kind: terminal.KindMore, Title: terminal.KindEvents, Date: day, Events: events.Pool, Lines: lines[:min(len(lines), terminal.ListRows(termCfg))]}
+	births := events
+	births.Kind = terminal.KindBirths
+	holidays := events
+	holidays.Kind = terminal.KindHolidays
+	featured := terminal.Page{Kind: terminal.KindFeatured, Date: day, Events: events.Events[4:5]}
+	plugin := terminal.Page{Kind: terminal.KindPlugin, Date: day, Title: "Sample", Lines: strings.Split(strings.Repeat("A line drawn by a plugin\n", 14), "\n")}
+	return []sampleScreen{
+		{"events", events}, {"detail", detail}, {"more", more},
+		{"births", births}, {"holidays", holidays}, {"featured", featured}, {"plugin", plugin},
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// easterEgg is the hidden screen a run of keys opens from the day's
+// screens. typed counts the keys of the run the caller has typed so far.
+type easterEgg struct {
+	keys  []input.Event
+	typed int
+	open  func() view
+}
+
+// newEasterEgg returns the easter egg egg sets up, or nil when there is
+// none or its art can't be read.
+func newEasterEgg(egg config.EasterEgg, termCfg terminal.TerminalConfig) *easterEgg {
+	if len(egg.Keys) == 0 {
+		return nil
+	}
+	art, err := terminal.LoadArt(egg.Art)
+	if err != nil {
+		// Checked by cfg.Validate, but the file may have gone since
+		slog.Warn("could not read the easter egg's art, leaving it out", "file", egg.Art, "error", err)
+		return nil
+	}
+	e := &easterEgg{open: func() view { return artView{termCfg: termCfg, art: art} }}
+	for _, name := range egg.Keys {
+		ev, err := input.ParseKey(name)
+		if err != nil {
+			// Already checked by cfg.Validate
+			return nil
+		}
+		e.keys = append(e.keys, ev)
+	}
+	return e
+}
+
+// press follows ev along the run of keys, starting over when it doesn't
+// carry on from the keys typed so far. It reports whether ev was part of
+// the run, and whether it finished it.
+func (e *easterEgg) press(ev input.Event) (typing, done bool) {
+	if ev != e.keys[e.typed] {
+		e.typed = 0
+		if ev != e.keys[0] {
+			return false, false
+		}
+	}
+	e.typed++
+	if e.typed < len(e.keys) {
+		return true, false
+	}
+	e.typed = 0
+	return true, true
+}
+
+// artView shows a screen of art; quit leaves the door and any other key
+// goes back.
+type artView struct {
+	termCfg terminal.TerminalConfig
+	art     terminal.Art
+}
+
+func (v artView) draw() {
+	terminal.RenderArt(v.termCfg, v.art, "GO BACK")
+}
+
+func (v artView) handle(_ input.Event, action keymap.Action) step {
+	if action == keymap.Quit {
+		return quit
+	}
+	return back
+}
@@ -4,10 +4,8 @@ go 1.24.0
 
 toolchain go1.24.7
 
-require github.com/mattn/go-tty v0.0.4
-
 require (
-	github.com/mattn/go-isatty v0.0.10 // indirect
-	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
-	golang.org/x/text v0.29.0 // indirect
+	golang.org/x/sync v0.17.0
+	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a
+	golang.org/x/text v0.29.0
 )
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+)
+
+// sessionHooks runs the sysop's hook commands as a session starts, fails and
+// ends, with the session's details in their environment. Hooks run in the
+// background so the caller never waits on them, except at exit, where the
+// door lets them finish before handing back to the BBS.
+type sessionHooks struct {
+	cfg   config.Hooks
+	env   []string
+	start time.Time
+	wg    sync.WaitGroup
+}
+
+// newHooks prepares the hooks in cfg for session.
+func newHooks(cfg config.Hooks, session *dropfile.DoorSession) *sessionHooks {
+	return &sessionHooks{
+		cfg:   cfg,
+		start: time.Now(),
+		env: []string{
+			"HISTORY_USER=" + session.UserName,
+			"HISTORY_REAL_NAME=" + session.RealName,
+			"HISTORY_USER_NUMBER=" + strconv.Itoa(session.UserNumber),
+			"HISTORY_NODE=" + strconv.Itoa(session.Node),
+			"HISTORY_BBS=" + session.BbsName,
+			"HISTORY_SECURITY_LEVEL=" + strconv.Itoa(session.SecLevel),
+			"HISTORY_TIME_LEFT=" + strconv.Itoa(session.TimeLeft),
+			"HISTORY_DROPFILE=" + session.Path,
+		},
+	}
+}
+
+// started runs on_start.
+func (h *sessionHooks) started() {
+	h.run("start", h.cfg.OnStart)
+}
+
+// failed runs on_error with err's message.
+func (h *sessionHooks) failed(err error) {
+	h.run("error", h.cfg.OnError, "HISTORY_ERROR="+err.Error())
+}
+
+// exited runs on_exit with why the session ended ("quit", "hangup", "idle",
+// "session_limit", "time_left", "signal" or "error") and waits for every
+// hook still running.
+func (h *sessionHooks) exited(reason string) {
+	secs := int(time.Since(h.start).Seconds())
+	h.run("exit", h.cfg.OnExit, "HISTORY_EXIT_REASON="+reason, "HISTORY_SESSION_SECONDS="+strconv.Itoa(secs))
+	h.wg.Wait()
+}
+
+// run starts command in the background with the session environment and
+// extra, killing it after the hook timeout. An empty command does nothing.
+func (h *sessionHooks) run(event string, command []string, extra ...string) {
+	if len(command) == 0 {
+		return
+	}
+	env := append(os.Environ(), "HISTORY_HOOK="+event)
+	env = append(append(env, h.env...), extra...)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.cfg.Timeout))
+		defer cancel()
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Env = env
+		cmd.WaitDelay = time.Second
+		out, err := cmd.CombinedOutput()
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			slog.Info("hook output", "hook", event, "output", msg)
+		}
+		if err != nil {
+			slog.Warn("hook failed", "hook", event, "command", command[0], "error", err)
+		}
+	}()
+}
@@ -0,0 +1,49 @@
+// Package almanac is the door's built-in dataset of events, one or two
+// well-known ones for every day of the year, served for a day the cache
+// doesn't have when Wikipedia isn't asked: offline, or while the circuit
+// breaker is open.
+//
+// The events are in almanac.txt, in the pinned events format.
+package almanac
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/robbiew/history/internal/pinned"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+//go:embed almanac.txt
+var data string
+
+// events are the dataset's events by MM-DD day.
+var events = parse(data)
+
+// parse reads the dataset.
+func parse(text string) map[string][]wikimedia.Event {
+	days := map[string][]wikimedia.Event{}
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, date, err := pinned.Parse(line)
+		if err != nil {
+			// The dataset is the door's own and always parses
+			panic(fmt.Sprintf("almanac.txt:%d: %v", n+1, err))
+		}
+		days[date] = append(days[date], *e)
+	}
+	return days
+}
+
+// Fallback is an Options.Fallback for a wikimedia client: the dataset's
+// events for the day, and nothing for the other sections.
+func Fallback(section, month, day string) []wikimedia.Event {
+	if section != wikimedia.SectionEvents {
+		return nil
+	}
+	return append([]wikimedia.Event(nil), events[month+"-"+day]...)
+}
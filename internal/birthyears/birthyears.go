@@ -0,0 +1,100 @@
+// Package birthyears keeps the year each caller was born, as they gave it
+// the first time they asked what happened on the day in that year.
+package birthyears
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/robbiew/history/internal/filelock"
+)
+
+// File holds callers' birth years, keyed by user name, in a JSON file all
+// nodes share. Names are matched without regard to case or surrounding
+// space, as BBSes differ in how they write them. A nil *File is valid,
+// knows no one and refuses to store years.
+type File struct {
+	path string
+
+	mu    sync.Mutex
+	years map[string]int
+}
+
+// Open reads the file at path. A missing file starts empty; an empty path
+// returns nil.
+func Open(path string) (*File, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f := &File{path: path, years: map[string]int{}}
+	return f, f.read()
+}
+
+// read replaces the years with the file's. The caller holds mu.
+func (f *File) read() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	years := map[string]int{}
+	if err := json.Unmarshal(data, &years); err != nil {
+		return fmt.Errorf("parsing %s: %w", f.path, err)
+	}
+	f.years = years
+	return nil
+}
+
+// key is how user is filed.
+func key(user string) string {
+	return strings.ToLower(strings.TrimSpace(user))
+}
+
+// Get returns the year user was born, if they have given it.
+func (f *File) Get(user string) (int, bool) {
+	if f == nil {
+		return 0, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	year, ok := f.years[key(user)]
+	return year, ok
+}
+
+// Set records that user was born in year, replacing any year they gave
+// before. Doors on other nodes save the same file, so it is saved under a
+// lock with their callers' years since it was read, and replaced whole
+// through a temporary file.
+func (f *File) Set(user string, year int) error {
+	if f == nil {
+		return errors.New("birth years are off")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(f.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := f.read(); err != nil {
+		return err
+	}
+	f.years[key(user)] = year
+
+	data, err := json.Marshal(f.years)
+	if err != nil {
+		return err
+	}
+	return filelock.WriteAtomic(f.path, data)
+}
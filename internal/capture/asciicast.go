@@ -0,0 +1,84 @@
+package capture
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Cast writes an asciicast v2 recording: a JSON header line, then one
+// [seconds, "o", text] line per write, so asciinema can replay the session
+// at the pace the caller saw it. The door's output is CP437, or Codepage,
+// and is turned into UTF-8 on the way, as asciicast requires, unless UTF8
+// is set.
+type Cast struct {
+	// UTF8 marks output that is already UTF-8, which is recorded as is.
+	UTF8 bool
+	// Codepage is the code page the output is in when it isn't CP437.
+	Codepage *charmap.Charmap
+
+	w       io.Writer
+	start   time.Time
+	partial []byte // a UTF-8 character split across writes
+}
+
+// NewCast writes the header for a width x height session starting at start
+// and returns a Cast ready for output.
+func NewCast(w io.Writer, width, height int, start time.Time, title string) (*Cast, error) {
+	header, err := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     width,
+		"height":    height,
+		"timestamp": start.Unix(),
+		"title":     title,
+		"env":       map[string]string{"TERM": "ansi"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return &Cast{w: w, start: start}, nil
+}
+
+// Write implements io.Writer, recording p as one output event.
+func (c *Cast) Write(p []byte) (int, error) {
+	text, err := c.text(p)
+	if err != nil {
+		return 0, err
+	}
+	event, err := json.Marshal([]any{time.Since(c.start).Seconds(), "o", string(text)})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fmt.Fprintf(c.w, "%s\n", event); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// text returns p as UTF-8. Already UTF-8 output that ends partway through a
+// character keeps that character back for the next write.
+func (c *Cast) text(p []byte) ([]byte, error) {
+	if !c.UTF8 {
+		return cmp.Or(c.Codepage, charmap.CodePage437).NewDecoder().Bytes(p)
+	}
+	text := append(c.partial, p...)
+	c.partial = nil
+	for i := len(text) - 1; i >= max(len(text)-utf8.UTFMax, 0); i-- {
+		if utf8.RuneStart(text[i]) {
+			if !utf8.FullRune(text[i:]) {
+				c.partial = append([]byte(nil), text[i:]...)
+				text = text[:i]
+			}
+			break
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,50 @@
+// Package capture saves what the door sends a caller to a file, for looking
+// into layout problems reported from terminals the sysop doesn't have.
+package capture
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Create makes a new capture file in dir, creating dir if needed. The name
+// carries the node and the session's start time, with ext (such as ".ans")
+// on the end, so captures from different callers never collide.
+func Create(dir string, node int, ext string, start time.Time) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("history-node%d-%s%s", node, start.Format("20060102-150405"), ext)
+	return os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+}
+
+// Writer passes writes through to a capture file, but never fails: the
+// first error is handed to onErr and everything after it is discarded, so a
+// full disk costs the capture and not the caller's session.
+type Writer struct {
+	w      io.Writer
+	onErr  func(error)
+	failed bool
+}
+
+// NewWriter wraps w. onErr may be nil.
+func NewWriter(w io.Writer, onErr func(error)) *Writer {
+	return &Writer{w: w, onErr: onErr}
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.failed {
+		return len(p), nil
+	}
+	if _, err := w.w.Write(p); err != nil {
+		w.failed = true
+		if w.onErr != nil {
+			w.onErr(err)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,114 @@
+// Package category sorts events into a few broad subjects, from the words
+// in their text and in the short descriptions of the articles they link
+// to, so callers can see at a glance what an event is about and sysops can
+// show only the subjects their board cares for.
+//
+// The sorting is by keyword, and coarse: an event is put in the category
+// whose words it uses most, and in none when it uses none of them.
+package category
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Category is one subject.
+type Category struct {
+	Name  string
+	Badge string // short tag shown beside an event
+	words []string
+}
+
+// All lists the categories, in the order ties between them are settled.
+var All = []Category{
+	{Name: "war", Badge: "WAR", words: []string{
+		"war", "wars", "battle", "army", "armies", "invasion", "invade", "invades", "siege", "troops",
+		"military", "navy", "naval", "bombing", "bombed", "surrender", "surrenders", "rebellion",
+		"revolt", "massacre", "soldiers", "armistice", "fleet", "raid", "offensive", "occupation",
+		"regiment", "warship", "insurgents", "ceasefire",
+	}},
+	{Name: "science", Badge: "SCI", words: []string{
+		"science", "scientist", "scientific", "discovers", "discovered", "discovery", "physicist",
+		"chemist", "mathematician", "astronomer", "biologist", "space", "spacecraft", "satellite",
+		"orbit", "moon", "planet", "comet", "asteroid", "telescope", "vaccine", "medicine", "medical",
+		"invention", "invents", "invented", "patent", "computer", "nasa", "experiment", "element",
+		"theory", "rocket", "astronaut", "cosmonaut", "probe", "laboratory", "physics", "chemistry",
+		"genome", "dna", "technology", "engineer", "internet", "software",
+	}},
+	{Name: "politics", Badge: "POL", words: []string{
+		"president", "election", "elected", "parliament", "congress", "government", "treaty",
+		"constitution", "independence", "king", "queen", "emperor", "empress", "crowned",
+		"coronation", "monarch", "senate", "legislation", "politician", "minister", "republic",
+		"referendum", "coup", "dynasty", "pope", "chancellor", "assassinated", "abdicates",
+		"declaration", "sovereign", "statesman", "diplomat", "united nations",
+	}},
+	{Name: "sports", Badge: "SPT", words: []string{
+		"football", "soccer", "baseball", "basketball", "olympic", "olympics", "world cup",
+		"championship", "champion", "tournament", "cricket", "tennis", "golf", "boxing", "boxer",
+		"athlete", "league", "stadium", "medal", "grand prix", "marathon", "hockey", "rugby",
+		"footballer", "cyclist", "racing", "sprinter", "wrestler",
+	}},
+	{Name: "culture", Badge: "ART", words: []string{
+		"film", "novel", "album", "song", "singer", "musician", "composer", "painter", "painting",
+		"artist", "opera", "theatre", "theater", "museum", "poet", "poem", "writer", "author",
+		"actor", "actress", "premiere", "premieres", "published", "band", "television",
+		"broadcast", "book", "festival", "symphony", "ballet", "sculptor", "novelist", "comedian",
+		"rapper", "director", "screenwriter", "playwright", "cartoon",
+	}},
+}
+
+// Names returns the categories' names.
+func Names() []string {
+	names := make([]string, len(All))
+	for i, c := range All {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Lookup returns the category called name.
+func Lookup(name string) (Category, bool) {
+	i := slices.IndexFunc(All, func(c Category) bool { return c.Name == name })
+	if i < 0 {
+		return Category{}, false
+	}
+	return All[i], true
+}
+
+// Of returns the category of an event with text, linking to articles with
+// descriptions, and false when none fits. A description says what its
+// article is about more surely than the event's text, so its words count
+// twice.
+func Of(text string, descriptions ...string) (Category, bool) {
+	scores := make([]int, len(All))
+	for i, c := range All {
+		scores[i] = matches(text, c.words)
+		for _, d := range descriptions {
+			scores[i] += 2 * matches(d, c.words)
+		}
+	}
+	best := 0
+	for i, s := range scores {
+		if s > scores[best] {
+			best = i
+		}
+	}
+	if scores[best] == 0 {
+		return Category{}, false
+	}
+	return All[best], true
+}
+
+// matches counts the words, or phrases, of words that s uses.
+func matches(s string, words []string) int {
+	// Padded with spaces so a phrase matches whole words only
+	plain := " " + strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ") + " "
+	n := 0
+	for _, w := range words {
+		n += strings.Count(plain, " "+w+" ")
+	}
+	return n
+}
@@ -0,0 +1,834 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/category"
+	"github.com/robbiew/history/internal/era"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/internal/notice"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// DefaultPath is the config file looked for in the working directory when -config is not given.
+const DefaultPath = "history.json"
+
+// Config holds the door settings a sysop can keep in a file instead of repeating
+// them on every command line. Command-line flags override values loaded here.
+type Config struct {
+	Strategy  string   `json:"strategy"`
+	Shuffle   bool     `json:"shuffle"`
+	CacheTTL  Duration `json:"cache_ttl"`
+	LeapBlend bool     `json:"leap_blend"`
+	Links     bool     `json:"links"`
+	// Attribution names the Wikipedia articles the events come from when
+	// Links is off, for boards that want the credit without the addresses.
+	Attribution bool `json:"attribution"`
+	// Categories, when set, limits the events and people picked to those in
+	// these categories, as named by category.Names; a day with none in them
+	// is shown whole. CategoryTags shows each event's category beside it.
+	Categories   []string `json:"categories"`
+	CategoryTags bool     `json:"category_tags"`
+	// Anniversaries calls out events that are a round anniversary this
+	// year, as "150 YEARS AGO TODAY", whatever the strategy; the
+	// anniversary strategy always does.
+	Anniversaries bool `json:"anniversaries"`
+	// SceneEvents blends the door's own milestones of the BBS, demo, art
+	// and warez scenes into the day's events, one on screen on a day that
+	// has any.
+	SceneEvents bool `json:"scene_events"`
+	// DailyByBBS mixes the BBS name into the daily strategy's picks, so
+	// each board has its own set while every caller on it shares one.
+	DailyByBBS bool `json:"daily_by_bbs"`
+	// SeenDir keeps a file per caller of the events shown to them today, so
+	// a second visit the same day favours ones they haven't read. Empty
+	// turns it off.
+	SeenDir string `json:"seen_dir"`
+	// SharedSeen keeps a list of the events shown to every caller today in
+	// the cache directory, so callers on different nodes don't get the
+	// same ones back to back.
+	SharedSeen bool `json:"shared_seen"`
+	// RatingsFile keeps callers' likes and dislikes of events, for every
+	// node; empty turns rating off. Favorites is how many of the best
+	// rated events, those above zero, take the first slots of a screen.
+	RatingsFile string `json:"ratings_file"`
+	Favorites   int    `json:"favorites"`
+	// BirthYearsFile keeps the year each caller was born, asked the first
+	// time they open the screen of the day in that year; empty turns the
+	// screen off.
+	BirthYearsFile string `json:"birth_years_file"`
+	// Sections are extra screens shown after the events, in order; see KnownSections.
+	Sections []string `json:"sections"`
+	// Language is the Wikipedia edition the events and sections come
+	// from, by its language code, such as "de" or "ru".
+	Language string `json:"language"`
+
+	// CacheTTLs override CacheTTL per feed, keyed by "events" or a section
+	// name, e.g. holidays rarely change and can be kept for a week.
+	// "summary" sets how long article summaries for detail pages are kept,
+	// a month unless set.
+	CacheTTLs map[string]Duration `json:"cache_ttls"`
+	// CacheDir holds cached API responses. Empty means $HISTORY_CACHE_DIR,
+	// or failing that the per-user cache directory.
+	CacheDir string `json:"cache_dir"`
+	// CacheMaxSize caps the cache's size on disk; the least recently used
+	// days are deleted to make room. 0 means no cap.
+	CacheMaxSize ByteSize `json:"cache_max_size"`
+
+	// MinLevels gates optional features behind a minimum dropfile security
+	// level, keyed by feature name (see KnownFeatures). Features not listed
+	// are open to every caller.
+	MinLevels map[string]int `json:"min_levels"`
+
+	// TaglinesFile names a file of quotes, one of which is shown above the
+	// footer each session; empty shows none.
+	TaglinesFile string `json:"taglines_file"`
+
+	// PinnedFile names a file of events the sysop pins, by day, as the Event
+	// of the Day in slot one of the events screen.
+	PinnedFile string `json:"pinned_file"`
+
+	// Clock is "12h" or "24h", for times shown on screen.
+	Clock string `json:"clock"`
+	// Timezone is the time zone the door keeps, by its IANA name such as
+	// "America/New_York": the day it shows, the times on its screens and
+	// the theme's night hours. Empty uses the system's.
+	Timezone string `json:"timezone"`
+
+	// Locale is the language of the month and day names in the dates on
+	// screen: a built-in locale by its code, such as "de", or the path of
+	// a locale file. See the locale package for the format.
+	Locale string `json:"locale"`
+
+	// NoticesDir holds the sysop's own templates for the screens shown when
+	// there are no pages for the day, as error.tmpl, empty.tmpl and
+	// stopped.tmpl, with a subdirectory per locale code for other
+	// languages. Screens it leaves out are the built-in ones; empty uses
+	// only those. See the notice package for the format.
+	NoticesDir string `json:"notices_dir"`
+
+	// ScreenDiff redraws only the parts of the screen that change when paging,
+	// which saves bandwidth on slow links; turn it off for terminals that
+	// drift out of step with what the door thinks they show.
+	ScreenDiff bool `json:"screen_diff"`
+
+	// Charset is the character set the door's art is drawn in: "cp437",
+	// "ascii", "utf8", or "auto" to use ASCII when the dropfile says the
+	// caller's terminal is ASCII-only, UTF-8 for -local in a UTF-8 locale,
+	// and CP437 otherwise. The regional DOS code pages "cp850", "cp852"
+	// and "cp866" also keep the letters they have in event text.
+	Charset string `json:"charset"`
+
+	// Newline is how lines sent to the caller end: "crlf", which BBS
+	// terminals expect, or "lf", for local testing in a terminal or file
+	// that adds its own carriage returns.
+	Newline string `json:"newline"`
+
+	// YearColors is "era" to color each year like its era badge, or
+	// "gradient" to shade it by century from deep blue to bright green,
+	// with two tones on terminals without the 256-color palette.
+	YearColors string `json:"year_colors"`
+
+	// Layout is "left" to draw the door's 80 columns flush left in a wider
+	// window, or "center" to center them in it.
+	Layout string `json:"layout"`
+
+	// Theme tunes the look of the entries beyond their colors.
+	Theme Theme `json:"theme"`
+
+	// SpecialDays dress the door up on the days of the year they key, as
+	// MM-DD, with other header art and colors.
+	SpecialDays map[string]SpecialDay `json:"special_days"`
+
+	// LoadingStyle is how the loading screen shows a fetch under way: "bar"
+	// for a block bar, "spinner", "dots" for the status typed out with dots
+	// after it, or "starfield" for stars flying past. Each is drawn in the
+	// Charset, ASCII included.
+	LoadingStyle string `json:"loading_style"`
+
+	// Splash is a screen of the sysop's art shown as each session starts,
+	// before the day's screens.
+	Splash Splash `json:"splash"`
+
+	// SaveDir is where the save key puts a copy of the screen, typically the
+	// caller's download or drop directory; {user} and {node} are replaced
+	// with the caller's name and node number. Empty disables saving.
+	// SaveFormat is "txt" for the events as plain text or "ans" for the
+	// whole screen with its colors.
+	SaveDir    string `json:"save_dir"`
+	SaveFormat string `json:"save_format"`
+
+	// Keys rebinds door actions, keyed by action name; each list replaces the
+	// default keys for that action. See the keymap package for key names.
+	Keys map[string][]string `json:"keys"`
+
+	// Plugins are extra screens drawn by external commands, opened with
+	// their own keys from the day's screens. See the plugin package for the
+	// protocol.
+	Plugins []Plugin `json:"plugins"`
+
+	// EasterEgg is a hidden screen of the sysop's art, opened by typing a
+	// run of keys on the day's screens.
+	EasterEgg EasterEgg `json:"easter_egg"`
+
+	// Hooks are commands run as each session starts, fails and ends.
+	Hooks Hooks `json:"hooks"`
+
+	// SessionLimit caps how long one caller may stay in the door, whatever
+	// time they have left on the BBS; 0 means no cap.
+	SessionLimit Duration `json:"session_limit"`
+
+	// StaleWhileRevalidate shows an expired cache entry immediately and
+	// refreshes it in the background instead of making the caller wait.
+	StaleWhileRevalidate bool `json:"stale_while_revalidate"`
+
+	// Offline keeps the door off the network: every day is served from the
+	// cache, however old, for boards with no way out to the internet or
+	// that are only connected now and then.
+	Offline bool `json:"offline"`
+
+	// Network retry policy. FetchDeadline bounds the whole fetch including
+	// retries; FetchAttemptTimeout bounds each single request. Retries wait
+	// BackoffBase, doubling each time, shifted randomly by up to BackoffJitter.
+	FetchAttempts       int      `json:"fetch_attempts"`
+	FetchAttemptTimeout Duration `json:"fetch_attempt_timeout"`
+	FetchDeadline       Duration `json:"fetch_deadline"`
+	BackoffBase         Duration `json:"backoff_base"`
+	BackoffJitter       Duration `json:"backoff_jitter"`
+
+	// CircuitThreshold is how many fetches in a row may fail before the API is
+	// skipped for CircuitCooldown; 0 disables the circuit breaker.
+	CircuitThreshold int      `json:"circuit_threshold"`
+	CircuitCooldown  Duration `json:"circuit_cooldown"`
+
+	// LogOutput is where logs go: "file" for LogFile, "syslog" for the
+	// syslog daemon or "journald" for the systemd journal.
+	LogOutput string `json:"log_output"`
+	// LogFile receives structured (JSON) logs; empty keeps logging on stderr.
+	LogFile string `json:"log_file"`
+	// StatsFile receives one JSON line per API request; empty disables it.
+	StatsFile string `json:"stats_file"`
+	// MetricsDir receives a summary of each day's sessions, cache use and
+	// API errors, for graphing; empty disables it. See the metrics package
+	// for the format.
+	MetricsDir string `json:"metrics_dir"`
+	// WebhookURL is POSTed to when the door hits trouble a sysop should
+	// know about: the API failing repeatedly, a damaged cache entry or a
+	// crash; empty disables it. WebhookFormat is "json" for a plain object
+	// or "discord" for a Discord channel webhook.
+	WebhookURL    string `json:"webhook_url"`
+	WebhookFormat string `json:"webhook_format"`
+	// RecordDir receives a capture of everything sent to each caller;
+	// empty disables it. RecordFormat is "ans" for the raw bytes or
+	// "asciicast" for an asciinema recording that keeps the timing.
+	RecordDir    string `json:"record_dir"`
+	RecordFormat string `json:"record_format"`
+
+	// Serial names a serial device, such as /dev/ttyS0, that the caller is
+	// reached through instead of stdin and stdout, for a board on dial-up
+	// hardware. Baud and Parity set up its line. SerialFlow is "hardware"
+	// for RTS/CTS flow control, with a dropped carrier ending the session,
+	// or "none" for a three-wire null-modem cable.
+	Serial     string `json:"serial"`
+	Baud       int    `json:"baud"`
+	Parity     string `json:"parity"`
+	SerialFlow string `json:"serial_flow"`
+}
+
+// Plugin is an extra screen drawn by an external command.
+type Plugin struct {
+	// Name identifies the plugin to its command and in the logs.
+	Name string `json:"name"`
+	// Label is the plugin's entry in the command bar and help screen.
+	Label string `json:"label"`
+	// Keys open the screen; they must not clash with other bindings.
+	Keys []string `json:"keys"`
+	// Command is the program and its arguments, run without a shell.
+	Command []string `json:"command"`
+	// Timeout bounds each run; 0 means DefaultPluginTimeout.
+	Timeout Duration `json:"timeout"`
+	// MinLevel hides the plugin from callers below this security level.
+	MinLevel int `json:"min_level"`
+}
+
+// Splash is an intro screen: the ANSI art file Art, shown for at least
+// MinTime while the day is fetched behind it, and then until the fetch is
+// done. Any key skips it. An empty Art leaves it out.
+type Splash struct {
+	Art     string   `json:"art"`
+	MinTime Duration `json:"min_time"`
+}
+
+// EasterEgg is a hidden screen: typing Keys, in order, on the day's
+// screens shows the ANSI art file Art. Keys are named as in Keys, and must
+// not be bound to anything, so typing them does nothing else. Leaving both
+// empty leaves the egg out.
+type EasterEgg struct {
+	Keys []string `json:"keys"`
+	Art  string   `json:"art"`
+}
+
+// Hooks are commands, each a program and its arguments run without a
+// shell, with the session's details in the environment. An empty command
+// is skipped. Timeout bounds each run.
+type Hooks struct {
+	OnStart []string `json:"on_start"`
+	OnExit  []string `json:"on_exit"`
+	OnError []string `json:"on_error"`
+	Timeout Duration `json:"timeout"`
+}
+
+// Theme is the look of the entries.
+type Theme struct {
+	Prefix Prefix `json:"prefix"`
+	Wrap   Wrap   `json:"wrap"`
+	// Eras colors each era's years, badge and name, keyed by its badge,
+	// each one of terminal.ColorNames. Legend shows the eras' badges and
+	// names in their colors under the footer.
+	Eras   map[string]string `json:"eras"`
+	Legend bool              `json:"legend"`
+	Night  Night             `json:"night"`
+	// Seasons shifts the accents of the door's rules and title with the
+	// season of the day shown, in the north or south hemisphere's
+	// seasons, or "off" for the door's own colors all year.
+	Seasons string `json:"seasons"`
+}
+
+// ThemeSeasons are the values Theme.Seasons accepts.
+var ThemeSeasons = []string{"north", "south", "off"}
+
+// Night is the palette drawn for callers who come in at night, from From
+// o'clock until To o'clock in the door's time zone, when Enabled. Colors
+// maps each color the door draws to the one drawn instead, by
+// terminal.ColorNames; by default every bright color is drawn dimmer.
+type Night struct {
+	Enabled bool              `json:"enabled"`
+	From    int               `json:"from"`
+	To      int               `json:"to"`
+	Colors  map[string]string `json:"colors"`
+}
+
+// SpecialDay is how the door dresses up for a day. Header is the art at
+// the top of its screens: a built-in header by name, one of
+// terminal.Headers, or the path of an ANSI art file; empty is the door's
+// own. Colors swaps the door's colors for others, by terminal.ColorNames.
+type SpecialDay struct {
+	Header string            `json:"header"`
+	Colors map[string]string `json:"colors"`
+}
+
+// Prefix is how an entry's year column is set out before its text. Format
+// is the column, with {year} and {era} standing for the year and its era
+// badge, such as "{year} <{era}>", "[{year}]" or "{year} |". Years are
+// right-aligned in at least YearWidth columns, and the rest is drawn in
+// Color, one of terminal.ColorNames.
+type Prefix struct {
+	Format    string `json:"format"`
+	YearWidth int    `json:"year_width"`
+	Color     string `json:"color"`
+}
+
+// MaxPrefixWidth is the most columns Prefix.Format may take beyond the
+// year, and MaxYearWidth the most Prefix.YearWidth may be.
+const (
+	MaxPrefixWidth = 16
+	MaxYearWidth   = 10
+)
+
+// Wrap is how an entry's text wraps beside its year. Hang is "text" to
+// line the lines after the first up under its first, or "year" to start
+// them under the year column; Indent moves them further in by that many
+// columns. Justify is "ragged" to leave the right margin uneven, or
+// "filled" to space out every line but the last to meet it.
+type Wrap struct {
+	Hang    string `json:"hang"`
+	Indent  int    `json:"indent"`
+	Justify string `json:"justify"`
+}
+
+// WrapHangs are the values Wrap.Hang accepts.
+var WrapHangs = []string{"text", "year"}
+
+// WrapJustifies are the values Wrap.Justify accepts.
+var WrapJustifies = []string{"ragged", "filled"}
+
+// MaxWrapIndent is the most Wrap.Indent may be.
+const MaxWrapIndent = 20
+
+// DefaultPluginTimeout is how long a plugin command may run when its
+// Timeout is not set.
+const DefaultPluginTimeout = 10 * time.Second
+
+// KnownSections are the feed sections that can be listed in Sections.
+var KnownSections = []string{"births", "deaths", "holidays", "featured", "news"}
+
+// KnownFeatures are the optional features that can be listed in MinLevels.
+var KnownFeatures = []string{"links", "births", "deaths", "holidays", "featured", "news", "save", "birthyear"}
+
+// SaveFormats are the file formats SaveFormat accepts.
+var SaveFormats = []string{"txt", "ans"}
+
+// Charsets are the values Charset accepts.
+var Charsets = []string{"auto", "cp437", "ascii", "utf8", "cp850", "cp852", "cp866"}
+
+// Layouts are the values Layout accepts.
+var Layouts = []string{"left", "center"}
+
+// LoadingStyles are the loading screens LoadingStyle accepts.
+var LoadingStyles = []string{"bar", "spinner", "dots", "starfield"}
+
+// Newlines are the line endings Newline accepts.
+var Newlines = []string{"crlf", "lf"}
+
+// LogOutputs are the values LogOutput accepts.
+var LogOutputs = []string{"file", "syslog", "journald"}
+
+// WebhookFormats are the values WebhookFormat accepts.
+var WebhookFormats = []string{"json", "discord"}
+
+// RecordFormats are the capture formats RecordFormat accepts.
+var RecordFormats = []string{"ans", "asciicast"}
+
+// BaudRates are the serial speeds Baud accepts.
+var BaudRates = []int{300, 1200, 2400, 4800, 9600, 19200, 38400, 57600, 115200}
+
+// Parities are the values Parity accepts.
+var Parities = []string{"none", "even", "odd"}
+
+// SerialFlows are the flow control settings SerialFlow accepts.
+var SerialFlows = []string{"hardware", "none"}
+
+// Default returns the built-in settings used when no config file is present.
+func Default() Config {
+	return Config{
+		Strategy:    "era-based",
+		Shuffle:     true,
+		CacheTTL:    Duration(24 * time.Hour),
+		LeapBlend:   true,
+		Links:       true,
+		Attribution: true,
+		Language:    "en",
+
+		Clock:        "12h",
+		Locale:       "en",
+		ScreenDiff:   true,
+		Charset:      "auto",
+		Newline:      "crlf",
+		YearColors:   "era",
+		Layout:       "left",
+		LoadingStyle: "bar",
+		SaveFormat:   "txt",
+		Splash:       Splash{MinTime: Duration(3 * time.Second)},
+
+		Theme: Theme{
+			Prefix: Prefix{Format: terminal.DefaultPrefix, YearWidth: 4, Color: "bright-cyan"},
+			Wrap:   Wrap{Hang: "text", Justify: "ragged"},
+			Eras: map[string]string{
+				"ANC": "yellow",
+				"MED": "bright-magenta",
+				"EMD": "bright-red",
+				"MOD": "bright-cyan",
+				"CON": "bright-green",
+			},
+			Legend:  true,
+			Night:   Night{From: 22, To: 7, Colors: maps.Clone(terminal.Dim)},
+			Seasons: "north",
+		},
+
+		StaleWhileRevalidate: true,
+
+		FetchAttempts:       3,
+		FetchAttemptTimeout: Duration(12 * time.Second),
+		FetchDeadline:       Duration(15 * time.Second),
+		BackoffBase:         Duration(500 * time.Millisecond),
+		BackoffJitter:       Duration(100 * time.Millisecond),
+
+		CircuitThreshold: 3,
+		CircuitCooldown:  Duration(5 * time.Minute),
+
+		LogOutput: "file",
+
+		WebhookFormat: "json",
+		RecordFormat:  "ans",
+
+		Baud:       19200,
+		Parity:     "none",
+		SerialFlow: "hardware",
+
+		Hooks: Hooks{Timeout: Duration(10 * time.Second)},
+
+		SpecialDays: map[string]SpecialDay{
+			"01-01": {Header: "new-year"},
+			"10-31": {Header: "halloween"},
+			"12-25": {Header: "christmas"},
+		},
+	}
+}
+
+// Load reads the config file at path on top of the defaults. Keys missing from
+// the file keep their default value. A missing file is reported with an error
+// wrapping os.ErrNotExist so callers can decide whether that matters.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// IsNotExist reports whether err from Load means the file simply isn't there.
+func IsNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// Validate checks values that can't be caught while decoding.
+func (c Config) Validate() error {
+	var errs []error
+	if c.CacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("cache_ttl must be positive, got %v", c.CacheTTL))
+	}
+	if c.CacheMaxSize < 0 {
+		errs = append(errs, fmt.Errorf("cache_max_size must not be negative, got %v", c.CacheMaxSize))
+	}
+	if c.Favorites < 0 || c.Favorites > 5 {
+		errs = append(errs, fmt.Errorf("favorites must be 0 to 5, got %d", c.Favorites))
+	}
+	for name, ttl := range c.CacheTTLs {
+		if name != "events" && name != "summary" && !slices.Contains(KnownSections, name) {
+			errs = append(errs, fmt.Errorf("unknown feed %q in cache_ttls, expected events, summary or one of %v", name, KnownSections))
+		}
+		if ttl <= 0 {
+			errs = append(errs, fmt.Errorf("cache_ttls %s must be positive, got %v", name, ttl))
+		}
+	}
+	for _, name := range c.Categories {
+		if _, ok := category.Lookup(name); !ok {
+			errs = append(errs, fmt.Errorf("unknown category %q, expected one of %v", name, category.Names()))
+		}
+	}
+	for _, name := range c.Sections {
+		if !slices.Contains(KnownSections, name) {
+			errs = append(errs, fmt.Errorf("unknown section %q, expected one of %v", name, KnownSections))
+		}
+	}
+	for name, level := range c.MinLevels {
+		if !slices.Contains(KnownFeatures, name) {
+			errs = append(errs, fmt.Errorf("unknown feature %q in min_levels, expected one of %v", name, KnownFeatures))
+		}
+		if level < 0 {
+			errs = append(errs, fmt.Errorf("min_levels %s must not be negative, got %d", name, level))
+		}
+	}
+	if !languageCode(c.Language) {
+		errs = append(errs, fmt.Errorf("language %q is not a Wikipedia language code, such as en or de", c.Language))
+	}
+	if c.Clock != "12h" && c.Clock != "24h" {
+		errs = append(errs, fmt.Errorf("clock must be 12h or 24h, got %q", c.Clock))
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("unknown timezone %q: %w", c.Timezone, err))
+	}
+	if _, err := locale.Load(c.Locale); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := notice.Load(c.NoticesDir, locale.Code(c.Locale), nil); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(Charsets, c.Charset) {
+		errs = append(errs, fmt.Errorf("unknown charset %q, expected one of %v", c.Charset, Charsets))
+	}
+	if c.YearColors != "era" && c.YearColors != "gradient" {
+		errs = append(errs, fmt.Errorf("year_colors must be era or gradient, got %q", c.YearColors))
+	}
+	if !slices.Contains(LogOutputs, c.LogOutput) {
+		errs = append(errs, fmt.Errorf("unknown log_output %q, expected one of %v", c.LogOutput, LogOutputs))
+	}
+	if !slices.Contains(WebhookFormats, c.WebhookFormat) {
+		errs = append(errs, fmt.Errorf("unknown webhook_format %q, expected one of %v", c.WebhookFormat, WebhookFormats))
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("webhook_url %q is not an http or https URL", c.WebhookURL))
+		}
+	}
+	if !slices.Contains(LoadingStyles, c.LoadingStyle) {
+		errs = append(errs, fmt.Errorf("unknown loading_style %q, expected one of %v", c.LoadingStyle, LoadingStyles))
+	}
+	if c.Splash.Art != "" {
+		if _, err := terminal.LoadArt(c.Splash.Art); err != nil {
+			errs = append(errs, fmt.Errorf("splash art: %w", err))
+		}
+	}
+	if c.Splash.MinTime < 0 {
+		errs = append(errs, fmt.Errorf("splash min_time must not be negative, got %v", c.Splash.MinTime))
+	}
+	if !slices.Contains(SaveFormats, c.SaveFormat) {
+		errs = append(errs, fmt.Errorf("unknown save_format %q, expected one of %v", c.SaveFormat, SaveFormats))
+	}
+	if _, err := c.Bindings(); err != nil {
+		errs = append(errs, err)
+	}
+	names := map[string]bool{}
+	for i, p := range c.Plugins {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("plugins[%d]: name is required", i))
+		case names[p.Name]:
+			errs = append(errs, fmt.Errorf("plugin %q is listed twice", p.Name))
+		}
+		names[p.Name] = true
+		if len(p.Command) == 0 || p.Command[0] == "" {
+			errs = append(errs, fmt.Errorf("plugin %q: command is required", p.Name))
+		}
+		if len(p.Keys) == 0 {
+			errs = append(errs, fmt.Errorf("plugin %q: at least one key is needed", p.Name))
+		}
+		if p.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("plugin %q: timeout must not be negative, got %v", p.Name, p.Timeout))
+		}
+	}
+	if egg := c.EasterEgg; len(egg.Keys) > 0 || egg.Art != "" {
+		if len(egg.Keys) == 0 {
+			errs = append(errs, errors.New("easter_egg: at least one key is needed"))
+		}
+		if egg.Art == "" {
+			errs = append(errs, errors.New("easter_egg: art is required"))
+		} else if _, err := terminal.LoadArt(egg.Art); err != nil {
+			errs = append(errs, fmt.Errorf("easter_egg art: %w", err))
+		}
+	}
+	if c.SessionLimit < 0 {
+		errs = append(errs, fmt.Errorf("session_limit must not be negative, got %v", c.SessionLimit))
+	}
+	if c.FetchAttempts < 1 {
+		errs = append(errs, fmt.Errorf("fetch_attempts must be at least 1, got %d", c.FetchAttempts))
+	}
+	if c.FetchAttemptTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("fetch_attempt_timeout must be positive, got %v", c.FetchAttemptTimeout))
+	}
+	if c.FetchDeadline <= 0 {
+		errs = append(errs, fmt.Errorf("fetch_deadline must be positive, got %v", c.FetchDeadline))
+	}
+	if c.BackoffBase < 0 || c.BackoffJitter < 0 {
+		errs = append(errs, fmt.Errorf("backoff_base and backoff_jitter must not be negative"))
+	}
+	if c.CircuitThreshold < 0 {
+		errs = append(errs, fmt.Errorf("circuit_threshold must not be negative, got %d", c.CircuitThreshold))
+	}
+	if c.CircuitThreshold > 0 && c.CircuitCooldown <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_cooldown must be positive, got %v", c.CircuitCooldown))
+	}
+	if c.Hooks.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("hooks timeout must be positive, got %v", c.Hooks.Timeout))
+	}
+	if !slices.Contains(Layouts, c.Layout) {
+		errs = append(errs, fmt.Errorf("unknown layout %q, expected one of %v", c.Layout, Layouts))
+	}
+	if prefix := c.Theme.Prefix; !strings.Contains(prefix.Format, "{year}") {
+		errs = append(errs, fmt.Errorf("theme prefix format %q has no {year}", prefix.Format))
+	} else if w := textwrap.Width(strings.ReplaceAll(prefix.Format, "{year}", "")); w > MaxPrefixWidth {
+		errs = append(errs, fmt.Errorf("theme prefix format %q takes %d columns besides the year, at most %d fit", prefix.Format, w, MaxPrefixWidth))
+	}
+	if w := c.Theme.Prefix.YearWidth; w < 1 || w > MaxYearWidth {
+		errs = append(errs, fmt.Errorf("theme prefix year_width must be 1-%d, got %d", MaxYearWidth, w))
+	}
+	if _, ok := terminal.Colors[c.Theme.Prefix.Color]; !ok {
+		errs = append(errs, fmt.Errorf("unknown theme prefix color %q, expected one of %v", c.Theme.Prefix.Color, terminal.ColorNames()))
+	}
+	for _, badge := range slices.Sorted(maps.Keys(c.Theme.Eras)) {
+		color := c.Theme.Eras[badge]
+		if !slices.Contains(era.Badges(), badge) {
+			errs = append(errs, fmt.Errorf("unknown era %q in theme eras, expected one of %v", badge, era.Badges()))
+		} else if _, ok := terminal.Colors[color]; !ok {
+			errs = append(errs, fmt.Errorf("unknown color %q for era %s, expected one of %v", color, badge, terminal.ColorNames()))
+		}
+	}
+	if n := c.Theme.Night; n.From < 0 || n.From > 23 || n.To < 0 || n.To > 23 {
+		errs = append(errs, fmt.Errorf("theme night from and to must be hours 0-23, got %d and %d", n.From, n.To))
+	}
+	for _, from := range slices.Sorted(maps.Keys(c.Theme.Night.Colors)) {
+		for _, color := range []string{from, c.Theme.Night.Colors[from]} {
+			if _, ok := terminal.Colors[color]; !ok {
+				errs = append(errs, fmt.Errorf("unknown color %q in theme night colors, expected one of %v", color, terminal.ColorNames()))
+			}
+		}
+	}
+	if !slices.Contains(ThemeSeasons, c.Theme.Seasons) {
+		errs = append(errs, fmt.Errorf("unknown theme seasons %q, expected one of %v", c.Theme.Seasons, ThemeSeasons))
+	}
+	for _, day := range slices.Sorted(maps.Keys(c.SpecialDays)) {
+		if _, err := time.Parse("01-02", day); err != nil {
+			errs = append(errs, fmt.Errorf("special_days %q is not a day of the year, expected MM-DD", day))
+		}
+		special := c.SpecialDays[day]
+		if _, ok := terminal.Headers[special.Header]; !ok && special.Header != "" {
+			if _, err := terminal.LoadHeader(special.Header); errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("unknown special_days %s header %q, expected an art file or one of %v", day, special.Header, slices.Sorted(maps.Keys(terminal.Headers))))
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("special_days %s header: %w", day, err))
+			}
+		}
+		for _, from := range slices.Sorted(maps.Keys(special.Colors)) {
+			for _, color := range []string{from, special.Colors[from]} {
+				if _, ok := terminal.Colors[color]; !ok {
+					errs = append(errs, fmt.Errorf("unknown color %q in special_days %s colors, expected one of %v", color, day, terminal.ColorNames()))
+				}
+			}
+		}
+	}
+	if !slices.Contains(WrapHangs, c.Theme.Wrap.Hang) {
+		errs = append(errs, fmt.Errorf("unknown theme wrap hang %q, expected one of %v", c.Theme.Wrap.Hang, WrapHangs))
+	}
+	if c.Theme.Wrap.Indent < 0 || c.Theme.Wrap.Indent > MaxWrapIndent {
+		errs = append(errs, fmt.Errorf("theme wrap indent must be 0-%d, got %d", MaxWrapIndent, c.Theme.Wrap.Indent))
+	}
+	if !slices.Contains(WrapJustifies, c.Theme.Wrap.Justify) {
+		errs = append(errs, fmt.Errorf("unknown theme wrap justify %q, expected one of %v", c.Theme.Wrap.Justify, WrapJustifies))
+	}
+	if !slices.Contains(Newlines, c.Newline) {
+		errs = append(errs, fmt.Errorf("unknown newline %q, expected one of %v", c.Newline, Newlines))
+	}
+	if !slices.Contains(RecordFormats, c.RecordFormat) {
+		errs = append(errs, fmt.Errorf("unknown record_format %q, expected one of %v", c.RecordFormat, RecordFormats))
+	}
+	if !slices.Contains(BaudRates, c.Baud) {
+		errs = append(errs, fmt.Errorf("unsupported baud %d, expected one of %v", c.Baud, BaudRates))
+	}
+	if !slices.Contains(Parities, c.Parity) {
+		errs = append(errs, fmt.Errorf("unknown parity %q, expected one of %v", c.Parity, Parities))
+	}
+	if !slices.Contains(SerialFlows, c.SerialFlow) {
+		errs = append(errs, fmt.Errorf("unknown serial_flow %q, expected one of %v", c.SerialFlow, SerialFlows))
+	}
+	return errors.Join(errs...)
+}
+
+// languageCode reports whether s looks like a Wikipedia language code:
+// lowercase letters, in parts joined by hyphens as in "zh-yue".
+func languageCode(s string) bool {
+	for _, part := range strings.Split(s, "-") {
+		if part == "" || strings.Trim(part, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Bindings builds the key bindings: the defaults with Keys applied, plus
+// each plugin's keys. The easter egg's keys must be left unbound.
+func (c Config) Bindings() (*keymap.Map, error) {
+	m, err := keymap.New(c.Keys)
+	errs := []error{err}
+	for _, p := range c.Plugins {
+		errs = append(errs, m.Bind(keymap.PluginAction(p.Name), p.Keys))
+	}
+	for _, name := range c.EasterEgg.Keys {
+		ev, err := input.ParseKey(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("easter_egg keys: %v", err))
+			continue
+		}
+		if m.Bound(ev) {
+			errs = append(errs, fmt.Errorf("easter_egg key %s is bound to %s", ev, m.Lookup(ev)))
+		}
+	}
+	return m, errors.Join(errs...)
+}
+
+// RunTimeout returns how long p's command may run.
+func (p Plugin) RunTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return time.Duration(p.Timeout)
+	}
+	return DefaultPluginTimeout
+}
+
+// Allows reports whether a caller with security level secLevel may use feature.
+func (c Config) Allows(feature string, secLevel int) bool {
+	return secLevel >= c.MinLevels[feature]
+}
+
+// Duration is a time.Duration written as a Go duration string ("24h", "30m")
+// in the config file and on the command line.
+type Duration time.Duration
+
+// String implements fmt.Stringer.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ByteSize is a size in bytes written as a number with an optional unit
+// ("500KB", "50MB", "1GB"; units are powers of 1024) in the config file and
+// on the command line.
+type ByteSize int64
+
+var byteUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// String implements fmt.Stringer, using the largest unit that divides evenly.
+func (b ByteSize) String() string {
+	for _, u := range byteUnits[:3] {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.ToUpper(strings.TrimSpace(string(text)))
+	unit := ByteSize(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q, expected a number of bytes or e.g. 50MB", text)
+	}
+	*b = ByteSize(n) * unit
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package dropfile reads the dropfiles BBS software writes for doors and
+// normalizes them into a DoorSession.
+package dropfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Emulation values, following door32.sys numbering.
+const (
+	EmulationASCII  = 0
+	EmulationANSI   = 1
+	EmulationAvatar = 2
+	EmulationRIP    = 3
+)
+
+// DoorSession is what the door knows about the caller, whichever dropfile
+// format it came from. Fields a format does not carry are left zero.
+type DoorSession struct {
+	Format string // parser name, e.g. "door32.sys"
+	Path   string // dropfile that was read
+
+	Node       int
+	BbsName    string
+	UserName   string // handle or alias
+	RealName   string
+	UserNumber int
+	SecLevel   int
+	TimeLeft   int // minutes
+	Emulation  int
+	CommType   int // door32.sys: 0 local, 1 serial, 2 telnet
+	CommPort   int
+	BaudRate   int
+}
+
+// FieldError describes one missing or malformed field. Line is 1-based for
+// text formats; binary formats set Offset instead.
+type FieldError struct {
+	Line    int
+	Offset  int
+	Field   string
+	Value   string
+	Problem string
+}
+
+func (e *FieldError) Error() string {
+	pos := fmt.Sprintf("line %d", e.Line)
+	if e.Line == 0 {
+		pos = fmt.Sprintf("byte %d", e.Offset)
+	}
+	if e.Value == "" {
+		return fmt.Sprintf("%s (%s) %s", pos, e.Field, e.Problem)
+	}
+	return fmt.Sprintf("%s (%s): %q %s", pos, e.Field, e.Value, e.Problem)
+}
+
+// MalformedError lists everything wrong with one dropfile, so a sysop can
+// fix the batch file or BBS setup in one go.
+type MalformedError struct {
+	Path string
+	Errs []error
+}
+
+func (e *MalformedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is malformed:", e.Path)
+	for _, err := range e.Errs {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *MalformedError) Unwrap() []error { return e.Errs }
+
+// Parser reads one dropfile format.
+type Parser struct {
+	// Name identifies the format in messages and DoorSession.Format.
+	Name string
+	// Patterns are filepath.Match patterns, in lower case, for the file names
+	// this format uses.
+	Patterns []string
+	// Parse decodes the file contents. name is the base name of the file, for
+	// formats that encode the node in it. Problems with the contents are
+	// reported as a *MalformedError.
+	Parse func(name string, data []byte) (*DoorSession, error)
+}
+
+// parsers is the registry, in probe order.
+var parsers []Parser
+
+// Register adds a parser to the registry. When a node directory holds more
+// than one dropfile, parsers registered earlier win.
+func Register(p Parser) {
+	parsers = append(parsers, p)
+}
+
+// Formats returns the names of the registered formats in probe order.
+func Formats() []string {
+	names := make([]string, len(parsers))
+	for i, p := range parsers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Load reads the dropfile at path. path may be the dropfile itself or a node
+// directory, which is probed for each registered format in turn. File names
+// are matched case-insensitively.
+func Load(path string) (*DoorSession, error) {
+	cleanPath := filepath.Clean(path)
+
+	if fi, err := os.Stat(cleanPath); err == nil && !fi.IsDir() {
+		p, ok := parserFor(filepath.Base(cleanPath))
+		if !ok {
+			// Unrecognized names are read as door32.sys, as they always were
+			p = parsers[0]
+		}
+		return parseFile(p, cleanPath)
+	}
+
+	entries, err := os.ReadDir(cleanPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %v", cleanPath, err)
+	}
+	for _, p := range parsers {
+		for _, e := range entries {
+			if !e.IsDir() && p.matches(e.Name()) {
+				return parseFile(p, filepath.Join(cleanPath, e.Name()))
+			}
+		}
+	}
+	return nil, fmt.Errorf("no dropfile found in %s (looked for %s)", cleanPath, strings.Join(Formats(), ", "))
+}
+
+func parserFor(name string) (Parser, bool) {
+	for _, p := range parsers {
+		if p.matches(name) {
+			return p, true
+		}
+	}
+	return Parser{}, false
+}
+
+func (p Parser) matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range p.Patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFile(p Parser, path string) (*DoorSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	s, err := p.Parse(filepath.Base(path), data)
+	if merr, ok := err.(*MalformedError); ok {
+		merr.Path = path
+		return nil, merr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	s.Format = p.Name
+	s.Path = path
+	return s, nil
+}
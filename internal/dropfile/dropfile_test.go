@@ -0,0 +1,169 @@
+package dropfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// numbered writes a text dropfile with the given lines set and the rest of
+// its n lines blank, ending in CRLF as the BBSes write them. Blank lines at
+// the end don't count, so the file ends at its last line set.
+func numbered(n int, set map[int]string) string {
+	lines := make([]string, n)
+	for i, s := range set {
+		lines[i-1] = s
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// pcboard builds a pcboard.sys record for node, with user number user,
+// graphics mode graphics and connect speed connect.
+func pcboard(node byte, user int16, graphics byte, connect string) string {
+	data := []byte(strings.Repeat(" ", pcboardSysSize))
+	data[11] = graphics
+	copy(data[18:23], connect)
+	binary.LittleEndian.PutUint16(data[23:], uint16(user))
+	copy(data[84:109], "JOHN SMITH")
+	binary.LittleEndian.PutUint16(data[109:], 25)
+	data[111] = node
+	data[125] = 1
+	return string(data)
+}
+
+func TestLoad(t *testing.T) {
+	for _, tc := range []struct {
+		file, data string
+		want       DoorSession
+	}{
+		{
+			"door32.sys",
+			"2\r\n5\r\n38400\r\nTest BBS\r\n42\r\nJohn Smith\r\nJohnny\r\n100\r\n60\r\n1\r\n3\r\n",
+			DoorSession{Format: "door32.sys", Node: 3, BbsName: "Test BBS", UserName: "Johnny", RealName: "John Smith", UserNumber: 42, SecLevel: 100, TimeLeft: 60, Emulation: EmulationANSI, CommType: 2, CommPort: 5, BaudRate: 38400},
+		},
+		{
+			"DOOR32.SYS",
+			"0\n0\n0\nTest BBS\n1\nSysop\nSysop\n255\n1440\n0\n1\x1agarbage after the end of file",
+			DoorSession{Format: "door32.sys", Node: 1, BbsName: "Test BBS", UserName: "Sysop", RealName: "Sysop", UserNumber: 1, SecLevel: 255, TimeLeft: 1440},
+		},
+		{
+			"DOOR.SYS",
+			numbered(52, map[int]string{1: "COM1:", 2: "38400", 3: "8", 4: "2", 10: "John Smith", 15: "100", 19: "45", 20: "GR", 26: "17", 36: "Johnny"}),
+			DoorSession{Format: "DOOR.SYS", Node: 2, UserName: "Johnny", RealName: "John Smith", UserNumber: 17, SecLevel: 100, TimeLeft: 45, Emulation: EmulationANSI, CommType: 1, CommPort: 1, BaudRate: 38400},
+		},
+		{
+			"door.sys",
+			numbered(20, map[int]string{1: "COM0:", 2: "0", 4: "1", 10: "John Smith", 15: "10", 19: "30", 20: "NG"}),
+			DoorSession{Format: "DOOR.SYS", Node: 1, UserName: "John Smith", RealName: "John Smith", SecLevel: 10, TimeLeft: 30},
+		},
+		{
+			"DORINFO3.DEF",
+			numbered(13, map[int]string{1: "Test BBS", 4: "COM2", 5: "19200 BAUD,N,8,1", 7: "JOHN", 8: "SMITH", 10: "1", 11: "50", 12: "30"}),
+			DoorSession{Format: "DORINFO1.DEF", Node: 3, BbsName: "Test BBS", UserName: "JOHN SMITH", RealName: "JOHN SMITH", SecLevel: 50, TimeLeft: 30, Emulation: EmulationANSI, CommType: 1, CommPort: 2, BaudRate: 19200},
+		},
+		{
+			"chain.txt",
+			numbered(31, map[int]string{1: "7", 2: "Johnny", 3: "John Smith", 11: "80", 14: "1", 15: "1", 16: "1800", 20: "2400", 21: "1", 22: "Test BBS"}),
+			DoorSession{Format: "chain.txt", BbsName: "Test BBS", UserName: "Johnny", RealName: "John Smith", UserNumber: 7, SecLevel: 80, TimeLeft: 30, Emulation: EmulationANSI, CommType: 1, CommPort: 1, BaudRate: 2400},
+		},
+		{
+			"PCBOARD.SYS",
+			pcboard(4, 17, 'Y', "9600"),
+			DoorSession{Format: "pcboard.sys", Node: 4, UserName: "JOHN SMITH", RealName: "JOHN SMITH", UserNumber: 17, TimeLeft: 25, Emulation: EmulationANSI, CommType: 1, CommPort: 1, BaudRate: 9600},
+		},
+		{
+			"pcboard.sys",
+			pcboard(1, 2, 'N', "Local"),
+			DoorSession{Format: "pcboard.sys", Node: 1, UserName: "JOHN SMITH", RealName: "JOHN SMITH", UserNumber: 2, TimeLeft: 25, CommType: 1, CommPort: 1},
+		},
+	} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, tc.file)
+		if err := os.WriteFile(path, []byte(tc.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Found both by name and by probing the node directory
+		for _, from := range []string{path, dir} {
+			s, err := Load(from)
+			if err != nil {
+				t.Errorf("%s: %v", tc.file, err)
+				continue
+			}
+			want := tc.want
+			want.Path = path
+			if *s != want {
+				t.Errorf("%s from %s:\n got %+v\nwant %+v", tc.file, from, *s, want)
+			}
+		}
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	for _, tc := range []struct {
+		file, data string
+		lines      []int // of the fields reported, or offsets for pcboard.sys
+	}{
+		{"door32.sys", "2\r\nfive\r\n38400\r\nTest BBS\r\n42\r\n", []int{2, 6, 7, 8, 9, 10, 11}},
+		{"door32.sys", "3\n0\n0\nBBS\n1\nA\nA\n1\n1\n9\n1\n", []int{1, 10}},
+		{"DOOR.SYS", numbered(20, map[int]string{1: "LPT1", 2: "0", 4: "1", 15: "1", 19: "1", 20: "XX"}), []int{1, 20}},
+		{"DORINFO1.DEF", numbered(9, map[int]string{1: "BBS", 4: "LPT1", 5: "2400", 7: "A", 8: "B", 9: "C"}), []int{4, 11, 12, 10}},
+		{"chain.txt", numbered(22, map[int]string{1: "1", 11: "1", 14: "2", 15: "0", 16: "60", 20: "0", 21: "0", 22: "BBS"}), []int{14}},
+		{"pcboard.sys", pcboard(0, -1, 'X', "fast"), []int{18, 11, 23, 111}},
+		{"pcboard.sys", "too short", []int{9}},
+	} {
+		path := filepath.Join(t.TempDir(), tc.file)
+		if err := os.WriteFile(path, []byte(tc.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path)
+		var merr *MalformedError
+		if !errors.As(err, &merr) {
+			t.Errorf("%s: %v, want a *MalformedError", tc.file, err)
+			continue
+		}
+		if merr.Path != path {
+			t.Errorf("%s: error names %s, want %s", tc.file, merr.Path, path)
+		}
+		var got []int
+		for _, e := range merr.Errs {
+			var ferr *FieldError
+			if !errors.As(e, &ferr) {
+				t.Errorf("%s: %v is not a *FieldError", tc.file, e)
+				continue
+			}
+			got = append(got, max(ferr.Line, ferr.Offset))
+		}
+		if !slices.Equal(got, tc.lines) {
+			t.Errorf("%s: problems at %v, want %v:\n%v", tc.file, got, tc.lines, err)
+		}
+	}
+}
+
+func TestLoadProbeOrder(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"DOOR.SYS":   numbered(20, map[int]string{1: "COM0:", 2: "0", 4: "2", 15: "1", 19: "1", 20: "GR"}),
+		"door32.sys": "0\n0\n0\nBBS\n1\nA\nA\n1\n1\n1\n1\n",
+		"notes.txt":  "not a dropfile",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Format != "door32.sys" {
+		t.Errorf("node directory with door32.sys and DOOR.SYS read as %s", s.Format)
+	}
+
+	if _, err := Load(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no dropfile found") {
+		t.Errorf("empty node directory: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package dropfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(Parser{Name: "door32.sys", Patterns: []string{"door32.sys"}, Parse: parseDoor32})
+	Register(Parser{Name: "DOOR.SYS", Patterns: []string{"door.sys"}, Parse: parseDoorSys})
+	Register(Parser{Name: "DORINFO1.DEF", Patterns: []string{"dorinfo?.def"}, Parse: parseDorinfo})
+	Register(Parser{Name: "chain.txt", Patterns: []string{"chain.txt"}, Parse: parseChain})
+	Register(Parser{Name: "pcboard.sys", Patterns: []string{"pcboard.sys"}, Parse: parsePCBoard})
+}
+
+// textFile reads numbered lines of a text dropfile, collecting a FieldError
+// for every missing or malformed field rather than stopping at the first.
+type textFile struct {
+	lines []string
+	errs  []error
+}
+
+// newTextFile splits data into trimmed lines, accepting CRLF or LF endings
+// and stopping at a DOS end-of-file marker.
+func newTextFile(data []byte) *textFile {
+	if i := bytes.IndexByte(data, 0x1a); i >= 0 {
+		data = data[:i]
+	}
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return &textFile{lines: lines}
+}
+
+func (f *textFile) fail(line int, field, value, problem string) {
+	f.errs = append(f.errs, &FieldError{Line: line, Field: field, Value: value, Problem: problem})
+}
+
+// has reports whether the file reaches line n (1-based).
+func (f *textFile) has(n int) bool { return n <= len(f.lines) }
+
+// str returns line n, recording an error if the file is too short.
+func (f *textFile) str(n int, field string) string {
+	if !f.has(n) {
+		f.fail(n, field, "", "is missing, the file ends at line "+strconv.Itoa(len(f.lines)))
+		return ""
+	}
+	return f.lines[n-1]
+}
+
+// num parses line n as a whole number in [min, max].
+func (f *textFile) num(n int, field string, min, max int) int {
+	s := f.str(n, field)
+	if !f.has(n) {
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	return f.check(n, field, s, v, err == nil, min, max)
+}
+
+// numPrefix parses the number at the start of line n after an optional
+// case-insensitive prefix, ignoring anything after it: "COM1:" or
+// "38400 BAUD,N,8,1".
+func (f *textFile) numPrefix(n int, field, prefix string, min, max int) int {
+	s := f.str(n, field)
+	if !f.has(n) {
+		return 0
+	}
+	v, ok := leadingInt(s, prefix)
+	return f.check(n, field, s, v, ok, min, max)
+}
+
+func (f *textFile) check(n int, field, s string, v int, ok bool, min, max int) int {
+	switch {
+	case !ok:
+		f.fail(n, field, s, "is not a number")
+	case v < min || v > max:
+		f.fail(n, field, s, "is out of range "+strconv.Itoa(min)+"-"+strconv.Itoa(max))
+	}
+	return v
+}
+
+func (f *textFile) err() error {
+	if len(f.errs) == 0 {
+		return nil
+	}
+	return &MalformedError{Errs: f.errs}
+}
+
+func leadingInt(s, prefix string) (int, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		s = s[len(prefix):]
+	}
+	end := 0
+	if end < len(s) && s[end] == '-' {
+		end++
+	}
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	v, err := strconv.Atoi(s[:end])
+	return v, err == nil
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// parseDoor32 reads door32.sys: comm type, comm handle, baud, BBS name,
+// user record, real name, handle, security, minutes left, emulation, node.
+func parseDoor32(_ string, data []byte) (*DoorSession, error) {
+	f := newTextFile(data)
+	s := &DoorSession{
+		CommType:   f.num(1, "comm type", 0, 2),
+		CommPort:   f.num(2, "comm handle", 0, maxInt),
+		BaudRate:   f.num(3, "baud rate", 0, maxInt),
+		BbsName:    f.str(4, "BBS name"),
+		UserNumber: f.num(5, "user number", 0, maxInt),
+		RealName:   f.str(6, "real name"),
+		UserName:   f.str(7, "handle"),
+		SecLevel:   f.num(8, "security level", 0, maxInt),
+		TimeLeft:   f.num(9, "minutes left", 0, maxInt),
+		Emulation:  f.num(10, "emulation", EmulationASCII, 4),
+		Node:       f.num(11, "node", 0, maxInt),
+	}
+	return s, f.err()
+}
+
+// parseDoorSys reads the 52-line GAP DOOR.SYS. Only the first 20 lines are
+// required; the user number on line 26 and the alias on line 36 are used
+// when present.
+func parseDoorSys(_ string, data []byte) (*DoorSession, error) {
+	f := newTextFile(data)
+	s := &DoorSession{
+		CommPort:  f.numPrefix(1, "COM port", "COM", 0, 255),
+		BaudRate:  f.num(2, "baud rate", 0, maxInt),
+		Node:      f.num(4, "node", 0, maxInt),
+		RealName:  f.str(10, "user name"),
+		SecLevel:  f.num(15, "security level", 0, maxInt),
+		TimeLeft:  f.num(19, "minutes left", 0, maxInt),
+		Emulation: EmulationANSI,
+	}
+	s.UserName = s.RealName
+	switch g := strings.ToUpper(f.str(20, "graphics mode")); g {
+	case "GR", "RIP":
+	case "NG", "7E":
+		s.Emulation = EmulationASCII
+	default:
+		if f.has(20) {
+			f.fail(20, "graphics mode", g, "is not GR, NG, RIP or 7E")
+		}
+	}
+	if f.has(26) {
+		s.UserNumber = f.num(26, "user number", 0, maxInt)
+	}
+	if f.has(36) && f.lines[35] != "" {
+		s.UserName = f.lines[35]
+	}
+	if s.CommPort > 0 {
+		s.CommType = 1
+	}
+	return s, f.err()
+}
+
+// parseDorinfo reads DORINFOn.DEF (RBBS/QuickBBS). The node is the digit in
+// the file name; DORINFO1.DEF is node 1. The format has no user number.
+func parseDorinfo(name string, data []byte) (*DoorSession, error) {
+	f := newTextFile(data)
+	user := strings.TrimSpace(f.str(7, "first name") + " " + f.str(8, "last name"))
+	s := &DoorSession{
+		BbsName:   f.str(1, "BBS name"),
+		CommPort:  f.numPrefix(4, "COM port", "COM", 0, 255),
+		BaudRate:  f.numPrefix(5, "baud rate", "", 0, maxInt),
+		UserName:  user,
+		RealName:  user,
+		Emulation: EmulationASCII,
+		SecLevel:  f.num(11, "security level", 0, maxInt),
+		TimeLeft:  f.num(12, "minutes left", 0, maxInt),
+		Node:      1,
+	}
+	if f.num(10, "graphics", 0, 3) > 0 {
+		s.Emulation = EmulationANSI
+	}
+	if c := name[len("dorinfo")]; c >= '0' && c <= '9' {
+		s.Node = int(c - '0')
+	}
+	if s.CommPort > 0 {
+		s.CommType = 1
+	}
+	return s, f.err()
+}
+
+// parseChain reads WWIV chain.txt. It does not record the node.
+func parseChain(_ string, data []byte) (*DoorSession, error) {
+	f := newTextFile(data)
+	s := &DoorSession{
+		UserNumber: f.num(1, "user number", 0, maxInt),
+		UserName:   f.str(2, "alias"),
+		RealName:   f.str(3, "real name"),
+		SecLevel:   f.num(11, "security level", 0, maxInt),
+		TimeLeft:   f.num(16, "seconds left", 0, maxInt) / 60,
+		Emulation:  EmulationASCII,
+		BaudRate:   f.num(20, "baud rate", 0, maxInt),
+		CommPort:   f.num(21, "COM port", 0, 255),
+		BbsName:    f.str(22, "BBS name"),
+	}
+	if f.num(14, "ANSI", 0, 1) == 1 {
+		s.Emulation = EmulationANSI
+	}
+	if f.num(15, "remote", 0, 1) == 1 {
+		s.CommType = 1
+	}
+	return s, f.err()
+}
+
+// pcboardSysSize is the length of the PCBoard 14.x pcboard.sys record.
+const pcboardSysSize = 128
+
+// parsePCBoard reads the fixed-layout binary pcboard.sys. It carries no BBS
+// name or security level.
+func parsePCBoard(_ string, data []byte) (*DoorSession, error) {
+	if len(data) < pcboardSysSize {
+		return nil, &MalformedError{Errs: []error{&FieldError{
+			Offset: len(data), Field: "record", Problem: "is truncated, expected " + strconv.Itoa(pcboardSysSize) + " bytes",
+		}}}
+	}
+	var errs []error
+	field := func(off, n int) string { return strings.TrimSpace(string(data[off : off+n])) }
+	word := func(off int) int { return int(int16(binary.LittleEndian.Uint16(data[off:]))) }
+
+	s := &DoorSession{
+		UserNumber: word(23),
+		RealName:   field(84, 25),
+		TimeLeft:   word(109),
+		Node:       int(data[111]),
+		CommPort:   int(data[125]),
+		Emulation:  EmulationASCII,
+	}
+	s.UserName = s.RealName
+
+	switch connect := field(18, 5); {
+	case strings.EqualFold(connect, "Local"):
+	default:
+		v, err := strconv.Atoi(connect)
+		if err != nil {
+			errs = append(errs, &FieldError{Offset: 18, Field: "connect speed", Value: connect, Problem: "is not a number or Local"})
+		}
+		s.BaudRate = v
+	}
+	switch data[11] {
+	case 'Y':
+		s.Emulation = EmulationANSI
+	case 'N', '7':
+	default:
+		errs = append(errs, &FieldError{Offset: 11, Field: "graphics mode", Value: string(data[11:12]), Problem: "is not Y, N or 7"})
+	}
+	if s.UserNumber < 0 {
+		errs = append(errs, &FieldError{Offset: 23, Field: "user number", Value: strconv.Itoa(s.UserNumber), Problem: "is negative"})
+	}
+	if s.Node == 0 {
+		errs = append(errs, &FieldError{Offset: 111, Field: "node", Value: "0", Problem: "is not a node number"})
+	}
+	if s.CommPort > 0 {
+		s.CommType = 1
+	}
+	if len(errs) > 0 {
+		return s, &MalformedError{Errs: errs}
+	}
+	return s, nil
+}
@@ -0,0 +1,47 @@
+package era
+
+import "math"
+
+// Era is a broad historical period used both to balance event selection and to
+// label events on screen.
+type Era struct {
+	Name  string
+	Badge string // short tag shown next to the year
+	Min   int    // first year (inclusive)
+	Max   int    // last year (inclusive)
+}
+
+// All lists the eras in chronological order. BC years arrive from the feed as
+// negative numbers and belong in the Ancient era.
+var All = []Era{
+	{Name: "Ancient", Badge: "ANC", Min: math.MinInt, Max: 500},
+	{Name: "Medieval", Badge: "MED", Min: 501, Max: 1500},
+	{Name: "Early Modern", Badge: "EMD", Min: 1501, Max: 1800},
+	{Name: "Modern", Badge: "MOD", Min: 1801, Max: 1950},
+	{Name: "Contemporary", Badge: "CON", Min: 1951, Max: math.MaxInt},
+}
+
+// Contains reports whether year falls within the era.
+func (e Era) Contains(year int) bool {
+	return year >= e.Min && year <= e.Max
+}
+
+// Of returns the era a year belongs to.
+func Of(year int) Era {
+	for _, e := range All {
+		if e.Contains(year) {
+			return e
+		}
+	}
+	// All covers every int, but keep a sane answer for the compiler
+	return All[len(All)-1]
+}
+
+// Badges lists the eras' badges in chronological order.
+func Badges() []string {
+	badges := make([]string, len(All))
+	for i, e := range All {
+		badges[i] = e.Badge
+	}
+	return badges
+}
@@ -0,0 +1,121 @@
+// Package filelock guards the small files that doors on every node read and
+// rewrite, such as the ratings and the seen log, and replaces them whole.
+//
+// A lock is a file created exclusively next to the one it guards. A door
+// that dies while holding one leaves it behind, so a lock older than any
+// save could take is stale and taken over.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockWait is how long Lock waits for another door to let go.
+	lockWait = 2 * time.Second
+	// staleLock is how old a lock taken with Lock must be before it is
+	// taken to be left by a door that died while saving; a save takes
+	// milliseconds.
+	staleLock = 10 * time.Second
+	// pollEvery is how often a waiting Lock tries again.
+	pollEvery = 20 * time.Millisecond
+)
+
+// takeoverExt names the file held, next to a lock, by a door clearing it as
+// stale.
+const takeoverExt = ".takeover"
+
+// Lock creates path exclusively, waiting up to two seconds for a holder to
+// let go and taking over a lock older than ten seconds. The returned func
+// removes it.
+func Lock(path string) (unlock func(), err error) {
+	deadline := time.Now().Add(lockWait)
+	for {
+		unlock, err := TryLock(path, staleLock)
+		if err == nil || !errors.Is(err, fs.ErrExist) {
+			return unlock, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is held by another door", path)
+		}
+		time.Sleep(pollEvery)
+	}
+}
+
+// TryLock creates path exclusively without waiting, taking over a lock older
+// than stale. A lock that is held reports an error matching fs.ErrExist. The
+// returned func removes it.
+func TryLock(path string, stale time.Duration) (unlock func(), err error) {
+	for tries := 0; ; tries++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) || tries > 0 {
+			return nil, err
+		}
+		fi, serr := os.Stat(path)
+		if serr == nil && time.Since(fi.ModTime()) <= stale {
+			return nil, err
+		}
+		takeOver(path, stale)
+	}
+}
+
+// takeOver clears the lock at path if it is older than stale. Two doors can
+// find the same stale lock at once, and by the time the slower one acts the
+// faster may already hold a new lock in its place, so clearing is done by
+// one door at a time, holding a takeover file next to the lock, and only
+// after finding the lock still stale.
+func takeOver(path string, stale time.Duration) {
+	guard := path + takeoverExt
+	g, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		// A takeover file outlives its door only if the door died in the
+		// middle of clearing a lock
+		if fi, err := os.Stat(guard); err == nil && time.Since(fi.ModTime()) > stale {
+			os.Remove(guard)
+		}
+		return
+	}
+	g.Close()
+	defer os.Remove(guard)
+	if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > stale {
+		os.Remove(path)
+	}
+}
+
+// WriteAtomic replaces path with data through a temporary file in the same
+// directory, so a reader never sees half of it and a crash never leaves
+// half of it.
+func WriteAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package filelock
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// age backdates path by d.
+func age(t *testing.T, path string, d time.Duration) {
+	t.Helper()
+	then := time.Now().Add(-d)
+	if err := os.Chtimes(path, then, then); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		lock   time.Duration // age of a lock already there, or -1 for none
+		guard  time.Duration // age of a takeover file already there, or -1
+		stale  time.Duration
+		wantOK bool
+	}{
+		{"free", -1, -1, time.Minute, true},
+		{"held", 0, -1, time.Minute, false},
+		{"held a while", 30 * time.Second, -1, time.Minute, false},
+		{"stale", 2 * time.Minute, -1, time.Minute, true},
+		{"stale while another door takes it over", 2 * time.Minute, 0, time.Minute, false},
+		{"stale with a takeover left behind", 2 * time.Minute, 2 * time.Minute, time.Minute, false},
+	} {
+		path := filepath.Join(t.TempDir(), "test.lock")
+		if tc.lock >= 0 {
+			if err := os.WriteFile(path, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			age(t, path, tc.lock)
+		}
+		if tc.guard >= 0 {
+			if err := os.WriteFile(path+takeoverExt, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			age(t, path+takeoverExt, tc.guard)
+		}
+		unlock, err := TryLock(path, tc.stale)
+		if ok := err == nil; ok != tc.wantOK {
+			t.Errorf("%s: TryLock: %v, want ok %v", tc.name, err, tc.wantOK)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, fs.ErrExist) {
+				t.Errorf("%s: TryLock: %v, want an error matching fs.ErrExist", tc.name, err)
+			}
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: lock not there while held: %v", tc.name, err)
+		}
+		unlock()
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: lock still there after unlock: %v", tc.name, err)
+		}
+	}
+}
+
+func TestTryLockClearsTakeoverLeftBehind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	for _, p := range []string{path, path + takeoverExt} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		age(t, p, 2*time.Minute)
+	}
+	// The first try clears the takeover file a dead door left, the next
+	// the lock itself
+	if _, err := TryLock(path, time.Minute); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("first TryLock: %v, want the lock still held", err)
+	}
+	unlock, err := TryLock(path, time.Minute)
+	if err != nil {
+		t.Fatalf("second TryLock: %v", err)
+	}
+	unlock()
+}
+
+func TestLockTakesTurns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "count")
+	const doors = 20
+	var wg sync.WaitGroup
+	for range doors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := Lock(path + ".lock")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+			data, _ := os.ReadFile(path)
+			n, _ := strconv.Atoi(string(data))
+			if err := WriteAtomic(path, []byte(strconv.Itoa(n+1))); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != strconv.Itoa(doors) {
+		t.Errorf("count is %s after %d doors each added one", data, doors)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("left in the directory: %v, want only count", names)
+	}
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.json")
+	for _, data := range []string{"first", "second, longer", ""} {
+		if err := WriteAtomic(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("after writing %q, read %q, %v", data, got, err)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files in the directory, want only file.json", len(entries))
+	}
+
+	if err := WriteAtomic(filepath.Join(dir, "missing", "file.json"), []byte("x")); err == nil {
+		t.Error("WriteAtomic into a missing directory succeeded")
+	}
+}
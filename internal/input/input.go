@@ -0,0 +1,411 @@
+// Package input decodes caller keystrokes, including the ESC sequences remote
+// terminals send for cursor and function keys.
+package input
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key identifies a decoded keystroke. KeyRune means an ordinary character,
+// found in Event.Rune.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	// KeyResize isn't a keystroke: the caller's window changed size, to
+	// Event.Cols by Event.Rows.
+	KeyResize
+)
+
+var keyNames = map[Key]string{
+	KeyEnter: "Enter", KeyEsc: "Esc", KeyBackspace: "Backspace", KeyTab: "Tab",
+	KeyUp: "Up", KeyDown: "Down", KeyLeft: "Left", KeyRight: "Right",
+	KeyHome: "Home", KeyEnd: "End", KeyPgUp: "PgUp", KeyPgDn: "PgDn",
+	KeyInsert: "Insert", KeyDelete: "Delete", KeyResize: "Resize",
+}
+
+func (k Key) String() string {
+	if k >= KeyF1 && k <= KeyF12 {
+		return "F" + strconv.Itoa(int(k-KeyF1)+1)
+	}
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return "Rune"
+}
+
+// Event is one decoded keystroke.
+type Event struct {
+	Key  Key
+	Rune rune // set for KeyRune
+	Cols int  // set for KeyResize
+	Rows int  // set for KeyResize
+}
+
+// String names the key the way ParseKey accepts it: the character itself,
+// "Space", or a key name such as "Esc" or "F1".
+func (e Event) String() string {
+	switch {
+	case e.Key != KeyRune:
+		return e.Key.String()
+	case e.Rune == ' ':
+		return "Space"
+	}
+	return string(e.Rune)
+}
+
+// ParseKey reads a key name as written in the config file: a single
+// character (case matters), "space", or a key name such as "esc", "pgdn" or
+// "f1" (case doesn't).
+func ParseKey(name string) (Event, error) {
+	if r := []rune(name); len(r) == 1 {
+		return Event{Key: KeyRune, Rune: r[0]}, nil
+	}
+	if strings.EqualFold(name, "space") {
+		return Event{Key: KeyRune, Rune: ' '}, nil
+	}
+	for k := KeyEnter; k <= KeyF12; k++ {
+		if strings.EqualFold(name, k.String()) {
+			return Event{Key: k}, nil
+		}
+	}
+	return Event{}, fmt.Errorf("unknown key %q", name)
+}
+
+// DefaultEscTimeout is how long a lone ESC waits for the rest of a sequence
+// before it counts as the Esc key. Long enough for a laggy telnet link, short
+// enough that Esc still feels immediate.
+const DefaultEscTimeout = 150 * time.Millisecond
+
+// Decoder turns a stream of runes into key events.
+type Decoder struct {
+	runes      chan rune
+	resized    chan Event    // the latest window size not yet read
+	done       chan struct{} // closed when the input ends
+	err        error
+	escTimeout time.Duration
+	pending    []rune
+	unread     []Event // put back with Unread, read first
+	lastCR     bool
+}
+
+// NewDecoder starts reading runes with readRune, such as one wrapping the
+// controlling terminal or the caller's socket on stdin (see RuneFunc). The
+// reader goroutine runs until readRune returns an error.
+func NewDecoder(readRune func() (rune, error), escTimeout time.Duration) *Decoder {
+	d := &Decoder{runes: make(chan rune, 64), resized: make(chan Event, 1), done: make(chan struct{}), escTimeout: escTimeout}
+	go d.read(readRune)
+	return d
+}
+
+// NewTelnetDecoder reads keys from r, a telnet connection such as the
+// caller's socket on stdin, with telnet commands taken out. A window size
+// the caller's client reports (NAWS, RFC 1073) comes out of ReadKey as a
+// KeyResize event.
+func NewTelnetDecoder(r io.Reader, escTimeout time.Duration) *Decoder {
+	d := &Decoder{runes: make(chan rune, 64), resized: make(chan Event, 1), done: make(chan struct{}), escTimeout: escTimeout}
+	t := &telnetReader{r: bufio.NewReader(r), resized: d.resize}
+	go d.read(t.readRune)
+	return d
+}
+
+// read feeds runes from readRune to the decoder until it returns an error.
+func (d *Decoder) read(readRune func() (rune, error)) {
+	for {
+		c, err := readRune()
+		if err != nil {
+			d.err = err
+			close(d.done)
+			close(d.runes)
+			return
+		}
+		d.runes <- c
+	}
+}
+
+// Done returns a channel closed once the input has ended, the caller having
+// hung up or the line dropped, even while no one is reading keys. Keys read
+// before then may still be waiting for ReadKey.
+func (d *Decoder) Done() <-chan struct{} {
+	return d.done
+}
+
+// resize queues a KeyResize event for ReadKey, replacing one not yet read,
+// since only the latest size matters.
+func (d *Decoder) resize(cols, rows int) {
+	ev := Event{Key: KeyResize, Cols: cols, Rows: rows}
+	for {
+		select {
+		case d.resized <- ev:
+			return
+		default:
+			select {
+			case <-d.resized:
+			default:
+			}
+		}
+	}
+}
+
+// RuneFunc adapts an io.RuneReader for NewDecoder.
+func RuneFunc(r io.RuneReader) func() (rune, error) {
+	return func() (rune, error) {
+		c, _, err := r.ReadRune()
+		return c, err
+	}
+}
+
+// next returns the next rune, waiting at most timeout when timeout > 0.
+// ok is false on timeout or end of input.
+func (d *Decoder) next(timeout time.Duration) (rune, bool) {
+	if len(d.pending) > 0 {
+		c := d.pending[0]
+		d.pending = d.pending[1:]
+		return c, true
+	}
+	if timeout <= 0 {
+		c, ok := <-d.runes
+		return c, ok
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case c, ok := <-d.runes:
+		return c, ok
+	case <-t.C:
+		return 0, false
+	}
+}
+
+// ReadKey blocks until a whole keystroke has arrived, or the caller's
+// window changes size between keystrokes.
+func (d *Decoder) ReadKey() (Event, error) {
+	return d.ReadKeyContext(context.Background())
+}
+
+// ReadKeyContext is ReadKey that gives up, returning ctx's error, if ctx
+// ends before a keystroke begins. One that has begun is read to its end.
+func (d *Decoder) ReadKeyContext(ctx context.Context) (Event, error) {
+	if len(d.unread) > 0 {
+		ev := d.unread[0]
+		d.unread = d.unread[1:]
+		return ev, nil
+	}
+	for {
+		var c rune
+		ok := true
+		if len(d.pending) > 0 {
+			c, ok = d.next(0)
+		} else {
+			select {
+			case ev := <-d.resized:
+				return ev, nil
+			case c, ok = <-d.runes:
+			case <-ctx.Done():
+				return Event{}, ctx.Err()
+			}
+		}
+		if !ok {
+			return Event{}, d.readErr()
+		}
+		// Telnet sends CR LF or CR NUL for Enter; swallow the second byte
+		wasCR := d.lastCR
+		d.lastCR = c == '\r'
+		if wasCR && (c == '\n' || c == 0) {
+			continue
+		}
+		switch c {
+		case '\r', '\n':
+			return Event{Key: KeyEnter}, nil
+		case '\t':
+			return Event{Key: KeyTab}, nil
+		case 0x08, 0x7f:
+			return Event{Key: KeyBackspace}, nil
+		case 0x1b:
+			if ev, ok := d.escape(); ok {
+				return ev, nil
+			}
+			// A sequence we don't use; drop it rather than leak it as typing
+			continue
+		}
+		return Event{Key: KeyRune, Rune: c}, nil
+	}
+}
+
+// Unread puts ev back for the next ReadKey to return, ahead of the keys
+// typed since. A key read while waiting on something else, such as a
+// fetch, is then acted on once the screens are ready for it.
+func (d *Decoder) Unread(ev Event) {
+	d.unread = append(d.unread, ev)
+}
+
+// Discard drops the keys typed ahead and not yet read, and any that arrive
+// within wait, so they aren't taken as answers to a screen the caller hasn't
+// seen yet, or left for the BBS to read once the door exits.
+func (d *Decoder) Discard(wait time.Duration) {
+	d.unread, d.pending = nil, nil
+	drop := func(c rune, ok bool) bool {
+		d.lastCR = c == '\r'
+		return ok
+	}
+	deadline := time.After(wait)
+	for {
+		select {
+		case c, ok := <-d.runes:
+			if !drop(c, ok) {
+				return
+			}
+			continue
+		default:
+		}
+		if wait <= 0 {
+			return
+		}
+		select {
+		case c, ok := <-d.runes:
+			if !drop(c, ok) {
+				return
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+func (d *Decoder) readErr() error {
+	if d.err == nil {
+		return io.EOF
+	}
+	return d.err
+}
+
+// escape decodes what follows an ESC. An ESC not followed by a sequence in
+// time is returned as a plain Esc, with any runes after it kept for the next
+// ReadKey. A whole sequence that names no key is read and reported with ok
+// false.
+func (d *Decoder) escape() (Event, bool) {
+	intro, ok := d.next(d.escTimeout)
+	if !ok {
+		return Event{Key: KeyEsc}, true
+	}
+	switch intro {
+	case 'O':
+		c, ok := d.next(d.escTimeout)
+		if !ok {
+			d.pending = append(d.pending, intro)
+			return Event{Key: KeyEsc}, true
+		}
+		if k, found := ss3Keys[c]; found {
+			return Event{Key: k}, true
+		}
+		d.pending = append(d.pending, intro, c)
+		return Event{Key: KeyEsc}, true
+	case '[':
+		var seq []rune
+		for len(seq) < 16 {
+			c, ok := d.next(d.escTimeout)
+			if !ok {
+				break
+			}
+			seq = append(seq, c)
+			// Parameters and intermediates are 0x20-0x3f; a final byte ends
+			// it, except the extra '[' of the Linux console's F1-F5
+			if c >= 0x40 && c <= 0x7e && !(c == '[' && len(seq) == 1) {
+				k, found := csiKey(string(seq))
+				return Event{Key: k}, found
+			}
+		}
+		d.pending = append(d.pending, append([]rune{intro}, seq...)...)
+		return Event{Key: KeyEsc}, true
+	}
+	d.pending = append(d.pending, intro)
+	return Event{Key: KeyEsc}, true
+}
+
+// ss3Keys are keys sent as ESC O x (application cursor mode and VT100 F1-F4).
+var ss3Keys = map[rune]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// csiFinal are keys identified by the final byte of ESC [ ... x. SyncTERM and
+// other ANSI-BBS terminals send End as ESC [ K and PgUp/PgDn as ESC [ V/U.
+var csiFinal = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'K': KeyEnd,
+	'V': KeyPgUp, 'U': KeyPgDn, '@': KeyInsert,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// csiTilde are keys sent as ESC [ n ~ (VT220, xterm, rxvt, the Linux console).
+var csiTilde = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPgUp, 6: KeyPgDn,
+	7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10, 23: KeyF11, 24: KeyF12,
+}
+
+// csiKey maps the body of a CSI sequence (after "ESC [") to a key. Modifier
+// parameters such as the ";5" in ESC [ 1 ; 5 A are ignored; a letter-final
+// sequence with other parameters, such as the row and column of a cursor
+// position report, ESC [ r ; c R, is no key.
+func csiKey(seq string) (Key, bool) {
+	final := seq[len(seq)-1]
+	params := seq[:len(seq)-1]
+	if final == '~' {
+		first, _, _ := strings.Cut(params, ";")
+		n, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, false
+		}
+		k, ok := csiTilde[n]
+		return k, ok
+	}
+	// The Linux console sends F1-F5 as ESC [ [ A-E
+	if strings.HasPrefix(params, "[") && final >= 'A' && final <= 'E' {
+		return KeyF1 + Key(final-'A'), true
+	}
+	if first, mod, _ := strings.Cut(params, ";"); params != "" && (first != "1" || !digits(mod)) {
+		return 0, false
+	}
+	k, ok := csiFinal[final]
+	return k, ok
+}
+
+// digits reports whether s is a run of one or more decimal digits.
+func digits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
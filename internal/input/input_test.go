@@ -0,0 +1,129 @@
+package input
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// keys decodes every keystroke in d up to the end of its input.
+func keys(t *testing.T, d *Decoder) []Event {
+	t.Helper()
+	var evs []Event
+	for {
+		ev, err := d.ReadKey()
+		if errors.Is(err, io.EOF) {
+			return evs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs = append(evs, ev)
+	}
+}
+
+func runes(s string) []Event {
+	var evs []Event
+	for _, c := range s {
+		evs = append(evs, Event{Key: KeyRune, Rune: c})
+	}
+	return evs
+}
+
+func TestReadKey(t *testing.T) {
+	for _, tc := range []struct {
+		name, in string
+		want     []Event
+	}{
+		{"typing", "ab1", runes("ab1")},
+		{"enter", "\r\n\r\x00\n", []Event{{Key: KeyEnter}, {Key: KeyEnter}, {Key: KeyEnter}}},
+		{"tab and backspace", "\t\x08\x7f", []Event{{Key: KeyTab}, {Key: KeyBackspace}, {Key: KeyBackspace}}},
+		{"csi cursor", "\x1b[A\x1b[B\x1b[C\x1b[D", []Event{{Key: KeyUp}, {Key: KeyDown}, {Key: KeyRight}, {Key: KeyLeft}}},
+		{"csi modifier", "\x1b[1;5A", []Event{{Key: KeyUp}}},
+		{"csi tilde", "\x1b[5~\x1b[6~\x1b[3~\x1b[15~\x1b[24~", []Event{{Key: KeyPgUp}, {Key: KeyPgDn}, {Key: KeyDelete}, {Key: KeyF5}, {Key: KeyF12}}},
+		{"csi tilde modifier", "\x1b[3;2~", []Event{{Key: KeyDelete}}},
+		{"ansi-bbs", "\x1b[K\x1b[V\x1b[U\x1b[H", []Event{{Key: KeyEnd}, {Key: KeyPgUp}, {Key: KeyPgDn}, {Key: KeyHome}}},
+		{"linux console f keys", "\x1b[[A\x1b[[E", []Event{{Key: KeyF1}, {Key: KeyF5}}},
+		{"ss3", "\x1bOA\x1bOH\x1bOP\x1bOS", []Event{{Key: KeyUp}, {Key: KeyHome}, {Key: KeyF1}, {Key: KeyF4}}},
+		{"lone esc", "\x1b", []Event{{Key: KeyEsc}}},
+		{"esc then key", "\x1bq", []Event{{Key: KeyEsc}, {Key: KeyRune, Rune: 'q'}}},
+		{"esc o then key", "\x1bOz", append([]Event{{Key: KeyEsc}}, runes("Oz")...)},
+		{"unknown csi dropped", "\x1b[Zq", runes("q")},
+		{"unknown tilde dropped", "\x1b[99~q", runes("q")},
+		{"cursor position report dropped", "\x1b[12;40Rq", runes("q")},
+		{"unfinished csi", "\x1b[12", append([]Event{{Key: KeyEsc}}, runes("[12")...)},
+	} {
+		d := NewDecoder(RuneFunc(strings.NewReader(tc.in)), 50*time.Millisecond)
+		if got := keys(t, d); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: %q read as %v, want %v", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestReadKeyTelnet(t *testing.T) {
+	const iac, sb, se, will, do = "\xff", "\xfa", "\xf0", "\xfb", "\xfd"
+	for _, tc := range []struct {
+		name, in string
+		want     []Event
+	}{
+		{"negotiation dropped", iac + will + "\x01a" + iac + do + "\x03b", runes("ab")},
+		{"other command dropped", iac + "\xf1a", runes("a")},
+		{"escaped 255", iac + iac, runes(string(utf8.RuneError))},
+		{"enter", "\r\x00a", []Event{{Key: KeyEnter}, {Key: KeyRune, Rune: 'a'}}},
+		{"utf-8", "é→", runes("é→")},
+		{"other subnegotiation dropped", iac + sb + "\x18\x00ANSI" + iac + se + "a", runes("a")},
+		{"escape sequence", "\x1b[Aa", []Event{{Key: KeyUp}, {Key: KeyRune, Rune: 'a'}}},
+	} {
+		d := NewTelnetDecoder(strings.NewReader(tc.in), 50*time.Millisecond)
+		if got := keys(t, d); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: %q read as %v, want %v", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestReadKeyWindowSize(t *testing.T) {
+	for _, tc := range []struct {
+		name, in   string
+		cols, rows int
+	}{
+		{"naws", "\xff\xfa\x1f\x00\x50\x00\x18\xff\xf0", 80, 24},
+		{"naws with an escaped 255", "\xff\xfa\x1f\x00\xff\xff\x00\x32\xff\xf0", 255, 50},
+	} {
+		r, w := io.Pipe()
+		d := NewTelnetDecoder(r, 50*time.Millisecond)
+		go w.Write([]byte(tc.in))
+		ev, err := d.ReadKey()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if want := (Event{Key: KeyResize, Cols: tc.cols, Rows: tc.rows}); ev != want {
+			t.Errorf("%s: read %+v, want %+v", tc.name, ev, want)
+		}
+		w.Close()
+	}
+}
+
+func TestReadKeyContext(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	d := NewDecoder(RuneFunc(bufio.NewReader(r)), 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.ReadKeyContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadKeyContext with no keys: %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A dropped sequence doesn't end the wait, and the key after it is read
+	go w.Write([]byte("\x1b[Zx"))
+	ev, err := d.ReadKeyContext(context.Background())
+	if err != nil || ev != (Event{Key: KeyRune, Rune: 'x'}) {
+		t.Errorf("ReadKeyContext after an unknown sequence: %v, %v", ev, err)
+	}
+}
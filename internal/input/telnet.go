@@ -0,0 +1,94 @@
+package input
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// Telnet command bytes (RFC 854) and the window size option (RFC 1073).
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+	optionNAWS = 31
+)
+
+// AskWindowSize asks the caller's telnet client to report its window size,
+// and to report it again whenever it changes (IAC DO NAWS).
+var AskWindowSize = []byte{telnetIAC, telnetDO, optionNAWS}
+
+// telnetReader reads runes from a telnet connection, leaving out telnet
+// commands. A window size report is passed to resized.
+type telnetReader struct {
+	r       *bufio.Reader
+	resized func(cols, rows int)
+}
+
+func (t *telnetReader) readRune() (rune, error) {
+	for {
+		b, err := t.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != telnetIAC {
+			t.r.UnreadByte()
+			c, _, err := t.r.ReadRune()
+			return c, err
+		}
+		cmd, err := t.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch cmd {
+		case telnetIAC:
+			// An escaped 255, which is no character in UTF-8
+			return utf8.RuneError, nil
+		case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+			// The option's negotiation was the BBS's business
+			if _, err := t.r.ReadByte(); err != nil {
+				return 0, err
+			}
+		case telnetSB:
+			if err := t.subnegotiation(); err != nil {
+				return 0, err
+			}
+		}
+		// Other commands are a single byte and mean nothing to the door
+	}
+}
+
+// subnegotiation reads the rest of an IAC SB ... IAC SE block, reporting
+// the window size if it is one.
+func (t *telnetReader) subnegotiation() error {
+	var data []byte
+	for {
+		b, err := t.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == telnetIAC {
+			if b, err = t.r.ReadByte(); err != nil {
+				return err
+			}
+			if b == telnetSE {
+				break
+			}
+			// IAC IAC is a 255 in the data
+		}
+		if len(data) < 16 {
+			data = append(data, b)
+		}
+	}
+	// NAWS sends the width and then the height, each as two bytes
+	if len(data) == 5 && data[0] == optionNAWS {
+		cols, rows := int(data[1])<<8|int(data[2]), int(data[3])<<8|int(data[4])
+		if cols > 0 && rows > 0 {
+			t.resized(cols, rows)
+		}
+	}
+	return nil
+}
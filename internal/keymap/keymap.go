@@ -0,0 +1,203 @@
+// Package keymap maps caller keystrokes to door actions, with defaults a
+// sysop can override from the config file.
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/robbiew/history/internal/input"
+)
+
+// Action is something a key can do.
+type Action string
+
+const (
+	// Next moves on to the following screen, exiting after the last. Keys
+	// without a binding do this, so "press any key" keeps working.
+	Next    Action = "next"
+	Quit    Action = "quit"
+	Refresh Action = "refresh"
+	// Save keeps a copy of the screen in the caller's download directory.
+	Save     Action = "save"
+	Help     Action = "help"
+	Events   Action = "events"
+	Births   Action = "births"
+	Deaths   Action = "deaths"
+	Holidays Action = "holidays"
+	Featured Action = "featured"
+	News     Action = "news"
+	// Detail opens an event on its own page: the first key bound opens the
+	// first event on screen, the second key the second, and so on.
+	Detail Action = "detail"
+	// More lists the page's events the caller hasn't seen yet, a batch at a
+	// time, in a list Up and Down scroll through.
+	More Action = "more"
+	Up   Action = "up"
+	Down Action = "down"
+	// Like and Dislike rate the event on a detail page up or down.
+	Like    Action = "like"
+	Dislike Action = "dislike"
+	// Cached shows the saved copy of the day when fetching it failed.
+	Cached Action = "cached"
+	// BirthYear shows what happened on the day in the year the caller was
+	// born, asking them the year the first time.
+	BirthYear Action = "birthyear"
+	// About shows the door's version, where its entries come from and how
+	// it is set up.
+	About Action = "about"
+)
+
+// Actions lists every bindable action, in help screen and command bar order,
+// with its command bar label and the line the help screen shows for it.
+var Actions = []struct {
+	Action Action
+	Label  string
+	Help   string
+}{
+	{Next, "Next", "Next screen"},
+	{Events, "Events", "Events"},
+	{Births, "Births", "Births"},
+	{Deaths, "Deaths", "Deaths"},
+	{Holidays, "Holidays", "Holidays and observances"},
+	{Featured, "Featured", "Today's featured article"},
+	{News, "News", "In the news"},
+	{Detail, "Detail", "A closer look at the event with that number"},
+	{More, "More", "More from this day, five at a time"},
+	{Up, "Up", "Scroll a list of more up"},
+	{Down, "Down", "Scroll a list of more down"},
+	{Like, "Like", "Rate the event on a detail page up"},
+	{Dislike, "Dislike", "Rate the event on a detail page down"},
+	{Cached, "Cached copy", "Show the saved copy when fetching fails"},
+	{BirthYear, "Your year", "This day in the year you were born"},
+	{About, "About", "About this door"},
+	{Refresh, "Refresh", "Pick a fresh set of entries"},
+	{Save, "Save", "Save this screen to your downloads"},
+	{Help, "Help", "This help"},
+	{Quit, "Quit", "Back to the BBS"},
+}
+
+// defaults are the built-in bindings; the config file replaces them per action.
+var defaults = map[Action][]string{
+	Next:      {"n", "N", "space", "enter", "pgdn", "right"},
+	Quit:      {"q", "Q", "esc"},
+	Refresh:   {"r", "R"},
+	Save:      {"s", "S"},
+	Help:      {"?", "h", "H", "f1"},
+	Events:    {"e", "E"},
+	Births:    {"b", "B"},
+	Deaths:    {"d", "D"},
+	Holidays:  {"o", "O"},
+	Featured:  {"f", "F"},
+	News:      {"w", "W"},
+	Detail:    {"1", "2", "3", "4", "5"},
+	More:      {"m", "M"},
+	Up:        {"up", "pgup"},
+	Down:      {"down"},
+	Like:      {"+", "="},
+	Dislike:   {"-", "_"},
+	Cached:    {"c", "C"},
+	BirthYear: {"y", "Y"},
+	About:     {"a", "A"},
+}
+
+// Map is a resolved set of bindings.
+type Map struct {
+	actions map[input.Event]Action
+	keys    map[Action][]input.Event
+}
+
+// New builds the bindings, replacing the defaults for every action named in
+// overrides. Unknown actions or keys, and a key bound to two actions, are
+// errors.
+func New(overrides map[string][]string) (*Map, error) {
+	var errs []error
+	names := make(map[Action][]string, len(defaults))
+	for a, keys := range defaults {
+		names[a] = keys
+	}
+	for name, keys := range overrides {
+		a := Action(name)
+		if _, ok := defaults[a]; !ok {
+			errs = append(errs, fmt.Errorf("unknown key action %q", name))
+			continue
+		}
+		names[a] = keys
+	}
+
+	m := &Map{actions: map[input.Event]Action{}, keys: map[Action][]input.Event{}}
+	for _, entry := range Actions {
+		a := entry.Action
+		for _, name := range names[a] {
+			ev, err := input.ParseKey(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keys %s: %v", a, err))
+				continue
+			}
+			if other, ok := m.actions[ev]; ok && other != a {
+				errs = append(errs, fmt.Errorf("key %s is bound to both %s and %s", ev, other, a))
+				continue
+			}
+			m.actions[ev] = a
+			if !slices.Contains(m.keys[a], ev) {
+				m.keys[a] = append(m.keys[a], ev)
+			}
+		}
+	}
+	if len(m.keys[Quit]) == 0 {
+		errs = append(errs, errors.New("keys quit: at least one key is needed"))
+	}
+	return m, errors.Join(errs...)
+}
+
+// PluginAction is the action that opens the named plugin screen.
+func PluginAction(name string) Action {
+	return Action("plugin:" + name)
+}
+
+// Bind adds keys for an action outside the built-in set, such as a plugin
+// screen. A key already bound to another action is an error.
+func (m *Map) Bind(a Action, keys []string) error {
+	var errs []error
+	for _, name := range keys {
+		ev, err := input.ParseKey(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("keys %s: %v", a, err))
+			continue
+		}
+		if other, ok := m.actions[ev]; ok && other != a {
+			errs = append(errs, fmt.Errorf("key %s is bound to both %s and %s", ev, other, a))
+			continue
+		}
+		m.actions[ev] = a
+		if !slices.Contains(m.keys[a], ev) {
+			m.keys[a] = append(m.keys[a], ev)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns the action bound to ev, or Next for unbound keys.
+func (m *Map) Lookup(ev input.Event) Action {
+	if a, ok := m.actions[ev]; ok {
+		return a
+	}
+	return Next
+}
+
+// Bound reports whether ev is bound to an action, rather than taken as Next
+// for having none.
+func (m *Map) Bound(ev input.Event) bool {
+	_, ok := m.actions[ev]
+	return ok
+}
+
+// Keys returns the names of the keys bound to a, in the order configured.
+func (m *Map) Keys(a Action) []string {
+	out := make([]string, len(m.keys[a]))
+	for i, ev := range m.keys[a] {
+		out[i] = ev.String()
+	}
+	return out
+}
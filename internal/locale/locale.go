@@ -0,0 +1,251 @@
+// Package locale names the days and months in the door's dates in the
+// sysop's language. Each locale is a file of settings: the ones built in
+// are embedded here by language code, and a sysop can write their own.
+//
+// A locale file has one "key = value" setting per line, with blank lines
+// and lines starting with '#' skipped:
+//
+//	months        twelve month names, separated by commas
+//	short_months  twelve abbreviated month names
+//	weekdays      seven day names, starting with Sunday
+//	ordinal       the suffix after every day of the month, such as "." in German
+//	ordinal.N     the suffix after day N, overriding ordinal
+//	day           the layout for a day of the year, as in the screen titles
+//	date          the layout for a whole date
+//	short_date    a shorter date, for when room is tight
+//	full_date     a date with its weekday
+//	short_day     a day of the year, shortened
+//
+// Layouts put {weekday}, {month}, {mon} (the short month), {day}, {ord}
+// (the day's ordinal suffix) and {year} where the language has them. Names
+// and layouts a file leaves out are the English ones; ordinal suffixes
+// are not, so a language without them simply leaves them out.
+package locale
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed locales/*.txt
+var builtin embed.FS
+
+// Locale is a language's names and layouts for dates. A nil *Locale is
+// English.
+type Locale struct {
+	Months      [12]string
+	ShortMonths [12]string
+	Weekdays    [7]string
+	// Ordinals are the suffixes after each day of the month, indexed by
+	// day; empty ones are left out.
+	Ordinals [32]string
+
+	DayLayout       string
+	DateLayout      string
+	ShortDateLayout string
+	FullDateLayout  string
+	ShortDayLayout  string
+}
+
+// English is the door's own locale, used when none is set, and the one
+// other locales fall back on.
+var English = func() *Locale {
+	f, err := builtin.Open("locales/en.txt")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	l, err := parse(f, Locale{})
+	if err != nil {
+		panic(err)
+	}
+	return l
+}()
+
+// Names returns the codes of the built-in locales.
+func Names() []string {
+	entries, _ := builtin.ReadDir("locales")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".txt"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Load returns the locale name stands for: a built-in one by its code,
+// such as "de", or a locale file by its path, such as "locales/eo.txt".
+// An empty name is English.
+func Load(name string) (*Locale, error) {
+	if name == "" {
+		return English, nil
+	}
+	var r io.ReadCloser
+	var err error
+	if strings.ContainsAny(name, `/\.`) {
+		r, err = os.Open(name)
+	} else if r, err = builtin.Open(path.Join("locales", name+".txt")); errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("unknown locale %q, expected a locale file or one of %v", name, Names())
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	l, err := Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("locale %s: %w", name, err)
+	}
+	return l, nil
+}
+
+// Code returns the language code of the locale name stands for, as Load
+// takes it: a built-in locale's own code, or a locale file's name without
+// its directory and extension, so "locales/eo.txt" is "eo". An empty name
+// is "en".
+func Code(name string) string {
+	if name == "" {
+		return "en"
+	}
+	base := filepath.Base(name)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Parse reads a locale file from r.
+func Parse(r io.Reader) (*Locale, error) {
+	base := *English
+	base.Ordinals = [32]string{}
+	return parse(r, base)
+}
+
+// parse reads a locale file from r on top of base.
+func parse(r io.Reader, base Locale) (*Locale, error) {
+	l := &base
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if err := l.set(key, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return l, scanner.Err()
+}
+
+// set applies one setting from a locale file.
+func (l *Locale) set(key, value string) error {
+	switch key {
+	case "months":
+		return names(l.Months[:], key, value)
+	case "short_months":
+		return names(l.ShortMonths[:], key, value)
+	case "weekdays":
+		return names(l.Weekdays[:], key, value)
+	case "ordinal":
+		for day := 1; day < len(l.Ordinals); day++ {
+			l.Ordinals[day] = value
+		}
+	case "day":
+		l.DayLayout = value
+	case "date":
+		l.DateLayout = value
+	case "short_date":
+		l.ShortDateLayout = value
+	case "full_date":
+		l.FullDateLayout = value
+	case "short_day":
+		l.ShortDayLayout = value
+	default:
+		d, ok := strings.CutPrefix(key, "ordinal.")
+		day, err := strconv.Atoi(d)
+		if !ok || err != nil || day < 1 || day > 31 {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		l.Ordinals[day] = value
+	}
+	return nil
+}
+
+// names fills dst from a comma-separated list of exactly len(dst) names.
+func names(dst []string, key, value string) error {
+	list := strings.Split(value, ",")
+	if len(list) != len(dst) {
+		return fmt.Errorf("%s needs %d names, got %d", key, len(dst), len(list))
+	}
+	for i, name := range list {
+		dst[i] = strings.TrimSpace(name)
+	}
+	return nil
+}
+
+// Map returns a copy of l with every name and suffix passed through f,
+// such as to put them in the caller's character set.
+func (l *Locale) Map(f func(string) string) *Locale {
+	m := *l.get()
+	for _, list := range [][]string{m.Months[:], m.ShortMonths[:], m.Weekdays[:], m.Ordinals[:]} {
+		for i, s := range list {
+			list[i] = f(s)
+		}
+	}
+	return &m
+}
+
+func (l *Locale) get() *Locale {
+	if l == nil {
+		return English
+	}
+	return l
+}
+
+// Ordinal returns the suffix written after day, which may be empty.
+func (l *Locale) Ordinal(day int) string {
+	if day < 1 || day > 31 {
+		return ""
+	}
+	return l.get().Ordinals[day]
+}
+
+// Day writes t's day of the year, as in "October 16th".
+func (l *Locale) Day(t time.Time) string { return l.format(l.get().DayLayout, t) }
+
+// Date writes t's date, as in "October 16, 2026".
+func (l *Locale) Date(t time.Time) string { return l.format(l.get().DateLayout, t) }
+
+// ShortDate writes t's date shortened, as in "Oct 16, 2026".
+func (l *Locale) ShortDate(t time.Time) string { return l.format(l.get().ShortDateLayout, t) }
+
+// FullDate writes t's date and weekday, as in "Friday, October 16, 2026".
+func (l *Locale) FullDate(t time.Time) string { return l.format(l.get().FullDateLayout, t) }
+
+// ShortDay writes t's day of the year shortened, as in "Oct 16".
+func (l *Locale) ShortDay(t time.Time) string { return l.format(l.get().ShortDayLayout, t) }
+
+// format fills in layout for t.
+func (l *Locale) format(layout string, t time.Time) string {
+	g := l.get()
+	return strings.NewReplacer(
+		"{weekday}", g.Weekdays[t.Weekday()],
+		"{month}", g.Months[t.Month()-1],
+		"{mon}", g.ShortMonths[t.Month()-1],
+		"{day}", strconv.Itoa(t.Day()),
+		"{ord}", g.Ordinal(t.Day()),
+		"{year}", strconv.Itoa(t.Year()),
+	).Replace(layout)
+}
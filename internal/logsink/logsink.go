@@ -0,0 +1,133 @@
+// Package logsink sends the door's structured logs to syslog or the systemd
+// journal, for boards run under a service manager instead of writing a log
+// file of their own.
+//
+// A record's attributes are kept: syslog gets them after the message as
+// key=value pairs, and the journal gets them that way in MESSAGE and also
+// as fields of their own, upper-cased, so they can be matched on, as in
+// journalctl SYSLOG_IDENTIFIER=history STATUS=503.
+package logsink
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Open returns a handler for output, "syslog" or "journald", tagging
+// entries with tag.
+func Open(output, tag string) (*Handler, error) {
+	switch output {
+	case "syslog":
+		return Syslog(tag)
+	case "journald":
+		return Journal(tag)
+	}
+	return nil, fmt.Errorf("unknown log output %q", output)
+}
+
+// field is one attribute of a record, flattened: groups are joined into
+// its key with dots.
+type field struct {
+	key, value string
+}
+
+// sink is where a Handler's records go.
+type sink interface {
+	send(level slog.Level, msg string, fields []field) error
+	close() error
+}
+
+// Handler is a slog.Handler that writes to syslog or the journal. Records
+// below slog.LevelInfo are dropped, as with slog's own handlers.
+type Handler struct {
+	out    sink
+	mu     *sync.Mutex // shared by the handlers made with WithAttrs and WithGroup
+	fields []field
+	group  string // the prefix of keys added from here on
+}
+
+func newHandler(out sink) *Handler {
+	return &Handler{out: out, mu: new(sync.Mutex)}
+}
+
+// Enabled reports whether a record at level is written.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+// Handle writes r.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	fields := append([]field(nil), h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = flatten(fields, h.group, a)
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.out.send(r.Level, r.Message, fields)
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.fields = append([]field(nil), h.fields...)
+	for _, a := range attrs {
+		h2.fields = flatten(h2.fields, h.group, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that puts the attributes added from here on
+// in group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// Close disconnects from the log. Handlers made from h with WithAttrs or
+// WithGroup stop working too.
+func (h *Handler) Close() error {
+	return h.out.close()
+}
+
+// flatten appends a to fields, under prefix, spreading a group into one
+// field for each of its attributes.
+func flatten(fields []field, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, sub := range a.Value.Group() {
+			fields = flatten(fields, prefix, sub)
+		}
+		return fields
+	}
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	return append(fields, field{prefix + a.Key, a.Value.String()})
+}
+
+// text writes msg followed by fields as key=value pairs, quoting values that
+// would otherwise run into the next.
+func text(msg string, fields []field) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, f := range fields {
+		v := f.value
+		if v == "" || strings.ContainsAny(v, " =\"\n\t") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(" " + f.key + "=" + v)
+	}
+	return b.String()
+}
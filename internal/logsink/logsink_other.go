@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package logsink
+
+import "errors"
+
+// Syslog fails on systems without a syslog daemon.
+func Syslog(tag string) (*Handler, error) {
+	return nil, errors.New("syslog is not supported on this system")
+}
+
+// Journal fails on systems without a systemd journal.
+func Journal(tag string) (*Handler, error) {
+	return nil, errors.New("the systemd journal is not supported on this system")
+}
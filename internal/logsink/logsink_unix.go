@@ -0,0 +1,108 @@
+//go:build !windows && !plan9
+
+package logsink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"log/syslog"
+	"net"
+	"strings"
+)
+
+// Syslog returns a handler logging to the local syslog daemon as tag, with
+// the user facility.
+func Syslog(tag string) (*Handler, error) {
+	w, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return newHandler(syslogSink{w}), nil
+}
+
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func (s syslogSink) send(level slog.Level, msg string, fields []field) error {
+	line := text(msg, fields)
+	switch {
+	case level >= slog.LevelError:
+		return s.w.Err(line)
+	case level >= slog.LevelWarn:
+		return s.w.Warning(line)
+	}
+	return s.w.Info(line)
+}
+
+func (s syslogSink) close() error { return s.w.Close() }
+
+// journalSocket is where journald takes entries in its native protocol.
+const journalSocket = "/run/systemd/journal/socket"
+
+// Journal returns a handler logging to the systemd journal as tag. It fails
+// where there is no journal to write to.
+func Journal(tag string) (*Handler, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return newHandler(journalSink{conn: conn, tag: tag}), nil
+}
+
+type journalSink struct {
+	conn *net.UnixConn
+	tag  string
+}
+
+func (s journalSink) send(level slog.Level, msg string, fields []field) error {
+	priority := "6" // info
+	switch {
+	case level >= slog.LevelError:
+		priority = "3"
+	case level >= slog.LevelWarn:
+		priority = "4"
+	}
+	var b bytes.Buffer
+	journalField(&b, "MESSAGE", text(msg, fields))
+	journalField(&b, "PRIORITY", priority)
+	journalField(&b, "SYSLOG_IDENTIFIER", s.tag)
+	for _, f := range fields {
+		journalField(&b, journalKey(f.key), f.value)
+	}
+	_, err := s.conn.Write(b.Bytes())
+	return err
+}
+
+func (s journalSink) close() error { return s.conn.Close() }
+
+// journalField writes one field of an entry. A value with a newline in it
+// is sent with its length in front, as the protocol asks.
+func journalField(b *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(key + "=" + value + "\n")
+		return
+	}
+	b.WriteString(key + "\n")
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value + "\n")
+}
+
+// journalKey turns key into a journal field name: upper-case letters, digits
+// and underscores, starting with a letter.
+func journalKey(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		name = "F" + name
+	}
+	return name
+}
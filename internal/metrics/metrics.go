@@ -0,0 +1,166 @@
+// Package metrics keeps a summary of each day's use of the door for the
+// sysop: how many sessions there were and by how many callers, how long
+// they stayed, how often the cache answered, and how many requests to the
+// API failed.
+//
+// Every day has two files in the metrics directory, named for the day, as
+// in 2026-10-16.txt. The .txt file is the summary, one "key=value" per
+// line, rewritten as each session ends so it can be read at any time:
+//
+//	date                      the day, as YYYY-MM-DD
+//	sessions                  sessions that ended
+//	unique_users              callers among them
+//	average_duration_seconds  their average length, in whole seconds
+//	cache_hits, cache_misses  fetches answered from the cache, and not
+//	cache_hit_ratio           hits over all fetches, from 0 to 1
+//	api_errors                requests to the API that failed
+//
+// The .json file is the tally the summary is written from, which every
+// node adds to.
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robbiew/history/internal/filelock"
+)
+
+// Session tallies one caller's visit, to add to its day's summary when it
+// ends. Its counts may be added to from any goroutine. A nil *Session is
+// valid and discards everything, for when metrics are off.
+type Session struct {
+	dir   string
+	user  string
+	start time.Time
+
+	cacheHits, cacheMisses, apiErrors atomic.Int64
+	ended                             sync.Once
+}
+
+// Start begins tallying a session by user, to be added to the summaries in
+// dir. An empty dir turns metrics off and returns nil.
+func Start(dir, user string) *Session {
+	if dir == "" {
+		return nil
+	}
+	return &Session{dir: dir, user: user, start: time.Now()}
+}
+
+// CacheHit counts a fetch answered from the cache without asking the API.
+func (s *Session) CacheHit() {
+	if s != nil {
+		s.cacheHits.Add(1)
+	}
+}
+
+// CacheMiss counts a fetch that asked the API.
+func (s *Session) CacheMiss() {
+	if s != nil {
+		s.cacheMisses.Add(1)
+	}
+}
+
+// APIError counts a request to the API that failed.
+func (s *Session) APIError() {
+	if s != nil {
+		s.apiErrors.Add(1)
+	}
+}
+
+// tally is a day's counts, as kept in its .json file.
+type tally struct {
+	Sessions    int      `json:"sessions"`
+	Seconds     float64  `json:"seconds"`
+	Users       []string `json:"users"`
+	CacheHits   int64    `json:"cache_hits"`
+	CacheMisses int64    `json:"cache_misses"`
+	APIErrors   int64    `json:"api_errors"`
+}
+
+// End adds the session to the summary of the day it started on. Only the
+// first call counts, so every way out of the door can call it.
+func (s *Session) End() error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	s.ended.Do(func() { err = s.save(time.Now()) })
+	return err
+}
+
+// save adds the session, ended at end, to its day's tally and rewrites the
+// summary. Doors on other nodes add to the same files, so the tally is
+// read and saved under a lock, and each file is replaced whole through a
+// temporary file.
+func (s *Session) save(end time.Time) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	date := s.start.Format(time.DateOnly)
+	base := filepath.Join(s.dir, date)
+	unlock, err := filelock.Lock(base + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var t tally
+	data, err := os.ReadFile(base + ".json")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &t); err != nil {
+			return fmt.Errorf("parsing %s.json: %w", base, err)
+		}
+	}
+	t.Sessions++
+	t.Seconds += end.Sub(s.start).Seconds()
+	if user := strings.ToLower(strings.TrimSpace(s.user)); !slices.Contains(t.Users, user) {
+		t.Users = append(t.Users, user)
+	}
+	t.CacheHits += s.cacheHits.Load()
+	t.CacheMisses += s.cacheMisses.Load()
+	t.APIErrors += s.apiErrors.Load()
+
+	if data, err = json.Marshal(t); err != nil {
+		return err
+	}
+	if err := filelock.WriteAtomic(base+".json", data); err != nil {
+		return err
+	}
+	return filelock.WriteAtomic(base+".txt", []byte(summary(date, t)))
+}
+
+// summary writes t, the tally for date, as the day's .txt file.
+func summary(date string, t tally) string {
+	ratio := 0.0
+	if fetches := t.CacheHits + t.CacheMisses; fetches > 0 {
+		ratio = float64(t.CacheHits) / float64(fetches)
+	}
+	average := 0
+	if t.Sessions > 0 {
+		average = int(t.Seconds/float64(t.Sessions) + 0.5)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "date=%s\n", date)
+	fmt.Fprintf(&b, "sessions=%d\n", t.Sessions)
+	fmt.Fprintf(&b, "unique_users=%d\n", len(t.Users))
+	fmt.Fprintf(&b, "average_duration_seconds=%d\n", average)
+	fmt.Fprintf(&b, "cache_hits=%d\n", t.CacheHits)
+	fmt.Fprintf(&b, "cache_misses=%d\n", t.CacheMisses)
+	fmt.Fprintf(&b, "cache_hit_ratio=%.2f\n", ratio)
+	fmt.Fprintf(&b, "api_errors=%d\n", t.APIErrors)
+	return b.String()
+}
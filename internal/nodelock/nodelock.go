@@ -0,0 +1,170 @@
+// Package nodelock keeps two copies of the door from running on one node.
+//
+// The lock is a pidfile in the node directory. A lock whose process is gone,
+// or that is older than any session could last, is stale and taken over, so
+// a door killed by a dropped carrier does not lock the node for good.
+package nodelock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the lock file created in the node directory.
+const FileName = "history.lock"
+
+// writeGrace is how long a lock with no PID in it yet is left alone: the
+// door that created it writes its PID straight after, so until then it is
+// one being taken, not one left behind.
+const writeGrace = 2 * time.Second
+
+// takeoverExt names the file held, next to the lock, by a door clearing a
+// stale one.
+const takeoverExt = ".takeover"
+
+// maxAge is how long a lock is honoured even if its process still seems to be
+// running, in case the PID has been reused.
+const maxAge = 6 * time.Hour
+
+// LockedError reports that another live process holds the node.
+type LockedError struct {
+	Path  string
+	PID   int
+	Since time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("node is in use by process %d since %s (%s)", e.PID, e.Since.Format(time.Kitchen), e.Path)
+}
+
+// Acquire locks the node directory dir. The returned release func removes the
+// lock; it is safe to call more than once.
+func Acquire(dir string) (release func(), err error) {
+	path := filepath.Join(dir, FileName)
+	deadline := time.Now().Add(writeGrace)
+	for tries := 0; tries < 2; {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, werr := fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			if werr != nil {
+				os.Remove(path)
+				return nil, werr
+			}
+			released := false
+			return func() {
+				if !released {
+					released = true
+					os.Remove(path)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		pid, since, err := readLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if since.IsZero() {
+			// Released between our create and read; try again
+			tries++
+			continue
+		}
+		if pid <= 0 && time.Since(since) < writeGrace && time.Now().Before(deadline) {
+			// Another door is taking the node; wait for its PID
+			time.Sleep(writeGrace / 20)
+			continue
+		}
+		if pid > 0 && held(pid, since) {
+			return nil, &LockedError{Path: path, PID: pid, Since: since}
+		}
+		// Left behind by a door that crashed or lost its caller
+		busy, err := takeOver(path)
+		if err != nil {
+			return nil, err
+		}
+		if busy && time.Now().Before(deadline) {
+			// Another door is clearing it; see who ends up with the node
+			time.Sleep(writeGrace / 20)
+			continue
+		}
+		tries++
+	}
+	return nil, fmt.Errorf("could not lock %s", path)
+}
+
+// held reports whether a lock recording pid, taken at since, still belongs to
+// a door: one that is running, or that has yet to write its PID.
+func held(pid int, since time.Time) bool {
+	if pid <= 0 {
+		return time.Since(since) < writeGrace
+	}
+	return pid != os.Getpid() && processAlive(pid) && time.Since(since) < maxAge
+}
+
+// takeOver clears the stale lock at path. Two doors can find the same stale
+// lock at once, and by the time the slower one acts the faster may already
+// hold a new lock in its place, so clearing is done by one door at a time,
+// holding a takeover file next to the lock, and only after reading the lock
+// again and finding it still stale. It reports busy when another door holds
+// the takeover file.
+func takeOver(path string) (busy bool, err error) {
+	guard := path + takeoverExt
+	g, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		// A takeover file outlives its door only if the door died in the
+		// middle of clearing a lock
+		if fi, err := os.Stat(guard); err == nil && time.Since(fi.ModTime()) > writeGrace {
+			os.Remove(guard)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	g.Close()
+	defer os.Remove(guard)
+	pid, since, err := readLock(path)
+	if err != nil {
+		return false, err
+	}
+	if !since.IsZero() && !held(pid, since) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// readLock returns the PID recorded in the lock and when it was taken, or a
+// zero time if the lock is gone. A lock that cannot be parsed reports PID 0,
+// so it is treated as stale once it is older than writeGrace.
+func readLock(path string) (pid int, since time.Time, err error) {
+	// Read and stat the same open file, so the PID and time can't come from
+	// two different locks
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, time.Time{}, nil
+		}
+		return 0, time.Time{}, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, fi.ModTime(), nil
+}
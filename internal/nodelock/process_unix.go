@@ -0,0 +1,21 @@
+//go:build linux || solaris || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package nodelock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether pid names a running process, by sending it
+// the null signal.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	err = p.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
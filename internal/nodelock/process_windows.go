@@ -0,0 +1,14 @@
+package nodelock
+
+import "os"
+
+// processAlive reports whether pid names a running process: finding one
+// opens it, which fails once it has gone.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,235 @@
+// Package notice writes the screens the door shows in place of the day's
+// pages when it has none: the fetch failed, Wikipedia listed nothing, or
+// the caller stopped the loading; and in place of the day in the year the
+// caller was born when nothing is recorded for it. Each screen is a text/template, built in
+// here and open to a sysop to rewrite, in English or in their callers'
+// language.
+//
+// A template's first line is the screen's title, shown in the header. Each
+// line after it is a paragraph, wrapped to fit the screen; blank lines are
+// kept as blank rows. The first paragraph is the headline and is drawn
+// brighter than the rest. Templates are given a Data, and these functions
+// besides the standard ones:
+//
+//	day, date, shortdate  a time written in the door's locale
+//	since, until          the time.Duration from or to a time
+//	span                  a duration in rough English, as in "5 minutes"
+//	minutes, hours, days  a duration in whole units, rounded up
+//
+// Templates in other languages can write durations with minutes, hours
+// and days, as span is English only.
+package notice
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/robbiew/history/internal/locale"
+)
+
+//go:embed notices
+var builtin embed.FS
+
+// The screens, by their template names.
+const (
+	// Error is shown when the day couldn't be fetched, offering the saved
+	// copy of it if there is one.
+	Error = "error"
+	// Empty is shown when Wikipedia lists no events for the day.
+	Empty = "empty"
+	// Stopped is shown when the caller stopped the loading before anything
+	// was saved to show instead.
+	Stopped = "stopped"
+	// BirthYear is shown in place of the day in the year the caller was
+	// born when Wikipedia has nothing for it.
+	BirthYear = "birthyear"
+)
+
+// Names are every screen's template name.
+var Names = []string{Error, Empty, Stopped, BirthYear}
+
+// Why a fetch failed, for Data.Cause.
+const (
+	// CauseNetwork is Wikipedia not being reached at all.
+	CauseNetwork = "network"
+	// CauseTimeout is Wikipedia not answering in time.
+	CauseTimeout = "timeout"
+	// CauseStatus is Wikipedia answering with an error; Data.Status is its
+	// HTTP status.
+	CauseStatus = "status"
+	// CauseCircuit is the door not asking Wikipedia for a while after
+	// several failures in a row; Data.RetryAt is when it will ask again.
+	CauseCircuit = "circuit"
+	// CauseOffline is the door being set never to ask Wikipedia, so only
+	// days already saved can be shown.
+	CauseOffline = "offline"
+)
+
+// Data is what a screen's template is given.
+type Data struct {
+	// Date is the day asked for; only its month and day mean anything.
+	Date time.Time
+	// Err is the error the fetch failed with, and Cause and Status what
+	// kind of failure it was; all three are empty for screens other than
+	// Error.
+	Err    string
+	Cause  string
+	Status int
+	// CachedAt is when the copy of the day shown was fetched, for the
+	// Empty screen, or when the one the caller can have instead was, for
+	// the Error screen. LastFetched is when anything was last fetched from
+	// Wikipedia. Either is zero when unknown or there is none.
+	CachedAt    time.Time
+	LastFetched time.Time
+	// RetryAt is when the door will next ask Wikipedia, when it is waiting
+	// to, or zero.
+	RetryAt time.Time
+	// RefreshKey is the key that loads the day again, and CachedKey the one
+	// that shows the copy fetched at CachedAt on the Error screen; either
+	// is "" when no key is bound.
+	RefreshKey string
+	CachedKey  string
+	// Year is the year asked about on the BirthYear screen, and 0 on the
+	// others.
+	Year int
+	// Now is when the screen is drawn.
+	Now time.Time
+}
+
+// Screen is a written notice: its title, and its text a paragraph a line.
+type Screen struct {
+	Title string
+	Text  []string
+}
+
+// Set is the templates for every screen, in one language.
+type Set struct {
+	templates map[string]*template.Template
+}
+
+// Load returns the screens for the locale named lang, such as "de". Each
+// screen's template is the first there is of dir/lang/NAME.tmpl,
+// dir/NAME.tmpl, the built-in one for lang and the built-in English one;
+// an empty dir uses only the built-ins. Dates are written in loc.
+func Load(dir, lang string, loc *locale.Locale) (*Set, error) {
+	s := &Set{templates: make(map[string]*template.Template, len(Names))}
+	funcs := funcMap(loc)
+	var errs []error
+	for _, name := range Names {
+		text, file, err := find(dir, lang, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		s.templates[name] = t
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Default returns the built-in English screens, with dates in loc.
+func Default(loc *locale.Locale) *Set {
+	s, err := Load("", "en", loc)
+	if err != nil {
+		// The built-in templates are the door's own and always parse
+		panic(err)
+	}
+	return s
+}
+
+// find returns the text of screen name's template for lang, and the file
+// it came from.
+func find(dir, lang, name string) (text, file string, err error) {
+	if dir != "" {
+		for _, p := range []string{filepath.Join(dir, lang, name+".tmpl"), filepath.Join(dir, name+".tmpl")} {
+			data, err := os.ReadFile(p)
+			if err == nil {
+				return string(data), p, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", p, err
+			}
+		}
+	}
+	for _, p := range []string{path.Join("notices", lang, name+".tmpl"), path.Join("notices", name+".tmpl")} {
+		if data, err := builtin.ReadFile(p); err == nil {
+			return string(data), p, nil
+		}
+	}
+	return "", "", fmt.Errorf("no template for the %s screen", name)
+}
+
+// Render writes the screen called name, one of Names, for d.
+func (s *Set) Render(name string, d Data) (Screen, error) {
+	t, ok := s.templates[name]
+	if !ok {
+		return Screen{}, fmt.Errorf("unknown screen %q", name)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, d); err != nil {
+		return Screen{}, err
+	}
+	title, body, _ := strings.Cut(strings.TrimLeft(b.String(), "\r\n"), "\n")
+	text := strings.Split(strings.TrimRight(body, " \r\n"), "\n")
+	for i, line := range text {
+		text[i] = strings.TrimRight(line, " \r")
+	}
+	return Screen{Title: strings.TrimSpace(title), Text: text}, nil
+}
+
+// funcMap is the functions templates can use, writing dates in loc.
+func funcMap(loc *locale.Locale) template.FuncMap {
+	return template.FuncMap{
+		"day":       loc.Day,
+		"date":      loc.Date,
+		"shortdate": loc.ShortDate,
+		"since":     time.Since,
+		"until":     time.Until,
+		"span":      span,
+		"minutes":   func(d time.Duration) int { return roundUp(d, time.Minute) },
+		"hours":     func(d time.Duration) int { return roundUp(d, time.Hour) },
+		"days":      func(d time.Duration) int { return roundUp(d, 24*time.Hour) },
+	}
+}
+
+// roundUp is d in whole units, rounded up, and never less than one.
+func roundUp(d, unit time.Duration) int {
+	return max(int(math.Ceil(float64(d)/float64(unit))), 1)
+}
+
+// span writes d roughly, in the largest unit that keeps it a whole number
+// people would say: "less than a minute", "5 minutes", "3 hours", "2 days".
+func span(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "less than a minute"
+	case d < time.Hour:
+		return plural(int(math.Round(d.Minutes())), "minute")
+	case d < 48*time.Hour:
+		return plural(int(math.Round(d.Hours())), "hour")
+	}
+	return plural(int(math.Round(d.Hours()/24)), "day")
+}
@@ -0,0 +1,79 @@
+// Package pinned reads the sysop's pinned events file, which names an Event
+// of the Day to put first on the events screen.
+package pinned
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// Load returns the event pinned for month and day in the file at path, or
+// nil when none is. Each line is a day as MM-DD, the year (negative for BC),
+// and the event's text, with an optional Wikipedia article URL after
+// " -- ". Blank lines and lines starting with '#' are skipped; the first
+// line for a day wins, so pins can be written ahead.
+func Load(path string, month time.Month, day int) (*wikimedia.Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	want := fmt.Sprintf("%02d-%02d", int(month), day)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, date, err := Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if date == want {
+			return e, nil
+		}
+	}
+	return nil, scanner.Err()
+}
+
+// Parse splits a line in the pinned events format, as Load reads, into its
+// event and its MM-DD day.
+func Parse(line string) (*wikimedia.Event, string, error) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return nil, "", fmt.Errorf("want MM-DD YEAR text, got %q", line)
+	}
+	date, err := time.Parse("01-02", fields[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("day %q is not MM-DD", fields[0])
+	}
+	year, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, "", fmt.Errorf("year %q is not a number", fields[1])
+	}
+	text, link, _ := strings.Cut(fields[2], " -- ")
+	e := &wikimedia.Event{Year: year, Text: strings.TrimSpace(text)}
+	if link = strings.TrimSpace(link); link != "" {
+		e.Pages = []wikimedia.Page{{Title: articleTitle(link), URL: link}}
+	}
+	return e, date.Format("01-02"), nil
+}
+
+// articleTitle names the article at a /wiki/ URL by its path, as the feed's
+// normalized titles do.
+func articleTitle(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return strings.ReplaceAll(path.Base(u.Path), "_", " ")
+}
@@ -0,0 +1,125 @@
+// Package plugin runs external commands that draw extra door screens, such
+// as local weather or board news, over a small JSON protocol.
+//
+// The door starts the command once each time the screen is opened or
+// refreshed and writes a single Request to its stdin as one line of JSON.
+// The command writes a single Response to stdout and exits. Anything it
+// writes to stderr is logged. A command that exits non-zero, runs past its
+// timeout, or writes something other than a Response fails the screen.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Version is the protocol version sent in every Request. It changes only
+// when a change would break existing commands.
+const Version = 1
+
+// Request is what the door tells a command about the screen to draw.
+type Request struct {
+	Version int    `json:"version"`
+	Screen  string `json:"screen"` // the plugin's name from the config
+	Date    string `json:"date"`   // the day being shown, YYYY-MM-DD
+
+	User     string `json:"user"`
+	Node     int    `json:"node"`
+	SecLevel int    `json:"security_level"`
+	TimeLeft int    `json:"time_left"` // minutes
+
+	// Width and Height are the room for Lines, in columns and rows.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Response is the screen a command draws. Lines are plain text, shown as
+// given; lines past Height are dropped and each is cut to Width. A non-empty
+// Error is shown to the caller instead.
+type Response struct {
+	Title string   `json:"title"`
+	Lines []string `json:"lines"`
+	Error string   `json:"error,omitempty"`
+}
+
+// maxOutput caps how much of a command's stdout and stderr is read.
+const maxOutput = 64 << 10
+
+// Run starts command with req on its stdin and decodes its Response,
+// killing it once timeout has passed.
+func Run(ctx context.Context, command []string, timeout time.Duration, req Request) (*Response, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no command")
+	}
+	req.Version = Version
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(append(in, '\n'))
+	var stdout, stderr limitedBuffer
+	stdout.max, stderr.max = maxOutput, maxOutput
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		log.Printf("plugin %s: %s", command[0], msg)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s: no answer within %v", command[0], timeout)
+	}
+	if err != nil {
+		if msg := firstLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %v: %s", command[0], err, msg)
+		}
+		return nil, fmt.Errorf("%s: %v", command[0], err)
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("%s: output is over %d bytes", command[0], maxOutput)
+	}
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("%s: bad response: %v", command[0], err)
+	}
+	return &resp, nil
+}
+
+// firstLine returns the first non-blank line of s.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the
+// rest, so a runaway command can't exhaust memory.
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
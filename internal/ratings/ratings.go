@@ -0,0 +1,119 @@
+// Package ratings keeps the board's callers' votes on events, so the ones
+// they like can be favoured when picking.
+package ratings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/robbiew/history/internal/filelock"
+)
+
+// Book holds every caller's vote on every event they rated, keyed by event
+// ID and then by caller, in a JSON file all nodes share. A nil *Book is
+// valid, scores everything 0 and refuses votes.
+type Book struct {
+	path string
+
+	mu    sync.Mutex
+	votes map[string]map[string]int
+}
+
+// Open reads the book at path. A missing file starts an empty book; an empty
+// path returns nil.
+func Open(path string) (*Book, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b := &Book{path: path, votes: map[string]map[string]int{}}
+	return b, b.read()
+}
+
+// read replaces the book's votes with the file's. The caller holds mu.
+func (b *Book) read() error {
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	votes := map[string]map[string]int{}
+	if err := json.Unmarshal(data, &votes); err != nil {
+		return fmt.Errorf("parsing %s: %w", b.path, err)
+	}
+	b.votes = votes
+	return nil
+}
+
+// Reload reads in the votes other doors have saved since the book was read.
+func (b *Book) Reload() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.read()
+}
+
+// Score is an event's votes up less its votes down.
+func (b *Book) Score(id string) int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	score := 0
+	for _, v := range b.votes[id] {
+		score += v
+	}
+	return score
+}
+
+// Rate records user's vote on the event id, 1 for up or -1 for down,
+// replacing any vote they gave it before, and returns its new score. Doors
+// on other nodes save the same file, so it is saved under a lock with their
+// votes since it was read, and replaced whole through a temporary file.
+func (b *Book) Rate(id, user string, vote int) (int, error) {
+	if b == nil {
+		return 0, errors.New("ratings are off")
+	}
+	if vote != 1 && vote != -1 {
+		return 0, fmt.Errorf("vote %d is not 1 or -1", vote)
+	}
+	if err := b.save(id, user, vote); err != nil {
+		return 0, err
+	}
+	return b.Score(id), nil
+}
+
+func (b *Book) save(id, user string, vote int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(b.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := b.read(); err != nil {
+		return err
+	}
+	if b.votes[id] == nil {
+		b.votes[id] = map[string]int{}
+	}
+	b.votes[id][user] = vote
+
+	data, err := json.Marshal(b.votes)
+	if err != nil {
+		return err
+	}
+	return filelock.WriteAtomic(b.path, data)
+}
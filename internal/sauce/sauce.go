@@ -0,0 +1,96 @@
+// Package sauce writes SAUCE records, the metadata trailer BBS software and
+// art viewers read from the end of ANSI files to learn their size, title and
+// font. See https://www.acid.org/info/sauce/sauce.htm.
+package sauce
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Record is the SAUCE metadata for an ANSI file. Text fields longer than
+// their slot are cut; shorter ones are padded with spaces.
+type Record struct {
+	Title  string // up to 35 characters
+	Author string // up to 20
+	Group  string // up to 20
+	Date   time.Time
+	// Width and Height are the file's size in character cells.
+	Width, Height int
+	// ICEColors marks blink as meaning bright backgrounds.
+	ICEColors bool
+	// Font names the font the art is drawn for, e.g. "IBM VGA".
+	Font string
+}
+
+// Sizes of the fixed fields.
+const (
+	titleLen  = 35
+	authorLen = 20
+	groupLen  = 20
+	fontLen   = 22
+
+	// recordLen is the size of a whole record.
+	recordLen = 128
+)
+
+// Data and file types for ANSI text.
+const (
+	dataTypeCharacter = 1
+	fileTypeANSI      = 1
+)
+
+// eof ends the file's text so DOS-era viewers stop before the record.
+const eof = 0x1a
+
+// Append writes data, the end-of-file marker and rec's SAUCE record to w.
+func Append(w io.Writer, data []byte, rec Record) error {
+	var b bytes.Buffer
+	b.Grow(len(data) + 1 + recordLen)
+	b.Write(data)
+	b.WriteByte(eof)
+
+	b.WriteString("SAUCE00")
+	b.WriteString(field(rec.Title, titleLen, ' '))
+	b.WriteString(field(rec.Author, authorLen, ' '))
+	b.WriteString(field(rec.Group, groupLen, ' '))
+	b.WriteString(rec.Date.Format("20060102"))
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.WriteByte(dataTypeCharacter)
+	b.WriteByte(fileTypeANSI)
+	binary.Write(&b, binary.LittleEndian, uint16(rec.Width))
+	binary.Write(&b, binary.LittleEndian, uint16(rec.Height))
+	binary.Write(&b, binary.LittleEndian, uint16(0)) // TInfo3
+	binary.Write(&b, binary.LittleEndian, uint16(0)) // TInfo4
+	b.WriteByte(0)                                   // no comment block
+	var flags byte
+	if rec.ICEColors {
+		flags |= 1
+	}
+	b.WriteByte(flags)
+	b.WriteString(field(rec.Font, fontLen, 0))
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// field fits s to n bytes, padding with pad. Bytes outside ASCII are
+// replaced, since SAUCE text is CP437.
+func field(s string, n int, pad byte) string {
+	out := make([]byte, 0, n)
+	for _, r := range s {
+		if len(out) == n {
+			break
+		}
+		if r < 32 || r > 126 {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	for len(out) < n {
+		out = append(out, pad)
+	}
+	return string(out)
+}
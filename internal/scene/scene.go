@@ -0,0 +1,45 @@
+// Package scene is a small built-in dataset of milestones from the BBS,
+// demo, ANSI art and warez scenes, the history a door's callers lived
+// through, to blend into Wikipedia's events for the day.
+//
+// The milestones are in scene.txt, in the pinned events format.
+package scene
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/pinned"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+//go:embed scene.txt
+var data string
+
+// milestones are the dataset's events by MM-DD day.
+var milestones = parse(data)
+
+// parse reads the dataset.
+func parse(text string) map[string][]wikimedia.Event {
+	days := map[string][]wikimedia.Event{}
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, date, err := pinned.Parse(line)
+		if err != nil {
+			// The dataset is the door's own and always parses
+			panic(fmt.Sprintf("scene.txt:%d: %v", n+1, err))
+		}
+		days[date] = append(days[date], *e)
+	}
+	return days
+}
+
+// On returns the milestones that fell on month and day, in any year.
+func On(month time.Month, day int) []wikimedia.Event {
+	return append([]wikimedia.Event(nil), milestones[fmt.Sprintf("%02d-%02d", int(month), day)]...)
+}
@@ -0,0 +1,140 @@
+// Package seen remembers which events have been shown today, to a caller or
+// to everyone on the board, so later picks can favour ones not read yet.
+package seen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/robbiew/history/internal/filelock"
+)
+
+// Log is a record of the events shown on one day, to one caller or to all
+// of them, kept in a small JSON file. A nil *Log is valid, remembers nothing and reports
+// nothing seen, which keeps call sites free of checks.
+type Log struct {
+	path string
+	day  string
+
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+// file is the on-disk form of a Log.
+type file struct {
+	Day string   `json:"day"`
+	IDs []string `json:"ids"`
+}
+
+// Open reads the log at path for day. A missing file, or one left from an
+// earlier day, starts an empty log; the file is only written once something
+// is added. An empty path returns nil.
+func Open(path string, day time.Time) (*Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+	l := &Log{path: path, day: day.Format(time.DateOnly), ids: map[string]bool{}}
+	return l, l.read()
+}
+
+// ID names an event for the log by its section, year and text.
+func ID(section string, year int, text string) string {
+	h := fnv.New32a()
+	h.Write([]byte(text))
+	return fmt.Sprintf("%s/%d/%08x", section, year, h.Sum32())
+}
+
+// Has reports whether the event with id has been shown today.
+func (l *Log) Has(id string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ids[id]
+}
+
+// Reload reads in what other processes have added to the log's file since
+// it was opened. Events the log already holds are kept.
+func (l *Log) Reload() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.read()
+}
+
+// read merges the file's ids into the log when it is from the same day.
+// The caller holds mu.
+func (l *Log) read() error {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("parsing %s: %w", l.path, err)
+	}
+	if f.Day == l.day {
+		for _, id := range f.IDs {
+			l.ids[id] = true
+		}
+	}
+	return nil
+}
+
+// Add records ids as shown and saves the log if any of them are new. The
+// file may be shared with other doors, on other nodes or by the same caller
+// twice, so it is saved under a lock, with what they added since it was
+// read, and replaced whole through a temporary file so a crash never leaves
+// half of it.
+func (l *Log) Add(ids ...string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	added := false
+	for _, id := range ids {
+		if !l.ids[id] {
+			l.ids[id] = true
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(l.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := l.read(); err != nil {
+		return err
+	}
+
+	f := file{Day: l.day}
+	for id := range l.ids {
+		f.IDs = append(f.IDs, id)
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return filelock.WriteAtomic(l.path, data)
+}
@@ -0,0 +1,14 @@
+// Package serial opens a serial port for a caller on a modem or null-modem
+// link, in raw mode so the door's bytes go out as they are.
+package serial
+
+// Config is the line a port is set up with. Eight data bits and one stop
+// bit are always used; the art needs all eight bits.
+type Config struct {
+	Baud   int
+	Parity string // "none", "even" or "odd"
+	// Flow is "hardware" for RTS/CTS flow control, with the modem's
+	// carrier watched so a hang-up ends the session, or "none" for a
+	// three-wire cable.
+	Flow string
+}
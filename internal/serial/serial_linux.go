@@ -0,0 +1,80 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// speeds are the termios codes for the baud rates a port can be set to.
+var speeds = map[int]uint32{
+	300: unix.B300, 1200: unix.B1200, 2400: unix.B2400, 4800: unix.B4800,
+	9600: unix.B9600, 19200: unix.B19200, 38400: unix.B38400,
+	57600: unix.B57600, 115200: unix.B115200,
+}
+
+// Open opens the serial device at path and sets it up with cfg. Reads block
+// until the caller sends something, and end with io.EOF when the carrier
+// drops.
+func Open(path string, cfg Config) (*os.File, error) {
+	speed, ok := speeds[cfg.Baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", cfg.Baud)
+	}
+	// Opened without waiting for a carrier, which the settings below may
+	// then require, and without becoming the door's controlling terminal
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	if err := setup(fd, speed, cfg); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("setting up %s: %w", path, err)
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+// setup puts the port in raw mode with cfg's line settings, then makes its
+// reads block again.
+func setup(fd int, speed uint32, cfg Config) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	// Raw: no echo, no line editing or signals, and no CR or LF rewritten
+	// either way, since the door sends CR LF itself
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.IXANY | unix.INPCK
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CBAUD | unix.CSIZE | unix.CSTOPB | unix.PARENB | unix.PARODD | unix.CRTSCTS | unix.CLOCAL
+	t.Cflag |= speed | unix.CS8 | unix.CREAD | unix.HUPCL
+	t.Ispeed, t.Ospeed = speed, speed
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+
+	switch cfg.Parity {
+	case "none":
+	case "even":
+		t.Cflag |= unix.PARENB
+		t.Iflag |= unix.INPCK
+	case "odd":
+		t.Cflag |= unix.PARENB | unix.PARODD
+		t.Iflag |= unix.INPCK
+	default:
+		return fmt.Errorf("unknown parity %q", cfg.Parity)
+	}
+	switch cfg.Flow {
+	case "hardware":
+		t.Cflag |= unix.CRTSCTS
+	case "none":
+		// No modem lines to watch on a three-wire cable
+		t.Cflag |= unix.CLOCAL
+	default:
+		return fmt.Errorf("unknown flow control %q", cfg.Flow)
+	}
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
+		return err
+	}
+	return unix.SetNonblock(fd, false)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package serial
+
+import (
+	"errors"
+	"os"
+)
+
+// Open fails outside Linux, where the door doesn't drive serial ports.
+func Open(path string, cfg Config) (*os.File, error) {
+	return nil, errors.New("serial ports are only supported on Linux")
+}
@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recorder appends one JSON object per line to a stats file. Each record is a
+// single small append, so several nodes can share one file. A nil *Recorder is
+// valid and discards everything, which keeps call sites free of checks.
+type Recorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns a Recorder writing to path, or nil when path is empty.
+func Open(path string) *Recorder {
+	if path == "" {
+		return nil
+	}
+	return &Recorder{path: path}
+}
+
+// Record writes fields as one line, adding "time" and "kind" keys.
+func (r *Recorder) Record(kind string, fields map[string]any) error {
+	if r == nil {
+		return nil
+	}
+	line := make(map[string]any, len(fields)+2)
+	for k, v := range fields {
+		line[k] = v
+	}
+	line["time"] = time.Now().Format(time.RFC3339)
+	line["kind"] = kind
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,45 @@
+// Package taglines reads the sysop's taglines file, one of which is shown in
+// the footer area each session.
+package taglines
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// Load reads taglines from path: one per line, with blank lines and lines
+// starting with '#' skipped. An attribution may follow the text after " -- ".
+func Load(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// Pick returns a tagline chosen with rng, or "" when there are none.
+func Pick(rng *rand.Rand, lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[rng.Intn(len(lines))]
+}
+
+// Split separates a tagline into its text and attribution.
+func Split(tagline string) (text, attribution string) {
+	text, attribution, _ = strings.Cut(tagline, " -- ")
+	return strings.TrimSpace(text), strings.TrimSpace(attribution)
+}
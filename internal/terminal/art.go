@@ -0,0 +1,135 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Art is a screen of ANSI art a sysop drew, such as the easter egg's: up
+// to 23 lines, in CP437 as ANSI art files are, above a prompt on the
+// bottom row.
+type Art struct {
+	Lines []string
+}
+
+// The most a screen of art takes: the rows above the prompt, and a column
+// short of the last, so no terminal wraps.
+const (
+	artRows = 23
+	artCols = 79
+)
+
+// artForward matches a cursor move right, which art drawn to save space
+// uses for runs of blanks.
+var artForward = regexp.MustCompile(`\x1b\[([0-9]*)C`)
+
+// artControl matches what art may not keep: escape sequences other than
+// colors, and control characters other than ESC.
+var artControl = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-ln-z]|[\x00-\x1a\x1c-\x1f\x7f]`)
+
+// readArt reads the first rows lines of the ANSI art file at path, each
+// ending in a reset. Moves right become blanks, colors are kept, and other
+// cursor moves, screen clears and anything after the end-of-file mark or a
+// SAUCE record are dropped. A line wider than cols is an error.
+func readArt(path string, rows, cols int) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(data, 0x1a); i >= 0 {
+		data = data[:i]
+	}
+	var lines []string
+	for i, line := range strings.Split(string(data), "\n") {
+		if i == rows {
+			break
+		}
+		line = artForward.ReplaceAllStringFunc(strings.TrimRight(line, "\r"), func(move string) string {
+			n, err := strconv.Atoi(move[2 : len(move)-1])
+			if err != nil {
+				n = 1
+			}
+			return strings.Repeat(" ", n)
+		})
+		line = artControl.ReplaceAllString(line, "")
+		if w := len(sgr.ReplaceAllString(line, "")); w > cols {
+			return nil, fmt.Errorf("%s: line %d is %d columns wide, at most %d fit", path, i+1, w, cols)
+		}
+		lines = append(lines, line+Reset)
+	}
+	return lines, nil
+}
+
+// LoadArt reads a screen of art from the ANSI art file at path, in CP437.
+// Its first 23 lines are kept, each up to 79 columns wide.
+func LoadArt(path string) (Art, error) {
+	lines, err := readArt(path, artRows, artCols)
+	return Art{Lines: lines}, err
+}
+
+// artLine returns a line of art, in CP437, as drawn in c: as it is for
+// CP437, in Unicode for the charsets drawn in it, and in ASCII's nearest
+// characters otherwise, or where Unicode's would be too wide.
+func artLine(line string, c Charset) string {
+	if c == CP437 {
+		return line
+	}
+	line, _ = charmap.CodePage437.NewDecoder().String(line)
+	if c.unicode() && !c.wideArt() {
+		return line
+	}
+	return strings.Map(asciiArt, line)
+}
+
+// asciiArt stands in for a CP437 character in ASCII: blocks and shades by
+// how dark they are, box drawing by its lines, and anything else by a
+// blank.
+func asciiArt(r rune) rune {
+	switch {
+	case r < 0x80:
+		return r
+	case r == '░':
+		return '.'
+	case r == '▒':
+		return ':'
+	case r >= '▀' && r <= '▟':
+		return '#'
+	case strings.ContainsRune("─━═", r):
+		return '-'
+	case strings.ContainsRune("│┃║", r):
+		return '|'
+	case r >= '─' && r <= '╿':
+		return '+'
+	}
+	return ' '
+}
+
+// RenderArt draws art over the whole screen, with the prompt to press any
+// key to do what then, such as "GO BACK", on the bottom row.
+func RenderArt(cfg TerminalConfig, art Art, then string) {
+	screen.Lock()
+	defer screen.Unlock()
+	frame(cfg, func() {
+		ClearScreen()
+		for i, line := range art.Lines {
+			MoveCursor(1, i+1)
+			write(artLine(line, cfg.Charset))
+		}
+		MoveCursor(1, 24)
+		write(anyKey(then))
+		redrawStatus(cfg)
+	})
+}
+
+// anyKey is the prompt on a screen's bottom row to press any key to do
+// what then says, centered.
+func anyKey(then string) string {
+	width := len("<<  ... press ANY KEY to  ... >>") + len(then)
+	return strings.Repeat(" ", (screenCols-width)/2-1) + BgBlueHi + WhiteHi + "<" + Reset + CyanHi + "<  " + BlackHi + "... " + Reset + WhiteHi + "press " + WhiteHi + "ANY KEY " + Reset + WhiteHi + "to " + WhiteHi + then + " " + Reset + BlackHi + "... " + Reset + CyanHi + ">" + BgBlueHi + WhiteHi + ">" + Reset
+}
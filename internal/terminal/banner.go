@@ -0,0 +1,130 @@
+package terminal
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// Banner sizes: a standard screen, or a tall one for 50-line displays.
+const (
+	BannerWidth = 80
+	bannerRows  = 24
+	bannerTall  = 50
+)
+
+// BannerRows are the heights Banner can draw.
+var BannerRows = []int{bannerRows, bannerTall}
+
+// bannerEvents is how many events a banner shows.
+const bannerEvents = 3
+
+// Banner draws page as a static screen for a BBS display file slot, such as
+// a login or menu screen: the header, the page's first three events and a
+// dated footer, rows lines tall. Nothing is positioned with cursor moves,
+// so it displays the same wherever it is shown. Every row is padded to 79
+// columns and ended with CR LF, except the last, so the screen neither
+// wraps nor scrolls on an 80-column terminal. generated is the date shown
+// in the footer, written in loc like the page's own; the dividers are
+// drawn in charset, and the whole screen is encoded in it when it is a
+// regional code page.
+func Banner(page Page, rows int, generated time.Time, charset Charset, loc *locale.Locale) []byte {
+	events := page.Events
+	if len(events) > bannerEvents {
+		events = events[:bannerEvents]
+	}
+
+	r := ANSI{Config: TerminalConfig{Charset: charset, Locale: loc}}
+	lines := []string{
+		" " + charset.rule(headerRuleTop),
+		" " + headerTitle,
+		" " + charset.rule(headerRuleMid),
+		" " + r.Title(page),
+		" " + charset.rule(headerRuleBottom),
+		"",
+	}
+	footer := []string{
+		charset.rule(footerRule),
+		r.Footer(generated),
+		charset.rule(footerRule),
+	}
+
+	// The events share what is left, a blank row after each
+	room := rows - len(lines) - len(footer)
+	style := entryStyle(page, events)
+	if len(events) > 0 {
+		style.Rows = room/len(events) - 1
+	}
+	for _, e := range events {
+		lines = append(lines, r.Entry(e, style, BannerWidth-1)...)
+		lines = append(lines, "")
+	}
+	for len(lines) < rows-len(footer) {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:rows-len(footer)], footer...)
+
+	var b strings.Builder
+	b.WriteString(r.Begin())
+	for i, line := range lines {
+		b.WriteString(padVisible(line, BannerWidth-1))
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	if cm := charset.Codepage(); cm != nil {
+		var encoded bytes.Buffer
+		(&encoder{w: &encoded, cm: cm}).Write([]byte(b.String()))
+		return encoded.Bytes()
+	}
+	return []byte(b.String())
+}
+
+// clipLines keeps at most n of lines, marking a cut with "..." on the last
+// one kept, which stays within width.
+func clipLines(lines []string, n, width int) []string {
+	if n < 1 {
+		return nil
+	}
+	if len(lines) <= n {
+		return lines
+	}
+	lines = lines[:n]
+	lines[n-1] = strings.TrimRight(textwrap.Fit(lines[n-1], width-len(textwrap.Ellipsis)), " ,;:.") + textwrap.Ellipsis
+	return lines
+}
+
+// sgr matches the color sequences the renderers use.
+var sgr = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// padVisible pads s with spaces, or cuts it, to exactly width visible
+// columns, leaving its color sequences alone. A cut line ends with a reset.
+func padVisible(s string, width int) string {
+	visible := textwrap.Width(s)
+	if visible <= width {
+		return s + strings.Repeat(" ", width-visible)
+	}
+	var b strings.Builder
+	n := 0
+	for len(s) > 0 && n < width {
+		if loc := sgr.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			b.WriteString(s[:loc[1]])
+			s = s[loc[1]:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		if n+textwrap.RuneWidth(r) > width {
+			break
+		}
+		b.WriteString(s[:size])
+		s = s[size:]
+		n += textwrap.RuneWidth(r)
+	}
+	// A wide character that didn't fit leaves a column to pad
+	return b.String() + Reset + strings.Repeat(" ", width-n)
+}
@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// centered is whether the screens are centered in a window wider than
+// them, and margin how many columns in that puts them. The caller holds
+// screen to use them.
+var (
+	centered bool
+	margin   int
+)
+
+// SetCentered centers the door's 80 columns in the caller's window, which
+// is cols wide until Resize says otherwise, rather than drawing them flush
+// left. Recordings are still made flush left, so they play back in 80
+// columns.
+func SetCentered(cols int) {
+	screen.Lock()
+	defer screen.Unlock()
+	centered = true
+	setMargin(cols)
+	connect()
+}
+
+// setMargin centers the screens in a window cols wide. The caller holds
+// screen.
+func setMargin(cols int) {
+	margin = 0
+	if centered && cols > screenCols {
+		margin = (cols - screenCols) / 2
+	}
+}
+
+// centerWriter moves everything written to it margin columns right on its
+// way to w: a cursor position gains margin columns, and each new line
+// starts with a move right. Line endings are already as the caller wants
+// them, so a lone CR or LF gets the move as well as CR LF.
+type centerWriter struct {
+	w       io.Writer
+	buf     []byte
+	seq     []byte // an escape sequence split across writes
+	cr      bool   // the last byte written was a CR, not yet moved after
+	pending bool   // seq holds an unfinished sequence
+}
+
+func (c *centerWriter) Write(p []byte) (int, error) {
+	if margin == 0 && !c.pending && !c.cr {
+		return c.w.Write(p)
+	}
+	c.buf = c.buf[:0]
+	for _, b := range p {
+		if c.pending {
+			c.seq = append(c.seq, b)
+			if len(c.seq) == 2 && b != '[' || len(c.seq) > 2 && b >= 0x40 && b <= 0x7e {
+				c.buf = cursorPosition(c.buf, c.seq)
+				c.pending = false
+			}
+			continue
+		}
+		if c.cr {
+			c.cr = false
+			if b != '\n' {
+				c.buf = moveRight(c.buf)
+			}
+		}
+		switch b {
+		case 0x1b:
+			c.seq, c.pending = append(c.seq[:0], b), true
+		case '\r':
+			c.buf = append(c.buf, b)
+			c.cr = true
+		case '\n':
+			c.buf = append(c.buf, b)
+			c.buf = moveRight(c.buf)
+		default:
+			c.buf = append(c.buf, b)
+		}
+	}
+	if _, err := c.w.Write(c.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// moveRight appends to b the move from the first column to the margin.
+func moveRight(b []byte) []byte {
+	if margin == 0 {
+		return b
+	}
+	b = append(b, Esc...)
+	b = strconv.AppendInt(b, int64(margin), 10)
+	return append(b, 'C')
+}
+
+// cursorPosition appends to b the escape sequence seq, with its column
+// moved in by the margin if it positions the cursor.
+func cursorPosition(b, seq []byte) []byte {
+	final := seq[len(seq)-1]
+	if margin == 0 || len(seq) < 3 || final != 'H' && final != 'f' {
+		return append(b, seq...)
+	}
+	row, col, _ := bytes.Cut(seq[2:len(seq)-1], []byte(";"))
+	n, err := strconv.Atoi(string(col))
+	if len(col) == 0 {
+		n, err = 1, nil
+	}
+	if err != nil {
+		return append(b, seq...)
+	}
+	if len(row) == 0 {
+		row = []byte("1")
+	}
+	b = append(b, Esc...)
+	b = append(b, row...)
+	b = append(b, ';')
+	b = strconv.AppendInt(b, int64(n+margin), 10)
+	return append(b, final)
+}
@@ -0,0 +1,155 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robbiew/history/pkg/textwrap"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Charset is the character set the door's art is drawn in: the dividers
+// and the loading bar. The empty Charset is CP437.
+type Charset string
+
+const (
+	// CP437 is the IBM PC set BBS terminals expect.
+	CP437 Charset = "cp437"
+	// ASCII is plain 7-bit text, for callers whose terminal can't show
+	// anything else.
+	ASCII Charset = "ascii"
+	// UTF8 is Unicode box-drawing and block characters, for modern
+	// terminals such as a local shell or tmux.
+	UTF8 Charset = "utf8"
+	// CP850, CP852 and CP866 are the DOS code pages for Western European,
+	// Central European and Cyrillic languages. Event text keeps the letters
+	// they have. The renderers draw in Unicode for them, and the output is
+	// encoded on its way to the caller.
+	CP850 Charset = "cp850"
+	CP852 Charset = "cp852"
+	CP866 Charset = "cp866"
+)
+
+// Charsets are the character sets the door can draw in.
+var Charsets = []Charset{CP437, ASCII, UTF8, CP850, CP852, CP866}
+
+// codepages are the tables that encode the regional code pages.
+var codepages = map[Charset]*charmap.Charmap{
+	CP850: charmap.CodePage850,
+	CP852: charmap.CodePage852,
+	CP866: charmap.CodePage866,
+}
+
+// Codepage returns the regional code page c stands for, or nil for CP437,
+// ASCII and UTF-8.
+func (c Charset) Codepage() *charmap.Charmap {
+	return codepages[c]
+}
+
+// unicode reports whether the renderers draw in Unicode for c: for UTF-8,
+// and for the regional code pages, which are encoded on the way out.
+func (c Charset) unicode() bool {
+	return c == UTF8 || c.Codepage() != nil
+}
+
+// wideArt reports whether the box and block characters c draws its art
+// with take two columns, as ambiguous-width characters do on a UTF-8
+// terminal set up for Chinese, Japanese or Korean. The art is drawn in
+// ASCII then, to keep its shape.
+func (c Charset) wideArt() bool {
+	return c == UTF8 && textwrap.AmbiguousWide
+}
+
+// glyphs are the characters a Charset draws its art with.
+type glyphs struct {
+	rule  string   // one cell of a divider
+	block string   // a filled cell of the loading bar
+	shade string   // an unfilled one
+	spin  []string // the frames of the loading spinner
+}
+
+var charsetGlyphs = map[Charset]glyphs{
+	CP437: {rule: "\xC4", block: "\xDB", shade: "\xB0", spin: []string{"\xB0", "\xB1", "\xB2", "\xDB", "\xB2", "\xB1"}},
+	ASCII: {rule: "-", block: "#", shade: ".", spin: []string{"|", "/", "-", "\\"}},
+	UTF8:  {rule: "─", block: "█", shade: "░", spin: []string{"░", "▒", "▓", "█", "▓", "▒"}},
+}
+
+func (c Charset) glyphs() glyphs {
+	if c.wideArt() {
+		return charsetGlyphs[ASCII]
+	}
+	if c.Codepage() != nil {
+		// Encoded to the same box and block characters as CP437's
+		return charsetGlyphs[UTF8]
+	}
+	if g, ok := charsetGlyphs[c]; ok {
+		return g
+	}
+	return charsetGlyphs[CP437]
+}
+
+// rule draws divider art, written with "-" for each rule cell, in c.
+func (c Charset) rule(art string) string {
+	return strings.ReplaceAll(art, "-", c.glyphs().rule)
+}
+
+// Bar draws a loading bar width cells wide with the first filled cells
+// full, in cyan.
+func (c Charset) Bar(filled, width int) string {
+	g := c.glyphs()
+	filled = min(max(filled, 0), width)
+	return Cyan + strings.Repeat(g.block, filled) + Reset + strings.Repeat(g.shade, width-filled)
+}
+
+// Spinner returns frame n of the loading spinner, one cell wide, counting
+// from 0 and going round.
+func (c Charset) Spinner(n int) string {
+	spin := c.glyphs().spin
+	return spin[n%len(spin)]
+}
+
+// encoder wraps w so Unicode written to it reaches w in a regional code
+// page. Bytes that aren't UTF-8 pass through as they are, and a character
+// the code page lacks becomes "?". A character split across writes is kept
+// back for the next.
+type encoder struct {
+	w       io.Writer
+	cm      *charmap.Charmap
+	partial []byte
+	buf     []byte
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+	data := p
+	if len(e.partial) > 0 {
+		data = append(e.partial, p...)
+		e.partial = nil
+	}
+	e.buf = e.buf[:0]
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c < utf8.RuneSelf {
+			e.buf = append(e.buf, c)
+			i++
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			e.partial = append([]byte(nil), data[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if b, ok := e.cm.EncodeRune(r); ok && r != utf8.RuneError {
+			e.buf = append(e.buf, b)
+		} else if size == 1 {
+			e.buf = append(e.buf, c)
+		} else {
+			e.buf = append(e.buf, '?')
+		}
+		i += size
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
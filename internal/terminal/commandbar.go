@@ -0,0 +1,67 @@
+package terminal
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// Command is one entry of the command bar: an action's label and the key
+// that triggers it.
+type Command struct {
+	Key   string
+	Label string
+	// Numbered, when set, are the keys that pick the page's entries by
+	// number, and Key is drawn as the range of them for the entries that
+	// made it on screen, as "[1-3] Detail". With none on screen the command
+	// is left out.
+	Numbered []string
+}
+
+// commandBarWidth is the room the bar may take on the prompt row.
+const commandBarWidth = 78
+
+// commandText renders c as "[N]ext" when its key is a letter of the label,
+// or as "[?] Help" otherwise, returning the colored text and its width.
+func commandText(c Command) (string, int) {
+	if utf8.RuneCountInString(c.Key) == 1 {
+		if i := strings.Index(strings.ToLower(c.Label), strings.ToLower(c.Key)); i >= 0 {
+			n := len(c.Key)
+			return Cyan + c.Label[:i] + BlackHi + "[" + WhiteHi + c.Label[i:i+n] + BlackHi + "]" + Cyan + c.Label[i+n:] + Reset,
+				textwrap.Width(c.Label) + 2
+		}
+	}
+	return BlackHi + "[" + WhiteHi + c.Key + BlackHi + "] " + Cyan + c.Label + Reset,
+		textwrap.Width(c.Key) + textwrap.Width(c.Label) + 3
+}
+
+// renderCommandBar draws the commands centered on the prompt row, dropping
+// trailing ones that don't fit. The caller has set onScreen.
+func renderCommandBar(commands []Command) {
+	var parts []string
+	width := 0
+	for _, c := range commands {
+		if c.Numbered != nil {
+			n := min(len(c.Numbered), len(onScreen.Events))
+			if n == 0 {
+				continue
+			}
+			c.Key = c.Numbered[0]
+			if n > 1 {
+				c.Key += "-" + c.Numbered[n-1]
+			}
+		}
+		text, w := commandText(c)
+		if len(parts) > 0 {
+			w++
+		}
+		if width+w > commandBarWidth {
+			break
+		}
+		parts = append(parts, text)
+		width += w
+	}
+	MoveCursor(1, 24)
+	write(strings.Repeat(" ", (80-width)/2) + strings.Join(parts, " "))
+}
@@ -0,0 +1,73 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// detailWidth is how wide an event's text is wrapped on its detail page.
+const detailWidth = 76
+
+// renderDetail fills the event area with a detail page's one event: its
+// year and era, then the whole of its text and its article's summary as far
+// as rows allow and, with links on, its article and the others it
+// mentions, above the tagline.
+// Holidays and news have no year, so their text starts at the top.
+func renderDetail(cfg TerminalConfig, page Page, rows int, tagline []string) {
+	onScreen = page
+	if len(page.Events) == 0 {
+		return
+	}
+	e := page.Events[0]
+	onScreen.Events = page.Events[:1]
+
+	var links []string
+	if cfg.ShowLinks && e.URL != "" {
+		links = append(links, " "+CyanHi+"Read more: "+WhiteHi+e.Title+Reset, "   "+Cyan+e.URL+Reset)
+		if len(e.Related) > 0 {
+			also := textwrap.Cut(strings.Join(e.Related, ", "), detailWidth-10)
+			links = append(links, " "+CyanHi+"See also: "+Reset+also)
+		}
+	} else if cfg.Attribution && e.Title != "" {
+		from := strings.Join(append([]string{e.Title}, e.Related...), ", ")
+		links = append(links, " "+CyanHi+"From Wikipedia: "+Reset+textwrap.Cut(from, detailWidth-16))
+	}
+
+	row := 8
+	if e.Year != 0 {
+		color := cfg.eraColor(e.Era)
+		MoveCursor(1, row)
+		write(" ", cfg.yearPrefix(e, 0), color, e.Era.Name, Reset)
+		row += 2
+	}
+	room := rows - (row - 8)
+	if len(links) > 0 {
+		room -= len(links) + 1
+	}
+	text := clipLines(textwrap.Wrap(strings.TrimSpace(e.Text), detailWidth), room, detailWidth)
+	for _, line := range text {
+		MoveCursor(1, row)
+		write("  ", WhiteHi, line, Reset)
+		row++
+	}
+
+	// The article's summary gets whatever room the text leaves, under a blank row
+	if room -= len(text) + 1; len(page.Lines) > 0 && room > 0 {
+		row++
+		for _, line := range clipLines(textwrap.Wrap(strings.Join(page.Lines, " "), detailWidth), room, detailWidth) {
+			MoveCursor(1, row)
+			write("  ", Reset, line)
+			row++
+		}
+	}
+
+	for i, line := range links {
+		MoveCursor(1, 20-len(tagline)-len(links)+i)
+		write(line)
+	}
+	for i, line := range tagline {
+		MoveCursor(1, 20-len(tagline)+i)
+		write(line)
+	}
+}
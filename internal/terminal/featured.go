@@ -0,0 +1,45 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// articleWidth is how wide a featured article's summary is wrapped.
+const articleWidth = 76
+
+// articleLines wraps an article summary into at most rows lines, ending
+// the last one with "..." when the summary runs longer.
+func articleLines(text string, rows int) []string {
+	return clipLines(textwrap.Wrap(strings.TrimSpace(text), articleWidth), rows, articleWidth)
+}
+
+// renderArticle fills the event area with a featured page's article: its
+// title, a blank row, then as much of the summary as fits in rows with a
+// blank row under it, above the footnotes and tagline.
+func renderArticle(page Page, rows int, footnotes, tagline []string) {
+	onScreen = page
+	if len(page.Events) == 0 {
+		onScreen.Events = nil
+		return
+	}
+	e := page.Events[0]
+	onScreen.Events = page.Events[:1]
+
+	MoveCursor(1, 8)
+	write(" " + YellowHi + e.Title + Reset)
+	for i, line := range articleLines(e.Text, rows-3) {
+		MoveCursor(1, 10+i)
+		write("  " + WhiteHi + line + Reset)
+	}
+
+	for i, line := range footnotes {
+		MoveCursor(1, 20-len(tagline)-len(footnotes)+i)
+		write(line)
+	}
+	for i, line := range tagline {
+		MoveCursor(1, 20-len(tagline)+i)
+		write(line)
+	}
+}
@@ -0,0 +1,104 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// footerRule is the divider drawn above and below the footer line.
+const footerRule = " " + BlackHi + "-" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "--" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "-----" + Reset + CyanHi + "-" + GreenHi + "--------------------------------------- ---  --- -- -  " + Reset
+
+// clockLayout is the time.Format layout for a time of day.
+func (cfg TerminalConfig) clockLayout() string {
+	if cfg.Clock24 {
+		return "15:04"
+	}
+	return "3:04 PM"
+}
+
+// maxTaglineRows caps how much of the events area a tagline may take.
+const maxTaglineRows = 2
+
+// taglineLines wraps the session's tagline for the rows above the footer,
+// quote in yellow and attribution in cyan. The attribution shares the last
+// line when it fits and gets its own, right-aligned, when it doesn't.
+func taglineLines(cfg TerminalConfig, width int) []string {
+	if cfg.Tagline == "" {
+		return nil
+	}
+	text := textwrap.Wrap(`"`+cfg.Tagline+`"`, width)
+	if len(text) > maxTaglineRows {
+		text = text[:maxTaglineRows]
+		last := []rune(text[maxTaglineRows-1])
+		text[maxTaglineRows-1] = string(last[:min(len(last), width-3)]) + "..."
+	}
+	lines := make([]string, len(text))
+	for i, t := range text {
+		lines[i] = " " + Yellow + t + Reset
+	}
+	if cfg.TaglineBy == "" {
+		return lines
+	}
+	by := " -- " + cfg.TaglineBy
+	if last := len(text) - 1; textwrap.Width(text[last])+textwrap.Width(by) <= width {
+		lines[last] += CyanHi + by + Reset
+	} else if len(lines) < maxTaglineRows {
+		pad := strings.Repeat(" ", max(width-textwrap.Width(by), 0))
+		lines = append(lines, " "+pad+CyanHi+by+Reset)
+	}
+	return lines
+}
+
+// poolNouns name what a page of each kind lists.
+var poolNouns = map[string]string{
+	"":           "events",
+	KindEvents:   "events",
+	KindBirths:   "births",
+	KindDeaths:   "deaths",
+	KindHolidays: "holidays",
+	KindNews:     "stories",
+}
+
+// renderFooter draws rows 20-22: the dividers and, between them, when the
+// screen was generated, how old a cached copy is and, for a page with a
+// pool, how many of its entries are on screen. The page's own renderer has
+// already set onScreen.
+func renderFooter(cfg TerminalConfig, page Page, now time.Time) {
+	MoveCursor(1, 20)
+	write(cfg.rule(footerRule))
+
+	// A cached note leaves less room, so the date is shortened to fit
+	date := cfg.Locale.Date(now)
+	if !page.CachedAt.IsZero() {
+		date = cfg.Locale.ShortDate(now)
+	}
+	MoveCursor(1, 21)
+	generated := "Generated on " + date + " at " + now.Format(cfg.clockLayout()) + " "
+	write(" " + BgRed + BlackHi + ">>" + BgBlack + " " + WhiteHi + generated + Reset)
+	used := 4 + textwrap.Width(generated)
+	if !page.CachedAt.IsZero() {
+		cached := "(cached from " + cfg.Locale.ShortDay(page.CachedAt) + " " + page.CachedAt.Format(cfg.clockLayout()) + ")"
+		write(YellowHi + cached + Reset)
+		used += textwrap.Width(cached)
+	}
+
+	// How much of the day is on screen, right-aligned, shortened when the
+	// cached note leaves little room
+	if len(page.Pool) > 0 {
+		count := fmt.Sprintf("%d of %d", len(onScreen.Events), len(page.Pool))
+		showing := "Displaying " + count + " " + poolNouns[page.Kind]
+		if used+2+len(showing) > 79 {
+			showing = count
+		}
+		if used+2+len(showing) <= 79 {
+			MoveCursor(80-len(showing), 21)
+			write(CyanHi + showing + Reset)
+		}
+	}
+
+	MoveCursor(1, 22)
+	write(cfg.rule(footerRule))
+}
@@ -0,0 +1,81 @@
+package terminal
+
+import "strings"
+
+// Header is the art at the top of the door's screens, around the page's
+// title line: three lines above it and, optionally, one under it. The zero
+// Header is the door's own.
+type Header struct {
+	// Lines are the art, in CP437 as ANSI art files are, or for a built-in
+	// header written with "-" for each rule cell.
+	Lines   []string
+	builtin bool
+}
+
+// doorHeader is the door's own header.
+var doorHeader = Header{Lines: []string{headerRuleTop, headerTitle, headerRuleMid, headerRuleBottom}, builtin: true}
+
+// Headers are the built-in headers for special days, by name.
+var Headers = map[string]Header{
+	"new-year":  festiveHeader(YellowHi, WhiteHi, BgBlueHi, "*** Happy New Year! ***"),
+	"halloween": festiveHeader(Yellow, MagentaHi, BgRed, "~~ Happy Halloween ~~"),
+	"christmas": festiveHeader(RedHi, GreenHi, BgGreen, "*** Merry Christmas ***"),
+}
+
+// festiveHeader is the door's header with its rules in two other colors,
+// its title on bg, and a greeting after it.
+func festiveHeader(first, second, bg, greeting string) Header {
+	recolor := strings.NewReplacer(CyanHi, first, GreenHi, second)
+	title := bg + WhiteHi + ">> " + second + "Glimpse In Time v1.1  " + Reset + bg + BlackHi + ">>" + BgBlack + second + ">>  " + Reset +
+		WhiteHi + "by " + first + "<" + WhiteHi + "PHEN0M" + Reset + first + ">" + Reset + "   " + first + greeting + Reset
+	return Header{Lines: []string{
+		recolor.Replace(headerRuleTop),
+		title,
+		recolor.Replace(headerRuleMid),
+		recolor.Replace(headerRuleBottom),
+	}, builtin: true}
+}
+
+// headerArtWidth is the most columns a line of header art may take, after
+// the space the screens start each line with.
+const headerArtWidth = 78
+
+// LoadHeader reads header art from the ANSI art file at path, in CP437:
+// the first three lines go above the page's title line and a fourth, if
+// there is one, under it. It is read as art for a screen is, but a line
+// wider than 78 columns is an error.
+func LoadHeader(path string) (Header, error) {
+	lines, err := readArt(path, 4, headerArtWidth)
+	if err != nil {
+		return Header{}, err
+	}
+	for len(lines) < 3 {
+		lines = append(lines, "")
+	}
+	return Header{Lines: lines}, nil
+}
+
+// headerLines returns the header as drawn for the caller: the door's own,
+// its rules in the theme's accents, for any lines it leaves out, or in
+// full when the caller's character set can't show it.
+func (cfg TerminalConfig) headerLines() []string {
+	h, c := cfg.Header, cfg.Charset
+	if !h.builtin && c != CP437 && !c.unicode() {
+		h = Header{}
+	}
+	lines := make([]string, len(doorHeader.Lines))
+	for i := range lines {
+		switch {
+		case i >= len(h.Lines) && doorHeader.Lines[i] == headerTitle:
+			// The title keeps the door's colors, which its background needs
+			lines[i] = headerTitle
+		case i >= len(h.Lines):
+			lines[i] = cfg.rule(doorHeader.Lines[i])
+		case h.builtin:
+			lines[i] = c.rule(h.Lines[i])
+		default:
+			lines[i] = artLine(h.Lines[i], c)
+		}
+	}
+	return lines
+}
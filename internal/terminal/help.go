@@ -0,0 +1,45 @@
+package terminal
+
+import (
+	"strings"
+)
+
+// HelpEntry is one line of the help screen.
+type HelpEntry struct {
+	Keys []string
+	Text string
+}
+
+// RenderHelp draws the key help screen from the active bindings.
+func RenderHelp(cfg TerminalConfig, entries []HelpEntry) {
+	screen.Lock()
+	defer screen.Unlock()
+	frame(cfg, func() { renderHelp(cfg, entries) })
+}
+
+func renderHelp(cfg TerminalConfig, entries []HelpEntry) {
+	ClearScreen()
+	write("\r\n " + cfg.rule(headerRuleTop))
+	write("\r\n " + BgRed + BlackHi + ">>" + BgBlack + " " + YellowHi + "KEYS" + Reset + RedHi + " :: " + Reset + "what each key does" + Reset)
+	write("\r\n " + cfg.rule(headerRuleMid))
+
+	row := 6
+	for _, e := range entries {
+		if len(e.Keys) == 0 || row > 19 {
+			continue
+		}
+		keys := strings.Join(e.Keys, " ")
+		if len(keys) > 28 {
+			keys = keys[:28]
+		}
+		MoveCursor(1, row)
+		writef("   %s%-28s%s  %s%s%s", WhiteHi, keys, Reset, GreenHi, e.Text, Reset)
+		row++
+	}
+	MoveCursor(4, row+1)
+	write(BlackHi + "Any other key moves on to the next screen." + Reset)
+
+	MoveCursor(1, 24)
+	write(anyKey("GO BACK"))
+	redrawStatus(cfg)
+}
@@ -0,0 +1,113 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Screen is what a caller's 80x25 terminal shows after some output, cell by
+// cell, as the door's own screen model reads it. It is for checking the
+// renderers by how their output looks: that nothing runs past column 80,
+// that the footer is where it belongs, that colors are reset at the end.
+// Checks like that survive harmless changes to the bytes, such as a color
+// set in a different order, which comparing output byte for byte does not.
+type Screen struct {
+	v vscreen
+}
+
+// NewScreen returns the screen a terminal showing charset shows once p is
+// written to it from blank.
+func NewScreen(charset Charset, p []byte) *Screen {
+	s := &Screen{}
+	s.v.reset(charset.unicode())
+	s.v.apply(p)
+	return s
+}
+
+// Inspect draws page as RenderEvents would, without sending it, and returns
+// the screen the caller would see.
+func Inspect(cfg TerminalConfig, page Page) *Screen {
+	return NewScreen(cfg.Charset, Draw(cfg, page))
+}
+
+// Row returns the text on row y, counted from 1, without trailing blanks.
+func (s *Screen) Row(y int) string {
+	if y < 1 || y > screenRows {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range s.v.cells[y-1] {
+		b.WriteString(c.ch)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// Colored reports whether the cell at column x, row y, both counted from 1,
+// has any color or intensity set.
+func (s *Screen) Colored(x, y int) bool {
+	if x < 1 || x > screenCols || y < 1 || y > screenRows {
+		return false
+	}
+	return s.v.cells[y-1][x-1].a != defaultAttr
+}
+
+// Scrolled reports whether the output made the terminal scroll, losing its
+// top row.
+func (s *Screen) Scrolled() bool { return s.v.scrolled }
+
+// Overflowed reports whether any text ran past the last column and wrapped.
+func (s *Screen) Overflowed() bool { return s.v.overflowed }
+
+// Understood reports whether every escape sequence in the output is one the
+// model knows, so the rest of what it says can be trusted.
+func (s *Screen) Understood() bool { return !s.v.unknown }
+
+// Reset reports whether the output ends with colors back at the terminal's
+// defaults, so whatever the BBS writes next isn't tinted.
+func (s *Screen) Reset() bool { return s.v.a == defaultAttr && len(s.v.pendingBytes) == 0 }
+
+// Rows of the screen's fixed furniture.
+const (
+	footerTopRow    = 20
+	footerRow       = 21
+	footerBottomRow = 22
+	promptRow       = 24
+)
+
+// Verify draws page and checks what any screen of the door should hold to:
+// nothing past column 80 and no scrolling, only sequences the model knows,
+// colors reset at the end, the footer's dividers and dated line on rows 20
+// to 22, and a prompt on row 24. It returns what is wrong, or nil.
+func Verify(cfg TerminalConfig, page Page) error {
+	return Inspect(cfg, page).verify()
+}
+
+// verify checks s for Verify.
+func (s *Screen) verify() error {
+	var errs []error
+	if !s.Understood() {
+		errs = append(errs, errors.New("writes an escape sequence the screen model doesn't know"))
+	}
+	if s.Overflowed() {
+		errs = append(errs, errors.New("text runs past column 80"))
+	}
+	if s.Scrolled() {
+		errs = append(errs, errors.New("the screen scrolls"))
+	}
+	if !s.Reset() {
+		errs = append(errs, errors.New("colors are not reset at the end"))
+	}
+	for _, row := range []int{footerTopRow, footerBottomRow} {
+		if s.Row(row) == "" || !s.Colored(2, row) {
+			errs = append(errs, fmt.Errorf("row %d is not a footer divider: %q", row, s.Row(row)))
+		}
+	}
+	if !strings.HasPrefix(s.Row(footerRow), " >> Generated on ") {
+		errs = append(errs, fmt.Errorf("row %d is not the footer: %q", footerRow, s.Row(footerRow)))
+	}
+	if strings.TrimSpace(s.Row(promptRow)) == "" {
+		errs = append(errs, fmt.Errorf("row %d has no prompt", promptRow))
+	}
+	return errors.Join(errs...)
+}
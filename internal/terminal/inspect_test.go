@@ -0,0 +1,102 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robbiew/history/internal/era"
+)
+
+// testDay is a leap day, so the events screen draws its leap day note too.
+var testDay = time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+func testEvents() []Event {
+	var events []Event
+	for i, year := range []int{-44, 1066, 1492, 1776, 1969, 2001} {
+		title := fmt.Sprintf("Article_%d", i)
+		events = append(events, Event{
+			Year:  year,
+			Text:  strings.Repeat("Kraków and Zürich sign a treaty that takes a while to describe. ", i%3+1),
+			Era:   era.Of(year),
+			Title: title,
+			URL:   "https://en.wikipedia.org/wiki/" + title,
+		})
+	}
+	return events
+}
+
+// testPages are a page of each kind the door draws, with the command bar.
+func testPages(cfg TerminalConfig) map[string]Page {
+	events := testEvents()
+	bar := []Command{{Key: "N", Label: "Next"}, {Numbered: []string{"1", "2", "3", "4", "5"}, Label: "Detail"}, {Key: "Q", Label: "Quit"}}
+	lines := ListLines(cfg, KindEvents, events)
+	return map[string]Page{
+		"events":   {Kind: KindEvents, Date: testDay, Events: events[:5], Pool: events, Commands: bar},
+		"births":   {Kind: KindBirths, Date: testDay, Events: events[:5], Pool: events, Commands: bar},
+		"detail":   {Kind: KindDetail, Date: testDay, Events: events[2:3], Title: FormatYear(events[2].Year)},
+		"more":     {Kind: KindMore, Title: KindEvents, Date: testDay, Events: events, Lines: lines[:min(len(lines), ListRows(cfg))]},
+		"featured": {Kind: KindFeatured, Date: testDay, Events: events[4:5]},
+		"plugin":   {Kind: KindPlugin, Date: testDay, Title: "Plugin", Lines: strings.Split(strings.Repeat("A line drawn by a plugin\n", 14), "\n")},
+	}
+}
+
+func TestVerifyPages(t *testing.T) {
+	for _, charset := range Charsets {
+		for _, links := range []bool{false, true} {
+			cfg := TerminalConfig{Charset: charset, ShowLinks: links}
+			for name, page := range testPages(cfg) {
+				if err := Verify(cfg, page); err != nil {
+					t.Errorf("%s page in %s, links %v: %v", name, charset, links, err)
+				}
+			}
+		}
+	}
+}
+
+func TestInspectEvents(t *testing.T) {
+	cfg := TerminalConfig{Charset: UTF8, ShowLinks: true}
+	s := Inspect(cfg, testPages(cfg)["events"])
+	if s.Overflowed() {
+		t.Error("text runs past column 80")
+	}
+	for y := 1; y <= screenRows; y++ {
+		if w := len([]rune(s.Row(y))); w > screenCols {
+			t.Errorf("row %d is %d columns wide: %q", y, w, s.Row(y))
+		}
+	}
+	if got := s.Row(footerRow); !strings.HasPrefix(got, " >> Generated on ") {
+		t.Errorf("row %d = %q, want the footer", footerRow, got)
+	}
+	if !s.Reset() {
+		t.Error("colors are not reset at the end")
+	}
+	if got := s.Row(promptRow); !strings.Contains(got, "[1-") || !strings.Contains(got, "] Detail") {
+		t.Errorf("row %d = %q, want the detail keys for the events drawn", promptRow, got)
+	}
+}
+
+// TestVerifyFails checks verify catches screens that break each rule.
+func TestVerifyFails(t *testing.T) {
+	footer := Esc + "20;1H" + Esc + "1;30m ----" + Esc + "21;1H >> Generated on today" +
+		Esc + "22;1H ----" + Esc + "0m" + Esc + "24;1H Press a key"
+	for _, tt := range []struct {
+		name string
+		out  string
+		want string
+	}{
+		{"too wide", Esc + "8;1H" + strings.Repeat("x", 81) + footer, "past column 80"},
+		{"not reset", footer + Esc + "1;31m", "not reset"},
+		{"footer moved", strings.Replace(footer, "21;1H", "19;1H", 1), "is not the footer"},
+		{"no prompt", strings.TrimSuffix(footer, " Press a key"), "no prompt"},
+	} {
+		err := NewScreen(ASCII, []byte(tt.out)).verify()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: verify() = %v, want an error saying %q", tt.name, err, tt.want)
+		}
+	}
+	if err := NewScreen(ASCII, []byte(footer)).verify(); err != nil {
+		t.Errorf("verify() of a good screen = %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package terminal
+
+import "github.com/robbiew/history/pkg/textwrap"
+
+// Room for a plugin page's lines: rows 8-19, less a margin column.
+const (
+	LinesWidth  = 78
+	LinesHeight = 12
+)
+
+// renderLines fills the event area with a plugin page's lines, dropping
+// those past the last row and cutting each to LinesWidth.
+func renderLines(page Page) {
+	onScreen = page
+	for i, line := range page.Lines {
+		if i >= LinesHeight {
+			break
+		}
+		line = textwrap.Fit(line, LinesWidth)
+		MoveCursor(1, 8+i)
+		write(" " + WhiteHi + line + Reset)
+	}
+}
+
+// renderNotice fills the event area with a notice page's paragraphs,
+// wrapped to LinesWidth: the first, its headline, in yellow and the rest
+// in white. Text past the last row is cut, marked with an ellipsis.
+func renderNotice(page Page) {
+	onScreen = page
+	var lines []string
+	headline := 0
+	for i, para := range page.Lines {
+		wrapped := textwrap.Wrap(para, LinesWidth)
+		lines = append(lines, wrapped...)
+		if i == 0 {
+			headline = len(wrapped)
+		}
+	}
+	for i, line := range clipLines(lines, LinesHeight, LinesWidth) {
+		color := WhiteHi
+		if i < headline {
+			color = YellowHi
+		}
+		MoveCursor(1, 8+i)
+		write(" " + color + line + Reset)
+	}
+}
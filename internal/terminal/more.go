@@ -0,0 +1,58 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// ListLines formats events as a continuation page lists them: the year and
+// era badge, or a bullet for holidays and news, with the text wrapped
+// beside it and a blank line after each event.
+func ListLines(cfg TerminalConfig, kind string, events []Event) []string {
+	bulleted := kind == KindHolidays || kind == KindNews
+	yearWidth := cfg.Theme.yearWidth()
+	for _, e := range events {
+		yearWidth = max(yearWidth, len(FormatYear(e.Year)))
+	}
+
+	var lines []string
+	for _, e := range events {
+		prefix, indent := " "+YellowHi+"*"+Reset+" ", 3
+		if !bulleted {
+			prefix = " " + cfg.yearPrefix(e, yearWidth)
+			indent = textwrap.Width(prefix)
+		}
+		first, rest := strings.Repeat(" ", indent), strings.Repeat(" ", cfg.Theme.hang(indent, 1, 77))
+		o := textwrap.Options{Width: 77, Indent: first, Hang: rest, Justify: cfg.Theme.Justify}
+		for i, text := range o.Wrap(strings.TrimSpace(e.Text)) {
+			if i == 0 {
+				text = prefix + WhiteHi + strings.TrimPrefix(text, first)
+			} else {
+				text = rest + WhiteHi + strings.TrimPrefix(text, rest)
+			}
+			lines = append(lines, text+Reset)
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// ListRows is how many lines of a continuation page fit on screen at once.
+func ListRows(cfg TerminalConfig) int {
+	return 12 - len(taglineLines(cfg, 76)) // rows 8-19
+}
+
+// renderMore fills the event area with a continuation page's Lines, which
+// are already formatted by ListLines and cut to ListRows.
+func renderMore(page Page, tagline []string) {
+	onScreen = page
+	for i, line := range page.Lines {
+		MoveCursor(1, 8+i)
+		write(line)
+	}
+	for i, line := range tagline {
+		MoveCursor(1, 20-len(tagline)+i)
+		write(line)
+	}
+}
@@ -0,0 +1,82 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Line endings for SetLineEnding.
+const (
+	// CRLF is what BBS terminals expect: every line feed goes out with a
+	// carriage return before it.
+	CRLF = "crlf"
+	// LF sends bare line feeds, for a local terminal or a file that adds
+	// its own carriage returns.
+	LF = "lf"
+)
+
+// lineEnding is the caller's line ending, and codepage the regional code
+// page their output is encoded in, if any. The caller holds screen to use
+// them.
+var (
+	lineEnding = CRLF
+	codepage   *charmap.Charmap
+)
+
+// SetLineEnding makes everything sent to the caller from now on end its
+// lines with ending, CRLF or LF, whichever the renderers wrote.
+func SetLineEnding(ending string) {
+	screen.Lock()
+	defer screen.Unlock()
+	lineEnding = ending
+	connect()
+}
+
+// SetCharset makes everything sent to the caller from now on reach them in
+// c. Only the regional code pages need it: the renderers draw in Unicode
+// for them, and the output is encoded on the way.
+func SetCharset(c Charset) {
+	screen.Lock()
+	defer screen.Unlock()
+	codepage = c.Codepage()
+	connect()
+}
+
+// newlines wraps w so line endings written to it come out as ending. With
+// CRLF a lone LF gains its CR; with LF the CR of each CR LF is dropped, and
+// a CR on its own, which only returns the cursor, is left alone.
+func newlines(w io.Writer, ending string) io.Writer {
+	return &newlineWriter{w: w, lf: ending == LF}
+}
+
+type newlineWriter struct {
+	w      io.Writer
+	lf     bool
+	lastCR bool // the last byte written was a CR
+	buf    []byte
+}
+
+func (n *newlineWriter) Write(p []byte) (int, error) {
+	if !bytes.ContainsAny(p, "\r\n") {
+		n.lastCR = false
+		return n.w.Write(p)
+	}
+	n.buf = n.buf[:0]
+	for i, c := range p {
+		switch {
+		case c == '\n' && !n.lf && !n.lastCR:
+			n.buf = append(n.buf, '\r', '\n')
+		case c == '\r' && n.lf && i+1 < len(p) && p[i+1] == '\n':
+			// A CR LF split across writes keeps its CR, which does no harm
+		default:
+			n.buf = append(n.buf, c)
+		}
+		n.lastCR = c == '\r'
+	}
+	if _, err := n.w.Write(n.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,181 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// term buffers everything sent to the caller. It is flushed once a screen
+// or status update is complete, so a slow link never shows a half-drawn
+// frame and the socket gets a few large writes instead of many small ones.
+// Line endings are put right on the way out, as SetLineEnding says. The
+// caller holds screen to use it.
+var term = bufio.NewWriterSize(newlines(os.Stdout, CRLF), 16<<10)
+
+// sinks are where term's output goes: the caller, then any recordings.
+var sinks = []io.Writer{os.Stdout}
+
+// out is where the renderers write. Outside a frame it is the terminal,
+// through display so the screen model keeps up with status updates.
+var out io.Writer = display{}
+
+// shown models what the caller's terminal is showing, and spare is the
+// model the next frame is drawn into; the two swap after each frame. The
+// caller holds screen to use them.
+var shown, spare = newVScreen(false), newVScreen(false)
+
+// frameBuf collects each frame before it is sent, and patchBuf the changes
+// that turn the last one into it. Both are reused from frame to frame, so
+// drawing a screen doesn't allocate them again. The caller holds screen to
+// use them.
+var frameBuf, patchBuf bytes.Buffer
+
+// display writes to the terminal and applies the same bytes to shown.
+type display struct{}
+
+func (display) Write(p []byte) (int, error) {
+	shown.apply(p)
+	return term.Write(p)
+}
+
+// Stdout is the caller's terminal, for output the renderers don't draw
+// themselves, such as the loading animation and the goodbye message. It
+// shares the renderers' buffer, so call Flush once the output should be
+// seen. Anything written here makes the next screen a full redraw.
+var Stdout io.Writer = direct{}
+
+type direct struct{}
+
+func (direct) Write(p []byte) (int, error) {
+	screen.Lock()
+	defer screen.Unlock()
+	shown.valid = false
+	return term.Write(p)
+}
+
+// windowCols is the caller's width as last reported to Resize, zero until
+// then. The caller holds screen to use it.
+var windowCols int
+
+// Resize records that the caller's window is now cols by rows and makes the
+// next screen a full redraw, since the terminal may have reflowed or
+// cleared what it showed. Screens are still laid out in 80 columns,
+// centered in the new width if SetCentered was called; the status lines
+// are placed against the new width.
+func Resize(cols, rows int) {
+	screen.Lock()
+	defer screen.Unlock()
+	windowCols = cols
+	setMargin(cols)
+	shown.valid = false
+}
+
+// connect sends what is buffered and points term at the sinks, through the
+// palette, the line ending filter and the code page encoder, and for the
+// caller alone the centering. The caller holds screen.
+func connect() {
+	term.Flush()
+	w := io.MultiWriter(sinks...)
+	if centered {
+		w = io.MultiWriter(append([]io.Writer{&centerWriter{w: sinks[0]}}, sinks[1:]...)...)
+	}
+	if codepage != nil {
+		w = &encoder{w: w, cm: codepage}
+	}
+	w = newlines(w, lineEnding)
+	if len(palette) > 0 {
+		w = newPaletteWriter(w, palette)
+	}
+	term.Reset(w)
+}
+
+// Flush sends whatever is buffered for the terminal.
+func Flush() error {
+	screen.Lock()
+	defer screen.Unlock()
+	return term.Flush()
+}
+
+// SetOutput sends the caller's output to w, such as a serial port, instead
+// of stdout. Call it before anything is drawn.
+func SetOutput(w io.Writer) {
+	screen.Lock()
+	defer screen.Unlock()
+	sinks[0] = w
+	connect()
+}
+
+// Tee copies everything sent to the caller from now on to w as well. A
+// failing w fails the caller's output too, so w should swallow its errors.
+func Tee(w io.Writer) {
+	screen.Lock()
+	defer screen.Unlock()
+	sinks = append(sinks, w)
+	connect()
+}
+
+// frame runs draw, which paints a whole screen starting with a clear, and
+// sends the terminal only the cells that differ from what it already
+// shows. The full screen is sent instead when diffing is off, the model is
+// out of step, or the patch would be no smaller. Either way it goes out in
+// one flush. It returns the full screen's bytes, which are only good until
+// the next frame. The caller holds screen.
+func frame(cfg TerminalConfig, draw func()) []byte {
+	frameBuf.Reset()
+	out = &frameBuf
+	draw()
+	out = display{}
+
+	next := spare
+	next.reset(cfg.Charset.unicode())
+	next.apply(frameBuf.Bytes())
+	data := frameBuf.Bytes()
+	if cfg.ScreenDiff && shown.valid && next.valid {
+		if diffScreens(&patchBuf, shown, next, len(data)) {
+			data = patchBuf.Bytes()
+		}
+	}
+	term.Write(data)
+	term.Flush()
+	shown, spare = next, shown
+	return frameBuf.Bytes()
+}
+
+// drawBuf and drawScreen are Draw's own frame buffer and screen model.
+var (
+	drawBuf    bytes.Buffer
+	drawScreen = newVScreen(false)
+)
+
+// Draw returns the whole screen RenderEvents would send for page without
+// sending it, for timing the renderer outside a session. The bytes are only
+// good until the next Draw.
+func Draw(cfg TerminalConfig, page Page) []byte {
+	screen.Lock()
+	defer screen.Unlock()
+	saved := onScreen
+	defer func() { onScreen = saved }()
+	drawBuf.Reset()
+	out = &drawBuf
+	renderEvents(cfg, page)
+	out = display{}
+	drawScreen.reset(cfg.Charset.unicode())
+	drawScreen.apply(drawBuf.Bytes())
+	return drawBuf.Bytes()
+}
+
+// write sends parts to out one after another, which saves joining them
+// into one string first.
+func write(parts ...string) {
+	for _, s := range parts {
+		io.WriteString(out, s)
+	}
+}
+
+// writef formats to out.
+func writef(format string, a ...any) {
+	fmt.Fprintf(out, format, a...)
+}
@@ -0,0 +1,177 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Palette swaps colors the door draws for others, each by its name in
+// Colors, such as "bright-cyan" for "cyan". Backgrounds and the 256-color
+// year shades are left alone.
+type Palette map[string]string
+
+// Dim is the night palette: every bright color drawn in its normal shade,
+// but for bright black, the door's dark gray, which would vanish.
+var Dim = Palette{
+	"bright-red":     "red",
+	"bright-green":   "green",
+	"bright-yellow":  "yellow",
+	"bright-blue":    "blue",
+	"bright-magenta": "magenta",
+	"bright-cyan":    "cyan",
+	"bright-white":   "white",
+}
+
+// Then returns the palette that draws in p, then swaps the result as next
+// does.
+func (p Palette) Then(next Palette) Palette {
+	both := make(Palette)
+	for from := range Colors {
+		to := from
+		if c, ok := p[to]; ok {
+			to = c
+		}
+		if c, ok := next[to]; ok {
+			to = c
+		}
+		if to != from {
+			both[from] = to
+		}
+	}
+	return both
+}
+
+// basicColors are the eight ANSI colors, in SGR order.
+var basicColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// colorAttr returns the foreground color and intensity Colors names.
+func colorAttr(name string) (fg int16, bold, ok bool) {
+	base, bold := strings.CutPrefix(name, "bright-")
+	for i, c := range basicColors {
+		if c == base {
+			return int16(i), bold, true
+		}
+	}
+	return 0, false, false
+}
+
+// palette is the palette everything sent to the caller is drawn in, nil
+// for the door's own colors. The caller holds screen to use it.
+var palette Palette
+
+// SetPalette draws everything sent to the caller from now on in p's
+// colors, and recordings too; nil goes back to the door's own. The next
+// screen is drawn in full.
+func SetPalette(p Palette) {
+	screen.Lock()
+	defer screen.Unlock()
+	palette = p
+	shown.valid = false
+	connect()
+}
+
+// paletteWriter recolors the SGR sequences written to it on their way to
+// w. It follows the attribute the door set, as the screen model does, and
+// writes whatever sets its recolored version in its place.
+type paletteWriter struct {
+	w       io.Writer
+	swap    map[[2]int16]attr // by foreground and intensity
+	drawn   attr              // the attribute the door set
+	sent    attr              // its recolored version, as sent
+	buf     []byte
+	sgr     bytes.Buffer
+	seq     []byte // an escape sequence split across writes
+	pending bool   // seq holds an unfinished sequence
+}
+
+func newPaletteWriter(w io.Writer, p Palette) *paletteWriter {
+	pw := &paletteWriter{w: w, swap: make(map[[2]int16]attr), drawn: defaultAttr, sent: defaultAttr}
+	for from, to := range p {
+		fg, bold, ok := colorAttr(from)
+		toFg, toBold, toOK := colorAttr(to)
+		if ok && toOK {
+			pw.swap[[2]int16{fg, boolInt(bold)}] = attr{fg: toFg, bold: toBold}
+		}
+	}
+	return pw
+}
+
+func boolInt(b bool) int16 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// recolor is a in the palette.
+func (pw *paletteWriter) recolor(a attr) attr {
+	if a.fg < 0 || a.fg >= extendedColor {
+		return a
+	}
+	if to, ok := pw.swap[[2]int16{a.fg, boolInt(a.bold)}]; ok {
+		a.fg, a.bold = to.fg, to.bold
+	}
+	return a
+}
+
+func (pw *paletteWriter) Write(p []byte) (int, error) {
+	if !pw.pending && bytes.IndexByte(p, 0x1b) < 0 {
+		return pw.w.Write(p)
+	}
+	pw.buf = pw.buf[:0]
+	for _, b := range p {
+		switch {
+		case pw.pending:
+			pw.seq = append(pw.seq, b)
+			if len(pw.seq) == 2 && b != '[' || len(pw.seq) > 2 && b >= 0x40 && b <= 0x7e {
+				pw.pending = false
+				pw.sequence()
+			}
+		case b == 0x1b:
+			pw.seq, pw.pending = append(pw.seq[:0], b), true
+		default:
+			pw.buf = append(pw.buf, b)
+		}
+	}
+	if _, err := pw.w.Write(pw.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sequence writes the escape sequence in seq, recolored if it is SGR.
+func (pw *paletteWriter) sequence() {
+	if len(pw.seq) < 3 || pw.seq[len(pw.seq)-1] != 'm' {
+		pw.buf = append(pw.buf, pw.seq...)
+		return
+	}
+	var params []int
+	ok := true
+	for _, f := range strings.Split(string(pw.seq[2:len(pw.seq)-1]), ";") {
+		n, err := strconv.Atoi(f)
+		if f == "" {
+			n, err = -1, nil
+		}
+		ok = ok && err == nil
+		params = append(params, n)
+	}
+	a := pw.drawn
+	if ok {
+		a, ok = pw.drawn.sgr(params)
+	}
+	if !ok {
+		// Sent as it is; what it set is unknown, so the next is sent whole
+		pw.buf = append(pw.buf, pw.seq...)
+		pw.sent = attr{fg: -2}
+		return
+	}
+	pw.drawn = a
+	if sent := pw.recolor(a); sent != pw.sent {
+		pw.sgr.Reset()
+		sent.writeSGR(&pw.sgr)
+		pw.buf = append(pw.buf, pw.sgr.Bytes()...)
+		pw.sent = sent
+	}
+}
@@ -0,0 +1,355 @@
+package terminal
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// Renderer turns the parts every output of a page shares into one format:
+// its title, its entries and the line saying when it was made. The layouts
+// (the events screen, the login banner, a saved screen and the digests)
+// decide which parts go where and how much room each gets; the Renderer
+// decides how they look. ANSI draws them as the door shows them, Plain as
+// bare text, and HTML as a web page.
+type Renderer interface {
+	// Begin and End open and close a whole document.
+	Begin() string
+	End() string
+	// Title is the line naming the page and its day.
+	Title(page Page) string
+	// Entry returns the lines of one of the page's entries, its text
+	// wrapped so that no line is wider than width.
+	Entry(e Event, style EntryStyle, width int) []string
+	// Footer is the line saying when the output was generated.
+	Footer(generated time.Time) string
+}
+
+// EntryStyle is how a page lays out its entries.
+type EntryStyle struct {
+	// YearWidth is the width of the year column. Bulleted pages, holidays
+	// and news, have a bullet instead, and Article pages a heading naming
+	// the entry's article.
+	YearWidth int
+	Bulleted  bool
+	Article   bool
+	// Number, above zero, is the entry's number for its detail key.
+	Number int
+	// Rows, above zero, is the most lines of text the entry may take; a
+	// cut is marked with "...".
+	Rows int
+	// Links adds the entry's article URL.
+	Links bool
+}
+
+// entryStyle returns how page lays out events, those that could be on it.
+func entryStyle(page Page, events []Event) EntryStyle {
+	kind := page.Kind
+	if kind == KindMore {
+		kind = page.Title
+	}
+	return EntryStyle{
+		YearWidth: yearColumnWidth(events, 4),
+		Bulleted:  kind == KindHolidays || kind == KindNews,
+		Article:   kind == KindFeatured || kind == KindDetail,
+	}
+}
+
+// heading names an article entry: its article's title, after its year if
+// it has one.
+func heading(e Event) string {
+	if e.Year == 0 {
+		return e.Title
+	}
+	return strings.TrimSuffix(FormatYear(e.Year)+": "+e.Title, ": ")
+}
+
+// AnniversaryTag is how a round anniversary of years is called out.
+func AnniversaryTag(years int) string {
+	return strconv.Itoa(years) + " YEARS AGO TODAY"
+}
+
+// tagged reports whether e's entry calls out its anniversary: a dated entry
+// in a list, not an article or a bulleted one.
+func tagged(e Event, style EntryStyle) bool {
+	return e.Anniversary > 0 && !style.Article && !style.Bulleted
+}
+
+// entryText wraps e's text into lines as o says, clipped to style.Rows,
+// less the row an anniversary tag takes.
+func entryText(e Event, style EntryStyle, o textwrap.Options) []string {
+	lines := o.Wrap(strings.TrimSpace(e.Text))
+	if rows := style.Rows; rows > 0 {
+		if tagged(e, style) {
+			rows = max(rows-1, 1)
+		}
+		lines = clipLines(lines, rows, o.Width)
+	}
+	return lines
+}
+
+// Document writes pages to w in r's format as one document: each page's
+// title and entries, lines no wider than width where the format has
+// lines, and a footer dated generated.
+func Document(w io.Writer, r Renderer, pages []Page, generated time.Time, width int) error {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString("\n")
+	}
+	if s := r.Begin(); s != "" {
+		line(s)
+	}
+	for _, page := range pages {
+		line(r.Title(page))
+		line("")
+		style := entryStyle(page, page.Events)
+		style.Links = true
+		for _, e := range page.Events {
+			for _, l := range r.Entry(e, style, width) {
+				line(l)
+			}
+			line("")
+		}
+	}
+	line(r.Footer(generated))
+	if s := r.End(); s != "" {
+		line(s)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ANSI draws in the door's own colors and Config's charset and locale,
+// as the events screen and the banner show it.
+type ANSI struct {
+	Config TerminalConfig
+}
+
+// Begin clears the screen and homes the cursor.
+func (r ANSI) Begin() string { return Reset + EraseScreen + Esc + "1;1H" }
+
+// End adds nothing.
+func (r ANSI) End() string { return "" }
+
+// Title is the header row under the door's title art.
+func (r ANSI) Title(page Page) string {
+	return titleLine(page, r.Config.Locale)
+}
+
+// Entry writes the entry's number, if it has one, then its year and era
+// badge or a bullet, and its text beside them, wrapping as the theme says;
+// a pinned entry is highlighted, and a round anniversary tagged above its
+// text. Articles are drawn by their own screens and get only text.
+func (r ANSI) Entry(e Event, style EntryStyle, width int) []string {
+	number, text := WhiteHi, WhiteHi
+	if e.Pinned {
+		number, text = BgBlueHi+YellowHi, YellowHi
+	}
+	var lead strings.Builder
+	indent := 0
+	if style.Number > 0 {
+		lead.WriteString(" " + number + strconv.Itoa(style.Number) + Reset)
+		indent += 2
+	}
+	year := indent + 1 // where the year column or bullet starts
+	switch {
+	case style.Article:
+	case style.Bulleted:
+		lead.WriteString(" " + YellowHi + "*" + Reset + " ")
+		indent += 3
+	default:
+		prefix := r.Config.yearPrefix(e, style.YearWidth)
+		lead.WriteString(" " + prefix)
+		indent += 1 + textwrap.Width(prefix)
+		if r.Config.CategoryTags {
+			// Every entry gets the room, so the text lines up with or without one
+			badge := fmt.Sprintf("%-3s ", e.Category.Badge)
+			lead.WriteString(categoryColors[e.Category.Name] + badge + Reset)
+			indent += len(badge)
+		}
+	}
+	hang := indent
+	if !style.Article {
+		hang = r.Config.Theme.hang(indent, year, width)
+	}
+	first, rest := strings.Repeat(" ", indent), strings.Repeat(" ", hang)
+	var lines []string
+	if tagged(e, style) {
+		lines = append(lines, lead.String()+BgRed+WhiteHi+" "+AnniversaryTag(e.Anniversary)+" "+Reset)
+	}
+	o := textwrap.Options{Width: width, Indent: first, Hang: rest, Justify: r.Config.Theme.Justify}
+	for i, l := range entryText(e, style, o) {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		l = strings.TrimPrefix(l, prefix)
+		if len(lines) == 0 {
+			prefix = lead.String()
+		}
+		lines = append(lines, prefix+text+l+Reset)
+	}
+	return lines
+}
+
+// Footer is the banner's dated footer row.
+func (r ANSI) Footer(generated time.Time) string {
+	return " " + BgRed + BlackHi + ">>" + BgBlack + " " + WhiteHi + "Updated " + r.Config.Locale.FullDate(generated) + " " + Reset
+}
+
+// Plain writes bare text with its dates in Locale, as a saved screen and
+// the plain digest are.
+type Plain struct {
+	Locale *locale.Locale
+}
+
+// plainTitles name each page kind in plain text.
+var plainTitles = map[string]string{
+	KindEvents:   "Events",
+	KindBirths:   "Births",
+	KindDeaths:   "Deaths",
+	KindHolidays: "Holidays and observances",
+	KindFeatured: "Featured article",
+	KindNews:     "In the news",
+	KindDetail:   "A closer look",
+}
+
+// Begin adds nothing.
+func (r Plain) Begin() string { return "" }
+
+// End adds nothing.
+func (r Plain) End() string { return "" }
+
+// Title names the page's kind and day; a plugin's page and a notice are
+// named by their own titles, and a birth year page by its day and year.
+func (r Plain) Title(page Page) string {
+	day := r.Locale.Day(page.Date)
+	switch page.Kind {
+	case KindPlugin, KindNotice:
+		return page.Title + ", " + day
+	case KindMore:
+		return "This Day in History: " + plainTitles[page.Title] + ", " + day
+	case KindBirthYear:
+		return "This Day in History: " + day + ", " + page.Title
+	case "":
+		return "This Day in History: " + plainTitles[KindEvents] + ", " + day
+	}
+	return "This Day in History: " + plainTitles[page.Kind] + ", " + day
+}
+
+// Entry writes the year, or a bullet, and the text beside it, with the
+// article's URL under the text. An article is its heading and text.
+func (r Plain) Entry(e Event, style EntryStyle, width int) []string {
+	if style.Article {
+		lines := append([]string{heading(e), ""}, entryText(e, style, textwrap.Options{Width: width})...)
+		if style.Links && e.URL != "" {
+			lines = append(lines, "", e.URL)
+		}
+		return lines
+	}
+	indent, lead := style.YearWidth+2, fmt.Sprintf("%*s  ", style.YearWidth, FormatYear(e.Year))
+	if style.Bulleted {
+		indent, lead = 2, "* "
+	}
+	pad := strings.Repeat(" ", indent)
+	lines := entryText(e, style, textwrap.Options{Width: width - indent})
+	if tagged(e, style) {
+		lines = append([]string{AnniversaryTag(e.Anniversary)}, lines...)
+	}
+	for i, l := range lines {
+		if i == 0 {
+			lines[i] = lead + l
+		} else {
+			lines[i] = pad + l
+		}
+	}
+	if style.Links && e.URL != "" {
+		lines = append(lines, pad+e.URL)
+	}
+	return lines
+}
+
+// Footer says when the text was made.
+func (r Plain) Footer(generated time.Time) string {
+	return "Updated " + r.Locale.FullDate(generated)
+}
+
+// HTML writes a web page with its dates in Locale, each year colored for
+// its era as on screen. Lines are left for the browser to wrap. Site is the
+// home page of the Wikipedia edition credited in the footer; it defaults to
+// English Wikipedia.
+type HTML struct {
+	Locale *locale.Locale
+	Site   string
+}
+
+// cssColors are the web colors of the ANSI colors the eras use, from the
+// VGA palette BBS terminals show.
+var cssColors = map[string]string{
+	Yellow:    "#aa5500",
+	MagentaHi: "#ff55ff",
+	RedHi:     "#ff5555",
+	CyanHi:    "#55ffff",
+	GreenHi:   "#55ff55",
+}
+
+// Begin opens the page, in light-on-dark colors like the door's.
+func (r HTML) Begin() string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>This Day in History</title>
+<style>
+body { background: #000; color: #aaa; font-family: monospace; max-width: 80ch; margin: 1em auto; }
+h2, h3 { color: #ffff55; }
+a { color: #55ffff; }
+.badge { color: #55ffff; }
+footer { color: #fff; border-top: 1px solid #555; padding-top: .5em; }
+</style>
+</head>
+<body>`
+}
+
+// End closes the page.
+func (r HTML) End() string { return "</body>\n</html>" }
+
+// Title is a heading naming the page's kind and day.
+func (r HTML) Title(page Page) string {
+	return "<h2>" + html.EscapeString(Plain{Locale: r.Locale}.Title(page)) + "</h2>"
+}
+
+// Entry is one paragraph: the year, colored for its era, and its badge, or
+// a bullet, then the text and a link to the article. An article gets a
+// heading of its own.
+func (r HTML) Entry(e Event, style EntryStyle, width int) []string {
+	text := html.EscapeString(strings.TrimSpace(e.Text))
+	link := ""
+	if style.Links && e.URL != "" {
+		link = ` <a href="` + html.EscapeString(e.URL) + `">` + html.EscapeString(cmp.Or(e.Title, e.URL)) + "</a>"
+	}
+	switch {
+	case style.Article:
+		return []string{"<h3>" + html.EscapeString(heading(e)) + "</h3>", "<p>" + text + link + "</p>"}
+	case style.Bulleted:
+		return []string{"<p>&bull; " + text + link + "</p>"}
+	}
+	if tagged(e, style) {
+		text = "<strong>" + AnniversaryTag(e.Anniversary) + "</strong> " + text
+	}
+	color := cssColors[eraColor(e.Era)]
+	return []string{`<p><span style="color: ` + color + `">` + html.EscapeString(FormatYear(e.Year)) + `</span> <span class="badge">&lt;<span style="color: ` + color + `">` + e.Era.Badge + `</span>&gt;</span> ` + text + link + "</p>"}
+}
+
+// Footer says when the page was made and where its text comes from.
+func (r HTML) Footer(generated time.Time) string {
+	return `<footer>Updated ` + html.EscapeString(r.Locale.FullDate(generated)) + `. From <a href="` + html.EscapeString(cmp.Or(r.Site, "https://en.wikipedia.org/")) + `">Wikipedia</a>, available under <a href="https://creativecommons.org/licenses/by-sa/4.0/">CC BY-SA 4.0</a>.</footer>`
+}
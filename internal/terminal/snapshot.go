@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/robbiew/history/internal/locale"
+)
+
+// onScreen is the page RenderEvents last drew, holding only the events that
+// fit, lastFrame the bytes that drew it in full, and onScreenLocale the
+// locale its dates were in. The caller holds screen.
+var (
+	onScreen       Page
+	lastFrame      []byte
+	onScreenLocale *locale.Locale
+)
+
+// Snapshot returns the screen RenderEvents last drew, for the caller to keep.
+// As ANSI it is the whole screen exactly as sent; as text it is the date and
+// the events that fit on it, with their articles, in CRLF lines. It returns
+// nil before the first page is drawn.
+func Snapshot(ansi bool) []byte {
+	screen.Lock()
+	defer screen.Unlock()
+	if lastFrame == nil {
+		return nil
+	}
+	if ansi {
+		return append([]byte(nil), lastFrame...)
+	}
+	return []byte(snapshotText(onScreen, onScreenLocale))
+}
+
+// snapshotText writes page as plain text, with its dates in loc. A
+// continuation page is saved like the list it continues, with every event
+// added so far.
+func snapshotText(page Page, loc *locale.Locale) string {
+	r := Plain{Locale: loc}
+	var b strings.Builder
+	b.WriteString(r.Title(page) + "\r\n\r\n")
+	if page.Kind == KindPlugin || page.Kind == KindNotice {
+		for _, line := range page.Lines {
+			b.WriteString(line + "\r\n")
+		}
+		return b.String()
+	}
+	style := entryStyle(page, page.Events)
+	style.Links = true
+	for _, e := range page.Events {
+		for _, line := range r.Entry(e, style, 78) {
+			b.WriteString(line + "\r\n")
+		}
+		if !style.Article {
+			b.WriteString("\r\n")
+		}
+	}
+	return b.String()
+}
+
+// Shown returns the events on the page RenderEvents last drew, in the order
+// they are numbered on screen.
+func Shown() []Event {
+	screen.Lock()
+	defer screen.Unlock()
+	return onScreen.Events
+}
@@ -0,0 +1,96 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// StatusRow is the blank row above the header where clocks and notices go.
+	StatusRow = 1
+	// FooterStatusRow is the lower footer divider; its right end carries the
+	// caller's BBS time.
+	FooterStatusRow = 22
+)
+
+// statusLine is a row of right-aligned status texts, one per named slot.
+type statusLine struct {
+	row   int
+	text  map[string]string
+	order []string // slots in the order first set
+	drawn int      // width last drawn, so a shorter text blanks what's left
+}
+
+var (
+	// screen serializes drawing between the page renderers and status
+	// updates, which arrive from their own goroutines.
+	screen sync.Mutex
+
+	topStatus    = &statusLine{row: StatusRow, text: map[string]string{}}
+	footerStatus = &statusLine{row: FooterStatusRow, text: map[string]string{}}
+)
+
+// SetStatus sets the text for slot on the status row above the header and
+// redraws it. An empty text clears the slot. Slots are shown right-aligned,
+// in the order first set.
+func SetStatus(cfg TerminalConfig, slot, text string) {
+	screen.Lock()
+	defer screen.Unlock()
+	topStatus.set(slot, text)
+	topStatus.draw(cfg)
+	term.Flush()
+}
+
+// SetFooterStatus is SetStatus for the right end of the footer.
+func SetFooterStatus(cfg TerminalConfig, slot, text string) {
+	screen.Lock()
+	defer screen.Unlock()
+	footerStatus.set(slot, text)
+	footerStatus.draw(cfg)
+	term.Flush()
+}
+
+func (l *statusLine) set(slot, text string) {
+	if _, ok := l.text[slot]; !ok {
+		l.order = append(l.order, slot)
+	}
+	l.text[slot] = text
+}
+
+// draw writes the line in one write, saving and restoring the cursor so
+// whatever else is on screen is left undisturbed. The caller holds screen.
+func (l *statusLine) draw(cfg TerminalConfig) {
+	var parts []string
+	for _, slot := range l.order {
+		if l.text[slot] != "" {
+			parts = append(parts, l.text[slot])
+		}
+	}
+	text := strings.Join(parts, "  ")
+	width := max(len(text), l.drawn)
+	if width == 0 {
+		return
+	}
+	cols := cfg.Cols
+	if windowCols > 0 {
+		cols = windowCols
+	}
+	if cols <= 0 || cols > 80 {
+		cols = 80
+	}
+	// Stop short of the last column so no terminal scrolls
+	col := max(cols-width, 1)
+	write(Esc + "s" + Esc + fmt.Sprintf("%d;%df", l.row, col) +
+		CyanHi + fmt.Sprintf("%*s", width, text) + Reset + Esc + "u")
+	l.drawn = len(text)
+}
+
+// redrawStatus puts both status lines back after the screen was cleared.
+// The caller holds screen.
+func redrawStatus(cfg TerminalConfig) {
+	for _, l := range []*statusLine{topStatus, footerStatus} {
+		l.drawn = 0
+		l.draw(cfg)
+	}
+}
@@ -2,8 +2,14 @@ package terminal
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/robbiew/history/internal/category"
+	"github.com/robbiew/history/internal/era"
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/pkg/textwrap"
 )
 
 const (
@@ -11,10 +17,14 @@ const (
 	EraseScreen = Esc + "2J"
 	Reset       = Esc + "0m"
 
+	Yellow    = Esc + "33m"
 	BlackHi   = Esc + "30;1m"
 	RedHi     = Esc + "31;1m"
 	GreenHi   = Esc + "32;1m"
 	YellowHi  = Esc + "33;1m"
+	BlueHi    = Esc + "34;1m"
+	MagentaHi = Esc + "35;1m"
+	Cyan      = Esc + "36m"
 	CyanHi    = Esc + "36;1m"
 	WhiteHi   = Esc + "37;1m"
 
@@ -33,157 +43,496 @@ type TerminalConfig struct {
 	Terminal string
 	Cols     int
 	Rows     int
+	// ShowLinks numbers each event and lists its Wikipedia article below the events.
+	ShowLinks bool
+	// Attribution names the Wikipedia articles the entries come from when
+	// ShowLinks is off, below the events and on detail pages.
+	Attribution bool
+	// CategoryTags shows each event's category badge after its era's.
+	CategoryTags bool
+	// Tagline and TaglineBy are the session's footer quote and its
+	// attribution; an empty Tagline shows none.
+	Tagline   string
+	TaglineBy string
+	// Clock24 shows times on a 24-hour clock instead of 12-hour with AM/PM.
+	Clock24 bool
+	// ScreenDiff sends only the cells that changed when one screen replaces
+	// another, instead of clearing and redrawing everything.
+	ScreenDiff bool
+	// Charset is what the dividers and loading bar are drawn in.
+	Charset Charset
+	// ExtendedPalette is set for terminals that show the 256-color palette.
+	ExtendedPalette bool
+	// GradientYears colors each year for its century rather than its era.
+	GradientYears bool
+	// Theme tunes the look of the entries.
+	Theme Theme
+	// Header is the art at the top of the screens.
+	Header Header
+	// Locale names the months and days in the header and footer dates, in
+	// Charset; nil is English.
+	Locale *locale.Locale
 }
 
 // Event represents the minimal event data the renderer requires.
 type Event struct {
 	Year int
 	Text string
+	// Key is the text in plain ASCII, the same whatever the charset, for
+	// telling events apart from one session to the next.
+	Key string
+	Era era.Era
+	// Title and URL are the event's primary Wikipedia article, if known.
+	Title string
+	URL   string
+	// Related are the titles of the other articles the event links to,
+	// listed on its detail page.
+	Related []string
+	// Pinned marks the sysop's Event of the Day, which is highlighted.
+	Pinned bool
+	// Category is the event's subject, if it has one the category package
+	// recognizes.
+	Category category.Category
+	// Anniversary is how many years ago the event was when that is a round
+	// number the screens call out, as "150 YEARS AGO TODAY"; 0 otherwise.
+	Anniversary int
+}
+
+// eraColors maps each era to the color used for its year column, badge and legend entry.
+var eraColors = map[string]string{
+	"Ancient":      Yellow,
+	"Medieval":     MagentaHi,
+	"Early Modern": RedHi,
+	"Modern":       CyanHi,
+	"Contemporary": GreenHi,
+}
+
+// categoryColors maps each category to the color of its badge.
+var categoryColors = map[string]string{
+	"war":      RedHi,
+	"science":  CyanHi,
+	"politics": YellowHi,
+	"sports":   GreenHi,
+	"culture":  MagentaHi,
+}
+
+// eraColor returns the door's own display color for an era, falling back to the classic cyan.
+func eraColor(e era.Era) string {
+	if c, ok := eraColors[e.Name]; ok {
+		return c
+	}
+	return CyanHi
+}
+
+// eraColor returns the display color for an era, the theme's if it gives
+// one.
+func (cfg TerminalConfig) eraColor(e era.Era) string {
+	if c, ok := Colors[cfg.Theme.Eras[e.Badge]]; ok {
+		return c
+	}
+	return eraColor(e)
+}
+
+// eraLegend builds the one-line footer legend of era badges and names, or
+// nothing when the theme hides it.
+func (cfg TerminalConfig) eraLegend() string {
+	if cfg.Theme.HideLegend {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(" ")
+	for i, e := range era.All {
+		if i > 0 {
+			b.WriteString(BlackHi + " : " + Reset)
+		}
+		b.WriteString(cfg.eraColor(e) + e.Badge + Reset + " " + e.Name)
+	}
+	return b.String()
 }
 
 func MoveCursor(x int, y int) {
-	fmt.Printf(Esc+"%d;%df", y, x)
+	write(Esc, strconv.Itoa(y), ";", strconv.Itoa(x), "f")
 }
 
 func ClearScreen() {
-	fmt.Print(EraseScreen)
+	write(EraseScreen)
 	MoveCursor(0, 0)
 }
 
-func getNumEndingLocal(n int) string {
-	if n%100 >= 11 && n%100 <= 13 {
-		return "th"
-	}
-	switch n % 10 {
-	case 1:
-		return "st"
-	case 2:
-		return "nd"
-	case 3:
-		return "rd"
+// FormatYear renders a year for display. Wikimedia encodes BC years as negative
+// numbers, so -44 becomes "44 BC"; very early AD years are marked "AD 33" so they
+// don't read as a truncated modern year.
+func FormatYear(year int) string {
+	switch {
+	case year <= 0:
+		// There is no year 0 in the calendar; the closest reading is 1 BC
+		if year == 0 {
+			return "1 BC"
+		}
+		return strconv.Itoa(-year) + " BC"
+	case year < 100:
+		return "AD " + strconv.Itoa(year)
 	default:
-		return "th"
+		return strconv.Itoa(year)
 	}
 }
 
-// wrapText breaks text into lines that fit within maxWidth (rune-aware).
-func wrapText(text string, maxWidth int) []string {
-	if maxWidth <= 0 {
-		return []string{text}
+// yearColumnWidth returns the width of the year column for the events that can
+// appear on screen, never narrower than width, the classic four digits unless
+// the theme says otherwise.
+func yearColumnWidth(events []Event, width int) int {
+	for i, e := range events {
+		if i >= 5 {
+			break
+		}
+		if w := len(FormatYear(e.Year)); w > width {
+			width = w
+		}
 	}
-	runes := []rune(text)
-	if len(runes) <= maxWidth {
-		return []string{text}
+	return width
+}
+
+// maxFootnoteRows caps how much of the event area the link list may take.
+const maxFootnoteRows = 3
+
+// footnoteLines packs the numbered article links for events into lines no wider
+// than width. URLs sharing the usual "host/wiki/" prefix are shortened to the
+// bare article path, with the prefix shown once on the first line.
+func footnoteLines(events []Event, width int) []string {
+	const lead = " Read more: "
+	prefix := ""
+	for _, e := range events {
+		if e.URL == "" {
+			continue
+		}
+		short := strings.TrimPrefix(strings.TrimPrefix(e.URL, "https://"), "http://")
+		if i := strings.Index(short, "/wiki/"); i >= 0 {
+			prefix = short[:i+len("/wiki/")]
+		}
+		break
 	}
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{""}
+	if prefix == "" {
+		return nil
 	}
-	var lines []string
-	var current []rune
-	for _, word := range words {
-		wr := []rune(word)
-		if len(current) == 0 {
-			if len(wr) <= maxWidth {
-				current = append(current, wr...)
-			} else {
-				if maxWidth > 3 {
-					lines = append(lines, string(wr[:maxWidth-3])+"...")
-				} else {
-					lines = append(lines, string(wr[:maxWidth]))
-				}
-			}
+
+	lines := []string{lead + CyanHi + prefix + Reset + BlackHi + "..." + Reset}
+	current, currentLen := "", 0
+	for i, e := range events {
+		if e.URL == "" {
 			continue
 		}
-		if len(current)+1+len(wr) <= maxWidth {
-			current = append(current, ' ')
-			current = append(current, wr...)
+		path := strings.TrimPrefix(strings.TrimPrefix(e.URL, "https://"), "http://")
+		path = strings.TrimPrefix(path, prefix)
+		label := fmt.Sprintf("[%d]", i+1)
+		entryLen := len(label) + 1 + len(path)
+		entry := CyanHi + label + Reset + " " + WhiteHi + path + Reset
+		if currentLen > 0 && currentLen+2+entryLen > width {
+			lines = append(lines, current)
+			current, currentLen = "", 0
+		}
+		if currentLen == 0 {
+			current, currentLen = " "+entry, 1+entryLen
 		} else {
-			lines = append(lines, string(current))
-			current = nil
-			if len(wr) <= maxWidth {
-				current = append(current, wr...)
-			} else {
-				if maxWidth > 3 {
-					lines = append(lines, string(wr[:maxWidth-3])+"...")
-				} else {
-					lines = append(lines, string(wr[:maxWidth]))
-				}
-			}
+			current += "  " + entry
+			currentLen += 2 + entryLen
+		}
+	}
+	if currentLen > 0 {
+		lines = append(lines, current)
+	}
+	if len(lines) > maxFootnoteRows {
+		lines = lines[:maxFootnoteRows]
+	}
+	return lines
+}
+
+// sourceLines names the Wikipedia article each of events comes from, by its
+// number, in lines no wider than width: the attribution without the links.
+func sourceLines(events []Event, width int) []string {
+	var lines []string
+	current, sep, any := " "+CyanHi+"From Wikipedia:"+Reset, " ", false
+	for i, e := range events {
+		if e.Title == "" {
+			continue
 		}
+		label := fmt.Sprintf("[%d]", i+1)
+		entry := CyanHi + label + Reset + " " + WhiteHi + textwrap.Cut(e.Title, width-len(label)-2) + Reset
+		if textwrap.Width(current+sep+entry) > width {
+			lines = append(lines, current)
+			current, sep = "", " "
+		}
+		current += sep + entry
+		sep, any = "  ", true
 	}
-	if len(current) > 0 {
-		lines = append(lines, string(current))
+	if !any {
+		return nil
 	}
-	if len(lines) == 0 {
-		return []string{""}
+	lines = append(lines, current)
+	if len(lines) > maxFootnoteRows {
+		lines = lines[:maxFootnoteRows]
 	}
 	return lines
 }
 
-// RenderEvents draws the header, events, and footer to the terminal.
+// notes are the lines below events naming their articles: the link list,
+// or the attribution when that is off.
+func (cfg TerminalConfig) notes(events []Event, width int) []string {
+	switch {
+	case cfg.ShowLinks:
+		return footnoteLines(events, width)
+	case cfg.Attribution:
+		return sourceLines(events, width)
+	}
+	return nil
+}
+
+// Page kinds, matching the feed sections they show.
+const (
+	KindEvents   = "events"
+	KindBirths   = "births"
+	KindDeaths   = "deaths"
+	KindHolidays = "holidays"
+	KindFeatured = "featured"
+	KindNews     = "news"
+	// KindPlugin pages are drawn by an external command: Title and Lines
+	// instead of events.
+	KindPlugin = "plugin"
+	// KindDetail pages show one event in full, with Title naming it in the
+	// header.
+	KindDetail = "detail"
+	// KindMore pages continue a list past what fit on its page: Title is
+	// the kind of page continued, Events every event listed so far, and
+	// Lines the part of ListLines on screen.
+	KindMore = "more"
+	// KindNotice pages stand in for the day's pages when there are none to
+	// show, saying why: Title is the header and Lines the paragraphs.
+	KindNotice = "notice"
+	// KindBirthYear pages list the day's entries from the year the caller
+	// was born, which Title holds.
+	KindBirthYear = "birthyear"
+)
+
+// Page is everything shown on one events screen.
+type Page struct {
+	// Kind selects the header wording; empty means KindEvents. Holidays and
+	// news have no year column, and a featured page shows its one event as an article.
+	Kind string
+	// Date is the day the events happened on; only month and day are shown.
+	Date   time.Time
+	Events []Event
+	// Pool is every entry fetched for the page, which Events were picked
+	// from; the more key lists the ones the caller hasn't seen.
+	Pool []Event
+	// CachedAt is set when the events come from an old cached copy because a
+	// fresh fetch failed; the footer then says when the copy was made.
+	CachedAt time.Time
+	// Commands fill the command bar on the prompt row; with none, the plain
+	// "press any key" prompt is shown.
+	Commands []Command
+	// Title and Lines are a KindPlugin page's header and text, shown as
+	// given apart from being cut to fit, or a KindNotice page's header and
+	// paragraphs. A KindDetail page has a Title too, and Lines that are
+	// the summary of its event's article, shown under the event's text.
+	Title string
+	Lines []string
+}
+
+// pageTitle returns the header banner wording for a page.
+func pageTitle(page Page) string {
+	switch page.Kind {
+	case KindBirths:
+		return "On " + Reset + YellowHi + "THIS DAY" + Reset + ", These " + YellowHi + "PEOPLE " + Reset + "Were " + YellowHi + "BORN" + Reset + "... "
+	case KindDeaths:
+		return "On " + Reset + YellowHi + "THIS DAY" + Reset + ", These " + YellowHi + "PEOPLE " + Reset + "Passed " + YellowHi + "AWAY" + Reset + "... "
+	case KindHolidays:
+		return "Today's " + Reset + YellowHi + "HOLIDAYS" + Reset + " and " + YellowHi + "OBSERVANCES" + Reset + "... "
+	case KindNews:
+		return "In the " + Reset + YellowHi + "NEWS" + Reset + " on " + YellowHi + "WIKIPEDIA" + Reset + "... "
+	case KindPlugin, KindNotice:
+		return YellowHi + strings.ToUpper(page.Title) + Reset + "... "
+	case KindMore:
+		return YellowHi + "MORE " + strings.ToUpper(page.Title) + Reset + " from " + YellowHi + "THIS DAY" + Reset + "... "
+	case KindDetail:
+		return "A " + Reset + YellowHi + "CLOSER LOOK" + Reset + " at " + YellowHi + strings.ToUpper(page.Title) + Reset + "... "
+	case KindFeatured:
+		return "Today's " + Reset + YellowHi + "FEATURED ARTICLE" + Reset + " on " + YellowHi + "WIKIPEDIA" + Reset + "... "
+	case KindBirthYear:
+		return "On " + Reset + YellowHi + "THIS DAY" + Reset + " in " + YellowHi + page.Title + Reset + ", the " + YellowHi + "YEAR " + Reset + "You Were " + YellowHi + "BORN" + Reset + "... "
+	default:
+		return "On " + Reset + YellowHi + "THIS DAY" + Reset + ", These " + YellowHi + "EVENTS " + Reset + "Happened... "
+	}
+}
+
+// titleLine is the header row naming the page and its day, in loc.
+func titleLine(page Page, loc *locale.Locale) string {
+	return BgRed + BlackHi + ">>" + BgBlack + " " + pageTitle(page) + Reset + RedHi + ":: " + Reset + " " + loc.Day(page.Date) + " " + Reset
+}
+
+// Header art, shared by the events screen and the banner: a rule, the
+// door's title and a rule above the page's own title line, and a rule
+// under it. The rules are drawn with "-" and put in the session's Charset
+// with Charset.rule.
+const (
+	headerRuleTop    = BlackHi + Reset + "-" + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "--" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "--------- ------------------------------------ ------ -- -  " + Reset
+	headerTitle      = BgGreen + WhiteHi + ">> " + GreenHi + "Glimpse In Time v1.1  " + Reset + BgGreen + BlackHi + ">>" + BgBlack + GreenHi + ">>  " + Reset + WhiteHi + "by " + CyanHi + "<" + WhiteHi + "PHEN0M" + Reset + CyanHi + ">" + Reset
+	headerRuleMid    = BlackHi + "-" + Reset + CyanHi + "--" + GreenHi + "--" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "----- --- -------------------------------- ------ -- -  " + Reset
+	headerRuleBottom = BlackHi + "-" + Reset + CyanHi + "--" + GreenHi + "--" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "--" + Reset + CyanHi + "--- " + GreenHi + "--- ---------------------------- ------ -- -  " + Reset
+)
+
+// RenderEvents draws the header, events for the page's date, and footer to the terminal.
 // It keeps rendering logic isolated so unit tests can target this package.
-func RenderEvents(cfg TerminalConfig, events []Event) {
-	day := time.Now().Day()
-	month := time.Now().Month()
-	year := time.Now().Year()
+func RenderEvents(cfg TerminalConfig, page Page) {
+	screen.Lock()
+	defer screen.Unlock()
+	lastFrame = append(lastFrame[:0], frame(cfg, func() { renderEvents(cfg, page) })...)
+	onScreenLocale = cfg.Locale
+}
+
+func renderEvents(cfg TerminalConfig, page Page) {
+	date, events := page.Date, page.Events
+	day := date.Day()
+	month := date.Month()
 	currentTime := time.Now()
 
 	ClearScreen()
 
-	// Header (kept visually similar to original)
-	fmt.Print("\r\n " + BlackHi + Reset + "-" + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "--" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "--------- ------------------------------------ ------ -- -  " + Reset)
-	fmt.Print("\r\n " + BgGreen + WhiteHi + ">> " + GreenHi + "Glimpse In Time v1.1  " + Reset + BgGreen + BlackHi + ">>" + BgBlack + GreenHi + ">>  " + Reset + WhiteHi + "by " + CyanHi + "<" + WhiteHi + "PHEN0M" + Reset + CyanHi + ">" + Reset)
-	fmt.Print("\r\n " + BlackHi + "-" + Reset + CyanHi + "--" + GreenHi + "--" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "----- --- -------------------------------- ------ -- -  " + Reset)
-	fmt.Printf("\r\n "+BgRed+BlackHi+">>"+BgBlack+" "+"On "+Reset+YellowHi+"THIS DAY"+Reset+", These "+YellowHi+"EVENTS "+Reset+"Happened... "+Reset+RedHi+":: "+Reset+" %v %v%v "+Reset, month, day, getNumEndingLocal(day))
-	fmt.Print("\r\n " + BlackHi + "-" + Reset + CyanHi + "--" + GreenHi + "--" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "--" + Reset + CyanHi + "--- " + GreenHi + "--- ---------------------------- ------ -- -  " + Reset)
+	// Header (kept visually similar to original, unless the day dresses it up)
+	header := cfg.headerLines()
+	write("\r\n ", header[0])
+	write("\r\n ", header[1])
+	write("\r\n ", header[2])
+	write("\r\n ", titleLine(page, cfg.Locale))
+	write("\r\n ", header[3])
 
-	// Dynamic Event Fitting: available rows and widths are intentionally conservative
-	const maxContentRows = 12 // rows 8-19
-	const prefixDisplayLength = 10
-	const maxLineLength = 75 - prefixDisplayLength
+	// Leap day gets a note on the spare row between the header and the events
+	if month == time.February && day == 29 {
+		MoveCursor(1, 7)
+		write(" " + YellowHi + "*" + Reset + " Leap Day! " + WhiteHi + "February 29th" + Reset + " only comes around once every four years " + YellowHi + "*" + Reset)
+	}
+
+	// Dynamic Event Fitting: available rows and widths are intentionally conservative.
+	// The year column grows to fit the widest candidate year (e.g. "44 BC").
+	// Each event is numbered for its detail key; with links enabled the bottom of the area holds the link list.
+	maxContentRows := 12 // rows 8-19
+	style := entryStyle(page, events)
+	style.YearWidth = yearColumnWidth(events, cfg.Theme.yearWidth())
+	// The tagline sits just above the footer, with any footnotes above it
+	tagline := taglineLines(cfg, 76)
+	maxContentRows -= len(tagline)
+	candidates := events
+	if len(candidates) > 5 {
+		candidates = candidates[:5]
+	}
+	footnotes := cfg.notes(candidates, 78)
+	maxContentRows -= len(footnotes)
+
+	// Featured, plugin, notice, detail and continuation pages fill the event area their own way
+	switch page.Kind {
+	case KindMore:
+		renderMore(page, tagline)
+		renderFooter(cfg, page, currentTime)
+		if page.Title != KindHolidays && page.Title != KindNews {
+			MoveCursor(1, 23)
+			write(cfg.eraLegend())
+		}
+		renderPrompt(page)
+		redrawStatus(cfg)
+		return
+	case KindDetail:
+		renderDetail(cfg, page, 12-len(tagline), tagline)
+		renderFooter(cfg, page, currentTime)
+		if len(page.Events) > 0 && page.Events[0].Year != 0 {
+			// The same legend as the list the event came from
+			MoveCursor(1, 23)
+			write(cfg.eraLegend())
+		}
+		renderPrompt(page)
+		redrawStatus(cfg)
+		return
+	case KindFeatured:
+		renderArticle(page, maxContentRows, footnotes, tagline)
+		renderFooter(cfg, page, currentTime)
+		renderPrompt(page)
+		redrawStatus(cfg)
+		return
+	case KindPlugin:
+		renderLines(page)
+		renderFooter(cfg, page, currentTime)
+		renderPrompt(page)
+		redrawStatus(cfg)
+		return
+	case KindNotice:
+		renderNotice(page)
+		renderFooter(cfg, page, currentTime)
+		renderPrompt(page)
+		redrawStatus(cfg)
+		return
+	}
 
+	// Each event is drawn once, to fit it and then to show it
+	r := ANSI{Config: cfg}
 	var selected []Event
+	var drawn [5][]string
 	totalRowsUsed := 0
 	for _, e := range events {
-		wrapped := wrapText(strings.TrimSpace(e.Text), maxLineLength)
-		eventRows := len(wrapped) + 1 // +1 blank line
+		style.Number = len(selected) + 1
+		lines := r.Entry(e, style, 75)
+		eventRows := len(lines) + 1 // +1 blank line
 		if totalRowsUsed+eventRows <= maxContentRows && len(selected) < 5 {
+			drawn[len(selected)] = lines
 			selected = append(selected, e)
 			totalRowsUsed += eventRows
 		} else {
 			break
 		}
 	}
+	onScreen = page
+	onScreen.Events = selected
 
-	// Display selected events starting at row 8
+	// Display selected events starting at row 8, a blank line between them
 	yPos := 8
-	for _, e := range selected {
-		yearStr := fmt.Sprintf("%4d", e.Year)
-		prefix := " " + CyanHi + yearStr + Reset + CyanHi + " <" + BlackHi + ":" + Reset + CyanHi + "> "
-		wrapped := wrapText(strings.TrimSpace(e.Text), maxLineLength)
-
-		MoveCursor(1, yPos)
-		fmt.Print(prefix + WhiteHi + wrapped[0] + Reset)
-		yPos++
-		for i := 1; i < len(wrapped); i++ {
+	for i := range selected {
+		for _, line := range drawn[i] {
 			MoveCursor(1, yPos)
-			fmt.Print("          " + WhiteHi + wrapped[i] + Reset)
+			write(line)
 			yPos++
 		}
-		// blank line between events
 		yPos++
 	}
 
-	// Footer
-	MoveCursor(1, 20)
-	fmt.Print(" " + BlackHi + "-" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "--" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "-----" + Reset + CyanHi + "-" + GreenHi + "--------------------------------------- ---  --- -- -  " + Reset)
-	MoveCursor(1, 21)
-	fmt.Printf(" "+BgRed+BlackHi+">>"+BgBlack+" "+WhiteHi+"Generated on %v %v, %v at %v "+Reset, month, day, year, currentTime.Format("3:4 PM"))
-	MoveCursor(1, 22)
-	fmt.Print(" " + BlackHi + "-" + Reset + CyanHi + "---" + GreenHi + "-" + Reset + CyanHi + "--" + GreenHi + "-" + Reset + CyanHi + "-" + GreenHi + "-----" + Reset + CyanHi + "-" + GreenHi + "--------------------------------------- ---  --- -- -  " + Reset)
+	// Link list sits directly above the footer, for the events that made it on screen
+	if len(footnotes) > 0 {
+		footnotes = cfg.notes(selected, 78)
+		for i, line := range footnotes {
+			MoveCursor(1, 20-len(tagline)-len(footnotes)+i)
+			write(line)
+		}
+	}
+	for i, line := range tagline {
+		MoveCursor(1, 20-len(tagline)+i)
+		write(line)
+	}
+
+	renderFooter(cfg, page, currentTime)
+
+	// Era legend explains the badges next to each year
+	if !style.Bulleted {
+		MoveCursor(1, 23)
+		write(cfg.eraLegend())
+	}
+
+	renderPrompt(page)
+
+	// The screen was cleared, so put the status row back
+	redrawStatus(cfg)
+}
 
-	// Pause prompt
+// renderPrompt draws the page's command bar, or the plain pause prompt.
+func renderPrompt(page Page) {
+	if len(page.Commands) > 0 {
+		renderCommandBar(page.Commands)
+		return
+	}
 	MoveCursor(1, 24)
-	fmt.Print("                   " + BgBlueHi + WhiteHi + "<" + Reset + CyanHi + "<  " + BlackHi + "... " + Reset + WhiteHi + "press " + WhiteHi + "ANY KEY " + Reset + WhiteHi + "to " + WhiteHi + "CONTINUE " + Reset + BlackHi + "... " + Reset + CyanHi + ">" + BgBlueHi + WhiteHi + ">" + Reset)
-}
\ No newline at end of file
+	write("                   " + BgBlueHi + WhiteHi + "<" + Reset + CyanHi + "<  " + BlackHi + "... " + Reset + WhiteHi + "press " + WhiteHi + "ANY KEY " + Reset + WhiteHi + "to " + WhiteHi + "CONTINUE " + Reset + BlackHi + "... " + Reset + CyanHi + ">" + BgBlueHi + WhiteHi + ">" + Reset)
+}
@@ -0,0 +1,167 @@
+package terminal
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultPrefix is an entry's year column when the theme doesn't set one:
+// the year, then its era badge in angle brackets.
+const DefaultPrefix = "{year} <{era}>"
+
+// Theme is how the screens look beyond their character set: how an
+// entry's year column is set out, and how its text wraps beside it. The
+// zero Theme is the door's own look.
+type Theme struct {
+	// Prefix sets out an entry's year column, with {year} and {era}
+	// standing for its year and era badge; empty is DefaultPrefix.
+	Prefix string
+	// YearWidth right-aligns years in at least that many columns; zero is
+	// four.
+	YearWidth int
+	// Glyphs colors the rest of the prefix, as one of Colors; empty is
+	// bright cyan.
+	Glyphs string
+	// Eras colors each era, by its badge and as one of Colors, wherever its
+	// years, badge and name are drawn; an era it leaves out keeps the
+	// door's color.
+	Eras map[string]string
+	// HideLegend leaves out the legend of era badges under the footer.
+	HideLegend bool
+
+	// HangYear starts the lines an entry's text wraps onto under its year
+	// column, giving them more room, rather than under its first line.
+	HangYear bool
+	// Indent moves those lines that many columns further in.
+	Indent int
+	// Justify fills each line but an entry's last to the right margin.
+	Justify bool
+
+	// Season draws the door's rules in its accents, as SeasonOf names it;
+	// empty keeps the door's bright cyan and green.
+	Season string
+}
+
+// seasonAccents are the colors each season draws the door's rules in, for
+// their bright cyan and their bright green. A normal shade resets first,
+// as the rules run straight on from one color to the next and bold would
+// otherwise carry over.
+var seasonAccents = map[string][2]string{
+	"spring": {GreenHi, Esc + "0;32m"},
+	"summer": {YellowHi, CyanHi},
+	"autumn": {YellowHi, Esc + "0;33m"},
+	"winter": {WhiteHi, CyanHi},
+}
+
+// SeasonOf names the season date falls in, by whole months: winter from
+// December, spring from March, summer from June and autumn from
+// September, or the other way round in the southern hemisphere, when
+// south.
+func SeasonOf(date time.Time, south bool) string {
+	seasons := []string{"winter", "spring", "summer", "autumn"}
+	i := int(date.Month()) % 12 / 3
+	if south {
+		i = (i + 2) % len(seasons)
+	}
+	return seasons[i]
+}
+
+// accents returns one of the door's rules in the theme's season's
+// accents.
+func (t Theme) accents(rule string) string {
+	a, ok := seasonAccents[t.Season]
+	if !ok {
+		return rule
+	}
+	return strings.NewReplacer(CyanHi, a[0], GreenHi, a[1]).Replace(rule)
+}
+
+// rule returns one of the door's rules in the theme's accents and the
+// caller's character set.
+func (cfg TerminalConfig) rule(rule string) string {
+	return cfg.Charset.rule(cfg.Theme.accents(rule))
+}
+
+// hang is how far in an entry's text continues on the lines after its
+// first, for an entry whose text starts indent columns in and whose year
+// column, or bullet, starts at column year. It leaves the text at least
+// half of width.
+func (t Theme) hang(indent, year, width int) int {
+	hang := indent
+	if t.HangYear {
+		hang = year
+	}
+	return min(hang+t.Indent, width/2)
+}
+
+// yearWidth is how many columns years are right-aligned in at least.
+func (t Theme) yearWidth() int {
+	return cmp.Or(t.YearWidth, 4)
+}
+
+// yearPrefix sets out e's year column as the theme says, with its year
+// right-aligned in width columns, and a space after it for the text.
+func (cfg TerminalConfig) yearPrefix(e Event, width int) string {
+	glyphs := CyanHi
+	if c, ok := Colors[cfg.Theme.Glyphs]; ok {
+		glyphs = c
+	}
+	year := FormatYear(e.Year)
+	var b strings.Builder
+	format := cmp.Or(cfg.Theme.Prefix, DefaultPrefix) + " "
+	for format != "" {
+		i := strings.IndexByte(format, '{')
+		if i < 0 {
+			i = len(format)
+		}
+		if i > 0 {
+			b.WriteString(glyphs + format[:i])
+			format = format[i:]
+			continue
+		}
+		switch {
+		case strings.HasPrefix(format, "{year}"):
+			b.WriteString(cfg.yearColor(e) + strings.Repeat(" ", max(width-len(year), 0)) + year + Reset)
+			format = format[len("{year}"):]
+		case strings.HasPrefix(format, "{era}"):
+			b.WriteString(cfg.eraColor(e.Era) + e.Era.Badge + Reset)
+			format = format[len("{era}"):]
+		default:
+			b.WriteString(glyphs + "{")
+			format = format[1:]
+		}
+	}
+	return b.String()
+}
+
+// Colors are the colors a theme can name, by their ANSI names.
+var Colors = map[string]string{
+	"black":          Esc + "30m",
+	"red":            Esc + "31m",
+	"green":          Esc + "32m",
+	"yellow":         Yellow,
+	"blue":           Esc + "34m",
+	"magenta":        Esc + "35m",
+	"cyan":           Cyan,
+	"white":          Esc + "37m",
+	"bright-black":   BlackHi,
+	"bright-red":     RedHi,
+	"bright-green":   GreenHi,
+	"bright-yellow":  YellowHi,
+	"bright-blue":    BlueHi,
+	"bright-magenta": MagentaHi,
+	"bright-cyan":    CyanHi,
+	"bright-white":   WhiteHi,
+}
+
+// ColorNames lists the names in Colors, in order.
+func ColorNames() []string {
+	names := make([]string, 0, len(Colors))
+	for name := range Colors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,399 @@
+package terminal
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/robbiew/history/pkg/textwrap"
+)
+
+// Virtual screen size. The door lays out for 80x25 whatever the caller has.
+const (
+	screenCols = 80
+	screenRows = 25
+)
+
+// attr is a cell's color and intensity, as set by SGR.
+type attr struct {
+	fg, bg int16 // 0-7, or extendedColor plus a 256-color index; -1 is the terminal default
+	bold   bool
+	blink  bool
+}
+
+// extendedColor offsets a 256-color palette index, set with 38;5, from the
+// eight basic foreground colors.
+const extendedColor = 256
+
+var defaultAttr = attr{fg: -1, bg: -1}
+
+// writeSGR writes the sequence that sets a from any state to b.
+func (a attr) writeSGR(b *bytes.Buffer) {
+	b.WriteString(Esc + "0")
+	if a.bold {
+		b.WriteString(";1")
+	}
+	if a.blink {
+		b.WriteString(";5")
+	}
+	if a.fg >= extendedColor {
+		b.WriteString(";38;5;")
+		b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(a.fg-extendedColor), 10))
+	} else if a.fg >= 0 {
+		b.WriteString(";3")
+		b.WriteByte(byte('0' + a.fg))
+	}
+	if a.bg >= 0 {
+		b.WriteString(";4")
+		b.WriteByte(byte('0' + a.bg))
+	}
+	b.WriteByte('m')
+}
+
+// sgr returns a as the SGR sequence with params, -1 for one left empty,
+// leaves it, or false if the sequence isn't one the door writes.
+func (a attr) sgr(params []int) (attr, bool) {
+	num := func(i, def int) int {
+		if i >= len(params) || params[i] < 0 {
+			return def
+		}
+		return params[i]
+	}
+	for i := 0; i < len(params); i++ {
+		switch v := num(i, 0); {
+		case v == 0:
+			a = defaultAttr
+		case v == 1:
+			a.bold = true
+		case v == 5:
+			a.blink = true
+		case v == 22:
+			a.bold = false
+		case v == 25:
+			a.blink = false
+		case v >= 30 && v <= 37:
+			a.fg = int16(v - 30)
+		case v == 38:
+			n := num(i+2, -1)
+			if num(i+1, 0) != 5 || n < 0 || n > 255 {
+				return a, false
+			}
+			a.fg = int16(extendedColor + n)
+			i += 2
+		case v == 39:
+			a.fg = -1
+		case v >= 40 && v <= 47:
+			a.bg = int16(v - 40)
+		case v == 49:
+			a.bg = -1
+		default:
+			return a, false
+		}
+	}
+	return a, true
+}
+
+// cell is one screen position: the bytes of its character and its
+// attribute. A wide character takes two cells, the second left empty.
+type cell struct {
+	ch string
+	a  attr
+}
+
+var blankCell = cell{ch: " ", a: defaultAttr}
+
+// vscreen models what the caller's terminal shows, by interpreting the
+// subset of ANSI the door itself writes: cursor positioning, save/restore,
+// erase screen and line, SGR, CR and LF. Each byte is one cell, as on a
+// CP437 terminal, unless utf8 is set, when each UTF-8 character takes the
+// columns it does on screen: two for a wide one, and none for a combining
+// mark, which joins the character before it. A screen that scrolls or sees
+// a sequence it doesn't know becomes invalid, and the next frame is drawn
+// in full.
+type vscreen struct {
+	cells        [screenRows][screenCols]cell
+	x, y         int // 0-based cursor; x == screenCols means a wrap is pending
+	savedX       int
+	savedY       int
+	a            attr
+	valid        bool
+	utf8         bool
+	pendingBytes []byte // an escape sequence or character split across writes
+	// scrolled, overflowed and unknown record, for Screen, that the
+	// terminal scrolled, that text ran past the last column and that a
+	// sequence wasn't understood
+	scrolled   bool
+	overflowed bool
+	unknown    bool
+}
+
+func newVScreen(utf8 bool) *vscreen {
+	s := &vscreen{}
+	s.reset(utf8)
+	return s
+}
+
+// reset blanks s for reuse, as newVScreen would return it.
+func (s *vscreen) reset(utf8 bool) {
+	*s = vscreen{cells: s.cells, a: defaultAttr, valid: true, utf8: utf8}
+	s.clear()
+}
+
+func (s *vscreen) clear() {
+	for y := range s.cells {
+		for x := range s.cells[y] {
+			s.cells[y][x] = blankCell
+		}
+	}
+}
+
+// apply interprets p, updating cells, cursor and attribute.
+func (s *vscreen) apply(p []byte) {
+	if len(s.pendingBytes) > 0 {
+		p = append(s.pendingBytes, p...)
+		s.pendingBytes = nil
+	}
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch c {
+		case 0x1b:
+			n, ok := s.escape(p[i:])
+			if n == 0 {
+				// Incomplete; keep it for the next write
+				s.pendingBytes = append([]byte(nil), p[i:]...)
+				return
+			}
+			if !ok {
+				s.valid, s.unknown = false, true
+			}
+			i += n - 1
+		case '\r':
+			s.x = 0
+		case '\n':
+			if s.y == screenRows-1 {
+				s.valid, s.scrolled = false, true // the terminal scrolled
+			} else {
+				s.y++
+			}
+		case 0x07:
+		default:
+			ch, w := p[i:i+1], 1
+			if s.utf8 && c >= utf8.RuneSelf {
+				if !utf8.FullRune(p[i:]) {
+					s.pendingBytes = append([]byte(nil), p[i:]...)
+					return
+				}
+				r, size := utf8.DecodeRune(p[i:])
+				ch, w = p[i:i+size], textwrap.RuneWidth(r)
+				i += size - 1
+			}
+			if w == 0 {
+				s.combine(ch)
+				continue
+			}
+			if s.x+w > screenCols {
+				s.x, s.overflowed = 0, true
+				if s.y == screenRows-1 {
+					s.valid, s.scrolled = false, true
+				} else {
+					s.y++
+				}
+			}
+			for x := s.x; x < s.x+w; x++ {
+				s.overwrite(x)
+			}
+			s.cells[s.y][s.x] = cell{ch: glyph(ch), a: s.a}
+			s.x++
+			if w == 2 {
+				s.cells[s.y][s.x] = cell{a: s.a}
+				s.x++
+			}
+		}
+	}
+}
+
+// overwrite blanks the other half of a wide character whose cell at x, on
+// the cursor's row, is about to be written over, as terminals do.
+func (s *vscreen) overwrite(x int) {
+	row := &s.cells[s.y]
+	switch {
+	case row[x].ch == "" && x > 0:
+		row[x-1].ch = " "
+	case x+1 < screenCols && row[x+1].ch == "":
+		row[x+1].ch = " "
+	}
+}
+
+// combine adds a combining mark to the character before the cursor.
+func (s *vscreen) combine(ch []byte) {
+	x := s.x - 1
+	if x > 0 && s.cells[s.y][x].ch == "" {
+		x--
+	}
+	if x < 0 {
+		return
+	}
+	c := &s.cells[s.y][x]
+	c.ch = glyph(append([]byte(c.ch), ch...))
+}
+
+// interned holds the characters cells hold, so a screen of UTF-8 box
+// drawing doesn't allocate a string for every cell it models.
+var interned = map[string]string{}
+
+// glyph returns ch as a string, shared with every other cell holding it.
+func glyph(ch []byte) string {
+	if len(ch) == 1 {
+		return string(ch) // one-byte strings never allocate
+	}
+	if g, ok := interned[string(ch)]; ok {
+		return g
+	}
+	g := string(ch)
+	interned[g] = g
+	return g
+}
+
+// maxParams is the most parameters an escape sequence the model
+// understands has; the door writes no more than five.
+const maxParams = 16
+
+// escape interprets the escape sequence at the start of p and returns its
+// length, or 0 if p ends before the sequence does. ok is false for
+// sequences the model doesn't understand.
+func (s *vscreen) escape(p []byte) (n int, ok bool) {
+	if len(p) < 2 {
+		return 0, true
+	}
+	if p[1] != '[' {
+		return 2, false
+	}
+	end := 2
+	for end < len(p) && (p[end] < 0x40 || p[end] > 0x7e) {
+		end++
+	}
+	if end == len(p) {
+		return 0, true
+	}
+	// Parameters are read in place; -1 marks one left empty
+	var params [maxParams]int
+	count := 0
+	for i := 2; i <= end; i++ {
+		if count == maxParams {
+			return end + 1, false
+		}
+		v, digits := 0, false
+		for ; i < end && p[i] >= '0' && p[i] <= '9'; i++ {
+			v, digits = v*10+int(p[i]-'0'), true
+		}
+		if i < end && p[i] != ';' {
+			return end + 1, false
+		}
+		params[count] = -1
+		if digits {
+			params[count] = v
+		}
+		count++
+	}
+	num := func(i, def int) int {
+		if i >= count || params[i] < 0 {
+			return def
+		}
+		return params[i]
+	}
+	switch p[end] {
+	case 'H', 'f':
+		s.y = min(max(num(0, 1), 1), screenRows) - 1
+		s.x = min(max(num(1, 1), 1), screenCols) - 1
+	case 'J':
+		if num(0, 0) != 2 {
+			return end + 1, false
+		}
+		s.clear()
+	case 'K':
+		if num(0, 0) != 0 {
+			return end + 1, false
+		}
+		for x := min(s.x, screenCols); x < screenCols; x++ {
+			s.cells[s.y][x] = cell{ch: " ", a: s.a}
+		}
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.x, s.y = s.savedX, s.savedY
+	case 'm':
+		a, ok := s.a.sgr(params[:count])
+		if !ok {
+			return end + 1, false
+		}
+		s.a = a
+	default:
+		return end + 1, false
+	}
+	return end + 1, true
+}
+
+// diffScreens writes to b, after resetting it, the bytes that turn a
+// terminal showing from into one showing to, leaving the cursor and
+// attribute where to has them. It reports false when a full redraw of full
+// bytes would be as cheap, in which case b is left partly written.
+func diffScreens(b *bytes.Buffer, from, to *vscreen, full int) bool {
+	b.Reset()
+	cur := attr{fg: -2} // unknown, so the first cell sets it
+	for y := 0; y < screenRows; y++ {
+		x := 0
+		for x < screenCols {
+			if from.cells[y][x] == to.cells[y][x] {
+				x++
+				continue
+			}
+			// Write the changed run, bridging short unchanged gaps rather
+			// than paying for another cursor move. A run that starts on a
+			// wide character's right half starts at its left.
+			if x > 0 && (from.cells[y][x].ch == "" || to.cells[y][x].ch == "") {
+				x--
+			}
+			moveTo(b, x, y)
+			for x < screenCols {
+				if from.cells[y][x] == to.cells[y][x] {
+					gap := 0
+					for x+gap < screenCols && from.cells[y][x+gap] == to.cells[y][x+gap] {
+						gap++
+					}
+					if x+gap == screenCols || gap > 6 {
+						break
+					}
+				}
+				c := to.cells[y][x]
+				if c.ch == "" {
+					// Drawn with the wide character before it
+					x++
+					continue
+				}
+				if c.a != cur {
+					c.a.writeSGR(b)
+					cur = c.a
+				}
+				b.WriteString(c.ch)
+				x++
+			}
+		}
+		if b.Len() >= full {
+			return false
+		}
+	}
+	moveTo(b, min(to.x, screenCols-1), to.y)
+	if to.a != cur {
+		to.a.writeSGR(b)
+	}
+	return b.Len() < full
+}
+
+// moveTo writes the sequence that puts the cursor at the 0-based x, y.
+func moveTo(b *bytes.Buffer, x, y int) {
+	b.WriteString(Esc)
+	b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(y+1), 10))
+	b.WriteByte(';')
+	b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(x+1), 10))
+	b.WriteByte('H')
+}
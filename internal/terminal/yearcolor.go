@@ -0,0 +1,58 @@
+package terminal
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/robbiew/history/internal/era"
+)
+
+// gradientFloor is where the ancient end of the gradient starts; older
+// years share its color.
+const gradientFloor = -1000
+
+// yearColor returns the color for an event's year: its era's color, or with
+// GradientYears a shade for its century, from deep blue for the ancient
+// world to bright green for today. Terminals without the extended palette
+// get blue for the older half and green for the newer.
+func (cfg TerminalConfig) yearColor(e Event) string {
+	if !cfg.GradientYears {
+		return cfg.eraColor(e.Era)
+	}
+	t := timeDepth(e.Year)
+	if !cfg.ExtendedPalette {
+		if t < 0.5 {
+			return BlueHi
+		}
+		return GreenHi
+	}
+	// Along the 6x6x6 color cube's green and blue axes
+	g := int(math.Round(5 * t))
+	b := int(math.Round(4 * (1 - t)))
+	return fmt.Sprintf(Esc+"38;5;%dm", 16+6*g+b)
+}
+
+// timeDepth places year's century between 0, the ancient world, and 1,
+// today. Each era gets an equal share, so the centuries of recent eras,
+// which most events come from, don't all come out the same shade.
+func timeDepth(year int) float64 {
+	century := year - ((year%100)+100)%100
+	e := era.Of(century)
+	lo, hi := e.Min, e.Max
+	if lo == math.MinInt {
+		lo = gradientFloor
+	}
+	if hi == math.MaxInt {
+		hi = time.Now().Year()
+	}
+	frac := 1.0
+	if hi > lo {
+		frac = float64(min(max(century, lo), hi)-lo) / float64(hi-lo)
+	}
+	i := 0
+	for i < len(era.All) && era.All[i].Name != e.Name {
+		i++
+	}
+	return min((float64(i)+frac)/float64(len(era.All)), 1)
+}
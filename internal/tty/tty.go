@@ -0,0 +1,35 @@
+// Package tty reads keys from the door's controlling terminal, such as the
+// console of a local run or a BBS that hands its doors a terminal rather
+// than the caller's socket. The terminal is put in raw mode, so each key
+// arrives unechoed as it is pressed, and what is typed is passed on as it
+// comes for the input package to decode.
+package tty
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// TTY is the controlling terminal, in raw mode until Close.
+type TTY struct {
+	f       *os.File
+	r       io.RuneReader
+	restore func() error
+}
+
+// Open opens the controlling terminal and puts it in raw mode. It fails when
+// the door has none, as when it runs with the caller's socket on stdin.
+func Open() (*TTY, error) {
+	return open()
+}
+
+// ReadRune waits for the next character typed.
+func (t *TTY) ReadRune() (rune, int, error) {
+	return t.r.ReadRune()
+}
+
+// Close puts the terminal back the way Open found it.
+func (t *TTY) Close() error {
+	return errors.Join(t.restore(), t.f.Close())
+}
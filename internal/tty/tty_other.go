@@ -0,0 +1,14 @@
+//go:build !(linux || solaris || darwin || dragonfly || freebsd || netbsd || openbsd || windows)
+
+package tty
+
+import (
+	"errors"
+	"runtime"
+)
+
+// open fails where the door doesn't drive a terminal itself; keys are then
+// read from stdin.
+func open() (*TTY, error) {
+	return nil, errors.New("no terminal support on " + runtime.GOOS)
+}
@@ -0,0 +1,38 @@
+//go:build linux || solaris || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package tty
+
+import (
+	"bufio"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func open() (*TTY, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	fd := int(f.Fd())
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	// No echo or line editing, and CR and LF left as typed. Signals stay on,
+	// so Ctrl-C still stops a local run.
+	t := *saved
+	t.Iflag &^= unix.ISTRIP | unix.INLCR | unix.ICRNL | unix.IGNCR | unix.IXOFF
+	t.Lflag &^= unix.ECHO | unix.ICANON
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &t); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &TTY{
+		f:       f,
+		r:       bufio.NewReader(f),
+		restore: func() error { return unix.IoctlSetTermios(fd, ioctlSetTermios, saved) },
+	}, nil
+}
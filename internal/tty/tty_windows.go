@@ -0,0 +1,63 @@
+package tty
+
+import (
+	"os"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/sys/windows"
+)
+
+func open() (*TTY, error) {
+	f, err := os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	h := windows.Handle(f.Fd())
+	var saved uint32
+	if err := windows.GetConsoleMode(h, &saved); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// No echo or line editing, and cursor and function keys sent as the
+	// same ESC sequences a remote terminal sends. Ctrl-C still stops the
+	// door.
+	mode := saved&^(windows.ENABLE_ECHO_INPUT|windows.ENABLE_LINE_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(h, mode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &TTY{
+		f:       f,
+		r:       &consoleReader{h: h},
+		restore: func() error { return windows.SetConsoleMode(h, saved) },
+	}, nil
+}
+
+// consoleReader reads what is typed at a Windows console, which comes as
+// UTF-16.
+type consoleReader struct {
+	h       windows.Handle
+	runes   []rune
+	pending []uint16 // the first half of a surrogate pair the next read ends
+}
+
+func (c *consoleReader) ReadRune() (rune, int, error) {
+	for len(c.runes) == 0 {
+		var buf [64]uint16
+		var n uint32
+		if err := windows.ReadConsole(c.h, &buf[0], uint32(len(buf)), &n, nil); err != nil {
+			return 0, 0, err
+		}
+		units := append(c.pending, buf[:n]...)
+		c.pending = nil
+		if len(units) > 0 && utf16.IsSurrogate(rune(units[len(units)-1])) && units[len(units)-1] < 0xdc00 {
+			c.pending = units[len(units)-1:]
+			units = units[:len(units)-1]
+		}
+		c.runes = utf16.Decode(units)
+	}
+	r := c.runes[0]
+	c.runes = c.runes[1:]
+	return r, utf8.RuneLen(r), nil
+}
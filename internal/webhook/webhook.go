@@ -0,0 +1,138 @@
+// Package webhook tells the sysop about trouble by POSTing to a webhook, so
+// a failing API or a damaged cache is known about before callers complain.
+//
+// A webhook is sent as JSON in one of two formats. "json" is a plain object
+// for the sysop's own tooling:
+//
+//	{"event": "api_failures", "message": "...", "source": "...", "time": "..."}
+//
+// "discord" is a message a Discord channel webhook accepts, with the same
+// details in its text.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Events a Notifier sends.
+const (
+	// APIFailures is sent when fetches have failed enough times in a row to
+	// open the circuit breaker.
+	APIFailures = "api_failures"
+	// CacheDamage is sent when a cached response is found damaged and
+	// dropped.
+	CacheDamage = "cache_damage"
+	// Panic is sent when the door crashes.
+	Panic = "panic"
+)
+
+const (
+	// sendTimeout bounds a single POST.
+	sendTimeout = 10 * time.Second
+	// discordLimit is the most characters Discord takes in a message; a
+	// longer one, such as a crash with its stack, is cut short.
+	discordLimit = 2000
+)
+
+// Notifier posts events to a webhook. A nil *Notifier is valid and sends
+// nothing, for when no webhook is set.
+type Notifier struct {
+	url    string
+	format string
+	source string
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// New returns a Notifier posting to url in format, "json" or "discord",
+// naming source, such as the BBS and node, as where the trouble is. An
+// empty url returns nil.
+func New(url, format, source string) *Notifier {
+	if url == "" {
+		return nil
+	}
+	return &Notifier{url: url, format: format, source: source, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Notify sends event in the background, so nothing waits on a slow webhook.
+// A webhook that fails is logged and given up on.
+func (n *Notifier) Notify(event, message string) {
+	if n == nil {
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if err := n.Send(context.Background(), event, message); err != nil {
+			slog.Warn("could not send webhook", "event", event, "error", err)
+		}
+	}()
+}
+
+// Send posts event and waits for the webhook to answer.
+func (n *Notifier) Send(ctx context.Context, event, message string) error {
+	if n == nil {
+		return nil
+	}
+	body, err := n.payload(event, message, time.Now())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Wait waits up to timeout for events still being sent.
+func (n *Notifier) Wait(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// payload is the body posted for event.
+func (n *Notifier) payload(event, message string, at time.Time) ([]byte, error) {
+	if n.format == "discord" {
+		content := fmt.Sprintf("**%s** on %s: %s", event, n.source, message)
+		if r := []rune(content); len(r) > discordLimit {
+			content = string(r[:discordLimit-1]) + "…"
+		}
+		return json.Marshal(map[string]string{
+			"username": "This Day in History",
+			"content":  content,
+		})
+	}
+	return json.Marshal(map[string]string{
+		"event":   event,
+		"message": message,
+		"source":  n.source,
+		"time":    at.Format(time.RFC3339),
+	})
+}
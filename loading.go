@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+const (
+	// loadingBarDelay is how long a load runs before the bar is drawn, so
+	// one served from the cache doesn't flash it up.
+	loadingBarDelay = 300 * time.Millisecond
+	// stallAfter is how long a load goes with nothing new before the bar
+	// counts the seconds it has been waiting.
+	stallAfter = 2 * time.Second
+)
+
+// loadProgress is how far the fetches of a day have got, as the client
+// reports them, for the loading bar.
+type loadProgress struct {
+	mu      sync.Mutex
+	fetches map[string]wikimedia.Progress // the latest of each section's fetch
+	order   []string
+	asked   map[string]bool // sections whose fetch went to the API
+	changed time.Time       // when a fetch last moved on
+}
+
+// newLoadProgress returns the progress of fetching sections, none of them
+// started.
+func newLoadProgress(sections []string) *loadProgress {
+	p := &loadProgress{fetches: make(map[string]wikimedia.Progress), asked: make(map[string]bool), changed: time.Now()}
+	for _, section := range sections {
+		p.fetches[section] = wikimedia.Progress{Section: section, Stage: wikimedia.StageCache}
+		p.order = append(p.order, section)
+	}
+	return p
+}
+
+// update records what a fetch reports. It is called from the fetches' own
+// goroutines.
+func (p *loadProgress) update(pr wikimedia.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.fetches[pr.Section]; !ok {
+		p.order = append(p.order, pr.Section)
+	}
+	p.fetches[pr.Section] = pr
+	if pr.Stage == wikimedia.StageRequest {
+		p.asked[pr.Section] = true
+	}
+	p.changed = time.Now()
+}
+
+// cacheUse counts the finished fetches the cache answered without asking the
+// API, and those that asked it.
+func (p *loadProgress) cacheUse() (hits, misses int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, section := range p.order {
+		switch {
+		case p.fetches[section].Stage != wikimedia.StageDone:
+		case p.asked[section]:
+			misses++
+		default:
+			hits++
+		}
+	}
+	return hits, misses
+}
+
+// stageDone is how far through a fetch each stage is.
+var stageDone = map[wikimedia.Stage]float64{
+	wikimedia.StageCache:   0.05,
+	wikimedia.StageRequest: 0.2,
+	wikimedia.StageRetry:   0.2,
+	wikimedia.StageReceive: 0.3,
+	wikimedia.StageParse:   0.9,
+	wikimedia.StageDone:    1,
+}
+
+// fetchDone is how far through its fetch pr is, between 0 and 1.
+func fetchDone(pr wikimedia.Progress) float64 {
+	if pr.Stage == wikimedia.StageReceive && pr.Total > 0 {
+		// Receiving takes the bar from 30% to 90% as the answer comes in
+		return 0.3 + 0.6*min(float64(pr.Bytes)/float64(pr.Total), 1)
+	}
+	return stageDone[pr.Stage]
+}
+
+// state returns how far through the fetches are together, between 0 and 1,
+// and what the one furthest behind is doing.
+func (p *loadProgress) state() (done float64, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.fetches) == 0 {
+		return 0, "Fetching historical data"
+	}
+	var sum float64
+	var behind *wikimedia.Progress
+	for _, section := range p.order {
+		pr := p.fetches[section]
+		sum += fetchDone(pr)
+		if pr.Stage != wikimedia.StageDone && (behind == nil || fetchDone(pr) < fetchDone(*behind)) {
+			behind = &pr
+		}
+	}
+	done = sum / float64(len(p.fetches))
+	if behind == nil {
+		return done, "Ready to display"
+	}
+	status = stageStatus(*behind)
+	if waited := time.Since(p.changed); waited >= stallAfter {
+		status += fmt.Sprintf(" (no reply for %ds)", int(waited.Seconds()))
+	}
+	return done, status
+}
+
+// stageStatus says what a fetch is doing.
+func stageStatus(pr wikimedia.Progress) string {
+	switch pr.Stage {
+	case wikimedia.StageCache:
+		return "Checking the cache"
+	case wikimedia.StageRequest:
+		if pr.Attempt > 1 {
+			return fmt.Sprintf("Asking Wikimedia for %s, try %d", pr.Section, pr.Attempt)
+		}
+		return "Asking Wikimedia for " + pr.Section
+	case wikimedia.StageReceive:
+		return fmt.Sprintf("Receiving %s: %d KB", pr.Section, (pr.Bytes+1023)/1024)
+	case wikimedia.StageRetry:
+		return "No luck with " + pr.Section + "; trying again"
+	case wikimedia.StageParse:
+		return "Processing " + pr.Section
+	}
+	return "Ready to display"
+}
+
+// loadingHintRow is where the loading screen says a key stops it, under
+// the bar.
+const loadingHintRow = 14
+
+// errLoadStopped is the cause of a fetch the caller stopped with a key.
+var errLoadStopped = errors.New("loading stopped by the caller")
+
+// stopOnKey returns a context that ends, with errLoadStopped, when the caller
+// presses a key that keep doesn't keep, so a slow fetch needn't lock them out
+// until its deadline. Keys keep keeps are put by without stopping anything.
+// keys is read only until done is called; done returns the keys pressed, in
+// order, once nothing is reading keys any more. A nil keys never ends the
+// context, and a nil keep keeps none.
+func stopOnKey(ctx context.Context, keys *input.Decoder, keep func(input.Event) bool) (stoppable context.Context, done func() []input.Event) {
+	stoppable, stop := context.WithCancelCause(ctx)
+	if keys == nil {
+		return stoppable, func() []input.Event {
+			stop(nil)
+			return nil
+		}
+	}
+	var pressed []input.Event // only the reader touches it until finished
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			ev, err := keys.ReadKeyContext(stoppable)
+			if err != nil {
+				return
+			}
+			if ev.Key == input.KeyResize {
+				// Not a key; the pages are drawn for the new window
+				terminal.Resize(ev.Cols, ev.Rows)
+				continue
+			}
+			pressed = append(pressed, ev)
+			if keep != nil && keep(ev) {
+				continue
+			}
+			stop(errLoadStopped)
+			return
+		}
+	}()
+	return stoppable, func() []input.Event {
+		stop(nil)
+		<-finished
+		return pressed
+	}
+}
+
+// loadingRow is the row the loading indicator says what is happening on.
+const loadingRow = 12
+
+// A loadingStyle draws the loading indicator, a frame at a time. Frames are
+// written whole, placed with cursor moves, and sent only when they differ
+// from the one before, so a style that keeps still costs nothing on a slow
+// line.
+type loadingStyle interface {
+	// frame writes frame n, counting from 0, for a load done of the way
+	// through, between 0 and 1, that is busy with status.
+	frame(w io.Writer, n int, done float64, status string)
+	// rows are the rows the style draws on, cleared when the load is over.
+	rows() []int
+}
+
+// newLoadingStyle returns the loading style called name, drawn in charset:
+// one of config.LoadingStyles.
+func newLoadingStyle(name string, charset terminal.Charset) loadingStyle {
+	switch name {
+	case "spinner":
+		return spinnerStyle{charset: charset}
+	case "dots":
+		return &dotsStyle{}
+	case "starfield":
+		return newStarfield()
+	}
+	return barStyle{charset: charset}
+}
+
+// lineAt moves to the start of row and clears it.
+func lineAt(row int) string {
+	return Esc + strconv.Itoa(row) + ";1f" + Esc + "K"
+}
+
+// percent writes done as a percentage, dimmed to sit after the status.
+func percent(done float64) string {
+	return BlackHi + fmt.Sprintf(" %d%%", int(math.Round(done*100))) + Reset
+}
+
+// barStyle is the classic block bar, filled as the load goes.
+type barStyle struct {
+	charset terminal.Charset
+}
+
+func (s barStyle) frame(w io.Writer, n int, done float64, status string) {
+	filled := int(math.Round(done * 10))
+	fmt.Fprint(w, lineAt(loadingRow)+" "+s.charset.Bar(filled, 10)+" "+Green+status+Reset)
+}
+
+func (barStyle) rows() []int { return []int{loadingRow} }
+
+// spinnerStyle turns a one-cell spinner beside the status and how far
+// along the load is.
+type spinnerStyle struct {
+	charset terminal.Charset
+}
+
+func (s spinnerStyle) frame(w io.Writer, n int, done float64, status string) {
+	fmt.Fprint(w, lineAt(loadingRow)+" "+Cyan+s.charset.Spinner(n/2)+Reset+" "+Green+status+Reset+percent(done))
+}
+
+func (spinnerStyle) rows() []int { return []int{loadingRow} }
+
+// dotsStyle types the status out a few letters a frame, like a teletype,
+// then trails dots after it while it holds. A new status that starts like
+// the last one, such as a count of seconds going up, types only what
+// changed.
+type dotsStyle struct {
+	text  string
+	typed int
+}
+
+// typeSpeed is how many letters dotsStyle types a frame.
+const typeSpeed = 2
+
+func (s *dotsStyle) frame(w io.Writer, n int, done float64, status string) {
+	if status != s.text {
+		same := 0
+		for same < min(len(status), len(s.text), s.typed) && status[same] == s.text[same] {
+			same++
+		}
+		s.text, s.typed = status, same
+	}
+	dots := ""
+	if s.typed < len(s.text) {
+		s.typed = min(s.typed+typeSpeed, len(s.text))
+	} else {
+		dots = strings.Repeat(".", n/3%4)
+	}
+	fmt.Fprint(w, lineAt(loadingRow)+" "+Green+s.text[:s.typed]+dots+Reset)
+}
+
+func (*dotsStyle) rows() []int { return []int{loadingRow} }
+
+// starfield flies stars past on the rows above the status, the nearer
+// ones faster and brighter. Stars move every other frame, and only they
+// and a changed status are redrawn, to go easy on slow lines.
+type starfield struct {
+	stars  []star
+	status string // the status line last drawn
+}
+
+// star is one star of a starfield: its row and column, and how many columns
+// it moves a frame.
+type star struct {
+	row, col, speed int
+}
+
+// Starfield size: its rows, above the status, and how many stars fly.
+const (
+	starfieldTop   = 8
+	starfieldRows  = 4
+	starfieldWidth = 78
+	starCount      = 14
+)
+
+// starLooks are how stars are drawn by their speed: far, nearer and near.
+var starLooks = []string{"", BlackHi + ".", White + "+", WhiteHi + "*"}
+
+func newStarfield() *starfield {
+	// The same sky every time; only the motion matters
+	rng := rand.New(rand.NewSource(1))
+	s := &starfield{}
+	for range starCount {
+		s.stars = append(s.stars, star{
+			row:   starfieldTop + rng.Intn(starfieldRows),
+			col:   rng.Intn(starfieldWidth),
+			speed: 1 + rng.Intn(len(starLooks)-1),
+		})
+	}
+	return s
+}
+
+func (s *starfield) frame(w io.Writer, n int, done float64, status string) {
+	col := func(st star, step int) int {
+		return ((st.col-step*st.speed)%starfieldWidth+starfieldWidth)%starfieldWidth + 2
+	}
+	var b strings.Builder
+	step := n / 2
+	if n%2 == 0 {
+		if step > 0 {
+			// Blank every star where it was before drawing any where it is,
+			// so none is rubbed out by another that just left its cell
+			for _, st := range s.stars {
+				fmt.Fprintf(&b, Esc+"%d;%df ", st.row, col(st, step-1))
+			}
+		}
+		for _, st := range s.stars {
+			fmt.Fprintf(&b, Esc+"%d;%df%s"+Reset, st.row, col(st, step), starLooks[st.speed])
+		}
+	}
+	if line := lineAt(loadingRow) + " " + Green + status + Reset + percent(done); line != s.status {
+		b.WriteString(line)
+		s.status = line
+	}
+	fmt.Fprint(w, b.String())
+}
+
+func (*starfield) rows() []int {
+	rows := []int{loadingRow}
+	for i := range starfieldRows {
+		rows = append(rows, starfieldTop+i)
+	}
+	return rows
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"os"
+
+	"github.com/robbiew/history/internal/dropfile"
+)
+
+// localOptions describes the caller used by -local, which runs without any
+// dropfile at all.
+type localOptions struct {
+	Enabled  bool
+	UserName string
+	Cols     int
+	Rows     int
+}
+
+// registerLocalFlags adds the -local flag family to fs. The user name defaults
+// to $HISTORY_USER, then $USER.
+func registerLocalFlags(fs *flag.FlagSet) *localOptions {
+	l := &localOptions{}
+	user := cmp.Or(os.Getenv("HISTORY_USER"), os.Getenv("USER"), "Sysop")
+	fs.BoolVar(&l.Enabled, "local", false, "run from a shell without a dropfile (no -path needed)")
+	fs.StringVar(&l.UserName, "local-user", user, "user name for -local (default $HISTORY_USER or $USER)")
+	fs.IntVar(&l.Cols, "local-cols", 0, "terminal width for -local (default $COLUMNS or 80)")
+	fs.IntVar(&l.Rows, "local-rows", 0, "terminal height for -local (default $LINES or 25)")
+	return l
+}
+
+// session returns the door session a dropfile would otherwise provide.
+func (l *localOptions) session() *dropfile.DoorSession {
+	return &dropfile.DoorSession{
+		Format:    "local",
+		BbsName:   "Local",
+		UserName:  l.UserName,
+		RealName:  l.UserName,
+		SecLevel:  255,
+		TimeLeft:  60,
+		Emulation: dropfile.EmulationANSI,
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/robbiew/history/internal/logsink"
+	"github.com/robbiew/history/internal/metrics"
+	"github.com/robbiew/history/internal/stats"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// logTag is what the door's entries in syslog and the journal are tagged
+// with.
+const logTag = "history"
+
+// setupLogging routes the standard logger and slog to output, one of
+// config.LogOutputs: syslog, the systemd journal, or a JSON log file at path.
+// Without a file, logs keep going to stderr as before. The returned function
+// closes the log.
+func setupLogging(output, path string) (func(), error) {
+	switch output {
+	case "syslog", "journald":
+		h, err := logsink.Open(output, logTag)
+		if err != nil {
+			return nil, err
+		}
+		slog.SetDefault(slog.New(h))
+		return func() { h.Close() }, nil
+	}
+	if path == "" {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(f, nil)))
+	return func() { f.Close() }, nil
+}
+
+// observeRequests logs every Wikimedia API attempt with its latency, status and
+// retry number, and mirrors it into the stats file, so a slow upstream can be
+// told apart from a broken install. Failed attempts are counted in m.
+func observeRequests(wikiClient *wikimedia.Client, rec *stats.Recorder, m *metrics.Session, node int) {
+	wikiClient.OnRequest(func(info wikimedia.RequestInfo) {
+		attrs := []any{
+			"url", info.URL,
+			"attempt", info.Attempt,
+			"status", info.Status,
+			"latency_ms", info.Latency.Milliseconds(),
+			"bytes", info.Bytes,
+			"node", node,
+		}
+		fields := map[string]any{
+			"url":        info.URL,
+			"attempt":    info.Attempt,
+			"retry":      info.Attempt > 1,
+			"status":     info.Status,
+			"latency_ms": info.Latency.Milliseconds(),
+			"bytes":      info.Bytes,
+			"node":       node,
+		}
+		if info.Err != nil || info.Status != 200 {
+			if info.Err != nil {
+				attrs = append(attrs, "error", info.Err.Error())
+				fields["error"] = info.Err.Error()
+			}
+			slog.Warn("api request failed", attrs...)
+			m.APIError()
+		} else {
+			slog.Info("api request", attrs...)
+		}
+		if err := rec.Record("api_request", fields); err != nil {
+			slog.Warn("failed to write stats", "error", err)
+		}
+	})
+}
+
+// endMetrics adds the session to the day's metrics summary, logging rather
+// than failing if it can't be written.
+func endMetrics(m *metrics.Session) {
+	if err := m.End(); err != nil {
+		slog.Warn("could not write metrics", "error", err)
+	}
+}
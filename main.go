@@ -2,51 +2,57 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	cryptorand "crypto/rand"
 	_ "embed"
+	"encoding/binary"
+	"errors"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"log/slog"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
-	"context"
 	"sync"
-	"math/rand"
-	"path/filepath"
-	"sort"
+	"time"
+	_ "time/tzdata" // zone names for -timezone on systems without a zone database
 	"unicode"
- 
-	"encoding/json"
-	"io"
-	"net/http"
- 
-	"github.com/mattn/go-tty"
+	"unicode/utf8"
+
+	"github.com/robbiew/history/internal/almanac"
+	"github.com/robbiew/history/internal/category"
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/era"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/locale"
+	"github.com/robbiew/history/internal/metrics"
+	"github.com/robbiew/history/internal/nodelock"
+	"github.com/robbiew/history/internal/notice"
+	"github.com/robbiew/history/internal/pinned"
+	"github.com/robbiew/history/internal/ratings"
+	"github.com/robbiew/history/internal/scene"
+	"github.com/robbiew/history/internal/seen"
+	"github.com/robbiew/history/internal/serial"
+	"github.com/robbiew/history/internal/stats"
+	"github.com/robbiew/history/internal/taglines"
 	"github.com/robbiew/history/internal/terminal"
-	"github.com/robbiew/history/internal/wikimedia"
+	"github.com/robbiew/history/internal/tty"
+	"github.com/robbiew/history/pkg/textwrap"
+	"github.com/robbiew/history/pkg/wikimedia"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/text/unicode/norm"
 )
 
-// Holds a collection of types from Door32.sys dropfile
-type Door32Drop struct {
-	Node          int
-	BbsName       string
-	UserName      string
-	RealName      string
-	SecLevel      int
-	TimeLeft      int
-	Emulation     int
-	CommPort      int
-	BaudRate      int
-	UserNumber    int
-	// Additional terminal capabilities
-	Terminal      string
-	LoadableFonts bool
-	XtendPalette  bool
-	Cols          int
-	Rows          int
-}
-
 const (
 	Esc         = "\u001B["
 	Osc         = "\u001B]"
@@ -90,29 +96,17 @@ const (
 	BgWhiteHi   = Esc + "47;1m"
 )
 
-
-// NewTimer boots a user after being idle too long
-func NewTimer(seconds int, action func()) *time.Timer {
-	timer := time.NewTimer(time.Second * time.Duration(seconds))
-
-	go func() {
-		<-timer.C
-		action()
-	}()
-	return timer
-}
-
 // DetectTerminalCapabilities detects terminal type and capabilities based on environment
 func DetectTerminalCapabilities() (string, bool, bool, int, int) {
 	var terminal string
 	var loadableFonts bool
 	var xtendPalette bool
 	var cols, rows int = 80, 25 // default values
-	
+
 	// Get terminal type from environment variables
 	termType := strings.ToLower(os.Getenv("TERM"))
 	termProgram := strings.ToLower(os.Getenv("TERM_PROGRAM"))
-	
+
 	// Try to get terminal size from environment
 	if colsStr := os.Getenv("COLUMNS"); colsStr != "" {
 		if c, err := strconv.Atoi(colsStr); err == nil {
@@ -124,7 +118,7 @@ func DetectTerminalCapabilities() (string, bool, bool, int, int) {
 			rows = r
 		}
 	}
-	
+
 	// Detect terminal capabilities based on TERM environment or program
 	if termType == "ansi-256color-rgb" || cols > 80 {
 		terminal = "Netrunner"
@@ -135,257 +129,67 @@ func DetectTerminalCapabilities() (string, bool, bool, int, int) {
 	} else {
 		terminal = "ANSI-Term"
 	}
-	
+
 	// Set capabilities based on terminal type
 	if terminal == "Netrunner" || terminal == "ANSI-Term" || terminal == "Magiterm" {
 		loadableFonts = false
 	} else {
 		loadableFonts = true
 	}
-	
-	if terminal == "Syncterm" || terminal == "Netrunner" || terminal == "Magiterm" {
+
+	if terminal == "Syncterm" || terminal == "Netrunner" || terminal == "Magiterm" || strings.Contains(termType, "256color") {
 		xtendPalette = true
 	} else {
 		xtendPalette = false
 	}
-	
+
 	return terminal, loadableFonts, xtendPalette, cols, rows
 }
 
 // Move cursor to X, Y location
 func MoveCursor(x int, y int) {
-	fmt.Printf(Esc+"%d;%df", y, x)
+	fmt.Fprintf(terminal.Stdout, Esc+"%d;%df", y, x)
 }
 
 // Erase the screen
 func ClearScreen() {
-	fmt.Print(EraseScreen)
+	fmt.Fprint(terminal.Stdout, EraseScreen)
 	MoveCursor(0, 0)
 }
 
-// Returns door32.sys values as strings: commport, baudind, baudrate, bbsname, usernum, realname, username, seclevel, timeleft, emulation, node
-func DropFileData(path string) (string, string, string, string, string, string, string, string, string, string, string, error) {
-	var commport string
-	var baudind string
-	var baudrate string
-	var bbsname string
-	var usernum string
-	var realname string
-	var username string
-	var seclevel string
-	var timeleft string
-	var emulation string
-	var node string
-
-	cleanPath := filepath.Clean(path)
-
-	// Determine if the provided path is a file or directory.
-	var filePath string
-	if fi, err := os.Stat(cleanPath); err == nil && !fi.IsDir() {
-		// Provided path is a file; use it directly.
-		filePath = cleanPath
-	} else {
-		// Treat as directory: look for a case-insensitive "door32.sys"
-		dirPath := cleanPath
-		entries, err := os.ReadDir(dirPath)
-		if err != nil {
-			return "", "", "", "", "", "", "", "", "", "", "", fmt.Errorf("error reading directory %s: %v", dirPath, err)
-		}
-		found := ""
-		for _, e := range entries {
-			if strings.EqualFold(e.Name(), "door32.sys") {
-				found = filepath.Join(dirPath, e.Name())
-				break
-			}
-		}
-		if found == "" {
-			// As a fallback, also accept a direct filename appended (in case caller passed a directory-like string that didn't stat)
-			possible := filepath.Join(dirPath, "door32.sys")
-			if _, err := os.Stat(possible); err == nil {
-				found = possible
-			}
-		}
-		if found == "" {
-			return "", "", "", "", "", "", "", "", "", "", "", fmt.Errorf("door32.sys not found in %s", dirPath)
-		}
-		filePath = found
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", "", "", "", "", "", "", "", "", "", "", fmt.Errorf("error opening %s: %v", filePath, err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanLines)
-	var text []string
-
-	for scanner.Scan() {
-		text = append(text, scanner.Text())
-	}
-
-	count := 0
-	for _, line := range text {
-		if count == 0 {
-			commport = line
-		}
-		if count == 1 {
-			baudind = line
-		}
-		if count == 2 {
-			baudrate = line
-		}
-		if count == 3 {
-			bbsname = line
-		}
-		if count == 4 {
-			usernum = line
-		}
-		if count == 5 {
-			realname = line
-		}
-		if count == 6 {
-			username = line
-		}
-		if count == 7 {
-			seclevel = line
-		}
-		if count == 8 {
-			timeleft = line
-		}
-		if count == 9 {
-			emulation = line
-		}
-		if count == 10 {
-			node = line
-		}
-		if count == 11 {
-			break
-		}
-		count++
-		continue
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", "", "", "", "", "", "", "", "", "", "", fmt.Errorf("scanner error: %v", err)
-	}
-	return commport, baudind, baudrate, bbsname, usernum, realname, username, seclevel, timeleft, emulation, node, nil
-}
-
 // Print text at an X, Y location
 func PrintStringLoc(text string, x int, y int) {
 	yLoc := y
 	s := bufio.NewScanner(strings.NewReader(text))
 	for s.Scan() {
-		fmt.Fprintf(os.Stdout, Esc+strconv.Itoa(yLoc)+";"+strconv.Itoa(x)+"f"+s.Text())
+		fmt.Fprint(terminal.Stdout, Esc+strconv.Itoa(yLoc)+";"+strconv.Itoa(x)+"f"+s.Text())
 		yLoc++
 	}
 }
 
-func getNumEnding() string {
-	// Correct ordinal suffix logic:
-	// - 11, 12, 13 -> "th"
-	// - otherwise, 1 -> "st", 2 -> "nd", 3 -> "rd", else "th"
-	n := time.Now().Day()
-	if n%100 >= 11 && n%100 <= 13 {
-		return "th"
-	}
-	switch n % 10 {
-	case 1:
-		return "st"
-	case 2:
-		return "nd"
-	case 3:
-		return "rd"
-	default:
-		return "th"
+// cyrillic spells Russian, Ukrainian and Belarusian letters in Latin ones,
+// much as passports do, so Cyrillic names read as names rather than as a
+// row of question marks.
+var cyrillic = func() *strings.Replacer {
+	latin := map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+		'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+		'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+		'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
 	}
-}
-
-// WikimediaEvent represents an event from the Wikimedia API
-type WikimediaEvent struct {
-	Year int    `json:"year"`
-	Text string `json:"text"`
-	Type string `json:"type"`
-}
-
-// WikimediaResponse represents the full response from Wikimedia API
-type WikimediaResponse struct {
-	Events []WikimediaEvent `json:"events"`
-	Births []WikimediaEvent `json:"births"`
-	Deaths []WikimediaEvent `json:"deaths"`
-}
-
-// wrapText breaks text into lines that fit within maxWidth (rune-aware)
-func wrapText(text string, maxWidth int) []string {
-	if maxWidth <= 0 {
-		// Defensive: non-positive width -> return original text as single line
-		return []string{text}
-	}
- 
-	runes := []rune(text)
-	if len(runes) <= maxWidth {
-		return []string{text}
-	}
- 
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{""}
-	}
- 
-	var lines []string
-	var current []rune
- 
-	for _, word := range words {
-		wr := []rune(word)
- 
-		if len(current) == 0 {
-			// Start a new line
-			if len(wr) <= maxWidth {
-				current = append(current, wr...)
-			} else {
-				// Word longer than maxWidth -> truncate with ellipsis if possible
-				if maxWidth > 3 {
-					lines = append(lines, string(wr[:maxWidth-3])+"...")
-				} else {
-					lines = append(lines, string(wr[:maxWidth]))
-				}
-			}
-			continue
+	var pairs []string
+	for r, l := range latin {
+		pairs = append(pairs, string(r), l)
+		if l != "" {
+			l = strings.ToUpper(l[:1]) + l[1:]
 		}
- 
-		// Attempt to add space + word
-		if len(current)+1+len(wr) <= maxWidth {
-			current = append(current, ' ')
-			current = append(current, wr...)
-		} else {
-			// Flush current line and start new one with word (or truncated word)
-			lines = append(lines, string(current))
-			current = nil
-			if len(wr) <= maxWidth {
-				current = append(current, wr...)
-			} else {
-				if maxWidth > 3 {
-					lines = append(lines, string(wr[:maxWidth-3])+"...")
-				} else {
-					lines = append(lines, string(wr[:maxWidth]))
-				}
-			}
-		}
-	}
- 
-	if len(current) > 0 {
-		lines = append(lines, string(current))
+		pairs = append(pairs, string(unicode.ToUpper(r)), l)
 	}
- 
-	if len(lines) == 0 {
-		return []string{""}
-	}
- 
-	return lines
-}
- 
+	return strings.NewReplacer(pairs...)
+}()
+
 // sanitizeText normalizes Unicode text (NFKD), strips combining marks (diacritics),
 // replaces common typographic punctuation with ASCII equivalents, and maps a small
 // set of problematic characters to CP437-friendly replacements.
@@ -393,6 +197,8 @@ func sanitizeText(s string) string {
 	if s == "" {
 		return s
 	}
+	// Spell Cyrillic in Latin letters, before NFKD splits й and ї apart
+	s = cyrillic.Replace(s)
 	// Normalize to NFKD to separate base runes + diacritics
 	n := norm.NFKD.String(s)
 	// Builder for ASCII output
@@ -405,7 +211,7 @@ func sanitizeText(s string) string {
 		}
 		switch r {
 		// common typographic punctuation
-		case '“', '”', '„', '˝':
+		case '“', '”', '„', '˝', '«', '»':
 			b.WriteRune('"')
 		case '‘', '’', '‚', '‛':
 			b.WriteRune('\'')
@@ -443,6 +249,16 @@ func sanitizeText(s string) string {
 					b.WriteString("oe")
 				case 'æ', 'Æ':
 					b.WriteString("ae")
+				case 'ł':
+					b.WriteRune('l')
+				case 'Ł':
+					b.WriteRune('L')
+				case 'đ':
+					b.WriteRune('d')
+				case 'Đ':
+					b.WriteRune('D')
+				case 'ı':
+					b.WriteRune('i')
 				default:
 					// Replace unknown non-ascii with '?'
 					b.WriteRune('?')
@@ -452,43 +268,125 @@ func sanitizeText(s string) string {
 	}
 	return b.String()
 }
- 
+
+// eastAsian reports whether language is the code of a Chinese, Japanese
+// or Korean edition, such as "ja" or "zh-yue".
+func eastAsian(language string) bool {
+	code, _, _ := strings.Cut(language, "-")
+	return code == "zh" || code == "ja" || code == "ko"
+}
+
+// displayText prepares text from the feed to be shown in charset. A UTF-8
+// terminal gets the text as it is, less control characters, wide CJK
+// characters and all; for a regional code page, the characters it has are
+// kept and the rest are sanitized; every other charset gets sanitizeText's
+// plain ASCII.
+func displayText(s string, charset terminal.Charset) string {
+	if charset == terminal.UTF8 {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return ' '
+			}
+			return r
+		}, norm.NFC.String(s))
+	}
+	cm := charset.Codepage()
+	if cm == nil {
+		return sanitizeText(s)
+	}
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if _, ok := cm.EncodeRune(r); ok && r >= utf8.RuneSelf && unicode.IsPrint(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(sanitizeText(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// localeFor loads the locale named by name with its names put in charset,
+// the way event text is.
+func localeFor(name string, charset terminal.Charset) *locale.Locale {
+	loc, err := locale.Load(name)
+	if err != nil {
+		// Already checked by cfg.Validate
+		fail(err)
+	}
+	return loc.Map(func(s string) string { return displayText(s, charset) })
+}
+
+// themeFor turns the theme settings into the renderer's.
+func themeFor(theme config.Theme) terminal.Theme {
+	return terminal.Theme{
+		Prefix:     theme.Prefix.Format,
+		YearWidth:  theme.Prefix.YearWidth,
+		Glyphs:     theme.Prefix.Color,
+		Eras:       theme.Eras,
+		HideLegend: !theme.Legend,
+		HangYear:   theme.Wrap.Hang == "year",
+		Indent:     theme.Wrap.Indent,
+		Justify:    theme.Wrap.Justify == "filled",
+	}
+}
+
+// headerFor is the header art a special day names, a built-in one or an
+// art file, or the door's own.
+func headerFor(special config.SpecialDay) terminal.Header {
+	if h, ok := terminal.Headers[special.Header]; ok {
+		return h
+	}
+	if special.Header == "" {
+		return terminal.Header{}
+	}
+	h, err := terminal.LoadHeader(special.Header)
+	if err != nil {
+		// Checked by cfg.Validate, but the file may have gone since
+		slog.Warn("could not read header art, using the door's own", "file", special.Header, "error", err)
+	}
+	return h
+}
+
+// paletteFor is the palette the theme draws in at now: its night palette
+// during its night hours, when that is on, or the door's own colors.
+func paletteFor(theme config.Theme, now time.Time) terminal.Palette {
+	night := theme.Night
+	if !night.Enabled || night.From == night.To {
+		return nil
+	}
+	h := now.Hour()
+	if night.From < night.To && h >= night.From && h < night.To ||
+		night.From > night.To && (h >= night.From || h < night.To) {
+		return terminal.Palette(night.Colors)
+	}
+	return nil
+}
+
 // selectEventsByEra selects a small, varied set of events using an era-based strategy.
 // It mirrors the era approach used in the JavaScript ENiGMA module: attempt to pick
-// a small quota from each era, then fill remaining slots with random events.
-func selectEventsByEra(allEvents []wikimedia.Event) []wikimedia.Event {
+// a small quota from each era, then fill remaining slots with random events from rng.
+func selectEventsByEra(allEvents []wikimedia.Event, rng *rand.Rand) []wikimedia.Event {
 	if len(allEvents) == 0 {
 		return nil
 	}
- 
-	type eraDef struct {
-		name       string
-		min, max   int
-		quota      int
-	}
- 
-	eras := []eraDef{
-		{name: "Ancient", min: 1, max: 500, quota: 1},
-		{name: "Medieval", min: 501, max: 1500, quota: 1},
-		{name: "Early Modern", min: 1501, max: 1800, quota: 1},
-		{name: "Modern", min: 1801, max: 1950, quota: 1},
-		{name: "Contemporary", min: 1951, max: 2030, quota: 1},
-	}
- 
+
+	// Each era contributes at most this many events in the first pass
+	const eraQuota = 1
+
 	// Helper to create a unique key for an event
 	keyFor := func(e wikimedia.Event) string {
 		return fmt.Sprintf("%d|%s", e.Year, e.Text)
 	}
- 
+
 	selected := make([]wikimedia.Event, 0, 5)
 	seen := make(map[string]bool)
- 
+
 	// First pass: try to select quota from each era
-	for _, era := range eras {
+	for _, e := range era.All {
 		// Collect eligible indices
 		var eraEvents []int
 		for i, ev := range allEvents {
-			if ev.Year >= era.min && ev.Year <= era.max {
+			if e.Contains(ev.Year) {
 				eraEvents = append(eraEvents, i)
 			}
 		}
@@ -496,9 +394,9 @@ func selectEventsByEra(allEvents []wikimedia.Event) []wikimedia.Event {
 			continue
 		}
 		// Shuffle indices
-		rand.Shuffle(len(eraEvents), func(i, j int) { eraEvents[i], eraEvents[j] = eraEvents[j], eraEvents[i] })
+		rng.Shuffle(len(eraEvents), func(i, j int) { eraEvents[i], eraEvents[j] = eraEvents[j], eraEvents[i] })
 		// Pick up to quota
-		for qi := 0; qi < era.quota && qi < len(eraEvents); qi++ {
+		for qi := 0; qi < eraQuota && qi < len(eraEvents); qi++ {
 			ev := allEvents[eraEvents[qi]]
 			k := keyFor(ev)
 			if !seen[k] {
@@ -513,7 +411,7 @@ func selectEventsByEra(allEvents []wikimedia.Event) []wikimedia.Event {
 			break
 		}
 	}
- 
+
 	// Fill remaining slots with random events if needed
 	if len(selected) < 5 {
 		// collect remaining indices not used
@@ -524,7 +422,7 @@ func selectEventsByEra(allEvents []wikimedia.Event) []wikimedia.Event {
 			}
 		}
 		if len(remaining) > 0 {
-			rand.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
+			rng.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
 			need := 5 - len(selected)
 			if need > len(remaining) {
 				need = len(remaining)
@@ -534,213 +432,228 @@ func selectEventsByEra(allEvents []wikimedia.Event) []wikimedia.Event {
 			}
 		}
 	}
- 
+
 	// Sort by year for stable display
 	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Year < selected[j].Year })
 	return selected
 }
 
-func displayLoadingAnimation(done <-chan bool, wg *sync.WaitGroup) {
-	loadingSteps := []struct {
-		bar   string
-		delay int
-	}{
-		{
-			bar:   " " + Cyan + "\xDB\xDB\xDB\xDB" + Reset + "\xB0\xB0\xB0\xB0\xB0\xB0 " + Green + "Fetching historical data" + Reset,
-			delay: 300,
-		},
-		{
-			bar:   " " + Cyan + "\xDB\xDB\xDB\xDB\xDB\xDB" + Reset + "\xB0\xB0\xB0\xB0 " + Green + "Processing events" + Reset,
-			delay: 400,
-		},
-		{
-			bar:   " " + Cyan + "\xDB\xDB\xDB\xDB\xDB\xDB\xDB\xDB" + Reset + "\xB0\xB0 " + Green + "Applying filters and sorting" + Reset,
-			delay: 600,
-		},
-		{
-			bar:   " " + Cyan + "\xDB\xDB\xDB\xDB\xDB\xDB\xDB\xDB\xDB\xDB " + Green + "Ready to display" + Reset,
-			delay: 300,
-		},
-	}
-	
-	loadingBarRow := 12
-	stepIndex := 0
-	
-	// Keep cycling through animation until done
+// displayLoadingAnimation draws the loading indicator in style from progress
+// until done, as the fetches move on, counting the seconds of a wait with
+// nothing coming back, with hint, if any, under it. A load over within
+// loadingBarDelay, as from the cache, draws nothing.
+func displayLoadingAnimation(style loadingStyle, progress *loadProgress, hint string, done <-chan bool, wg *sync.WaitGroup) {
+	var frame bytes.Buffer
+	shown := ""
+	n := 0
+	delay := time.After(loadingBarDelay)
+	var tick <-chan time.Time
+
 	for {
 		select {
 		case <-done:
-			// Clear the loading bar when done
-			MoveCursor(1, loadingBarRow)
-			fmt.Print(Esc + "K") // Clear the loading bar
+			// Clear the indicator when done
+			if shown != "" {
+				for _, row := range style.rows() {
+					MoveCursor(1, row)
+					fmt.Fprint(terminal.Stdout, Esc+"K")
+				}
+				if hint != "" {
+					MoveCursor(1, loadingHintRow)
+					fmt.Fprint(terminal.Stdout, Esc+"K")
+				}
+			}
+			terminal.Flush()
 			if wg != nil {
 				wg.Done()
 			}
 			return
-		case <-time.After(time.Duration(loadingSteps[stepIndex].delay) * time.Millisecond):
-			MoveCursor(1, loadingBarRow)
-			fmt.Print(Esc + "K") // Clear the line
-			fmt.Print(loadingSteps[stepIndex].bar)
-			stepIndex = (stepIndex + 1) % len(loadingSteps) // Cycle through steps
+		case <-delay:
+			ticker := time.NewTicker(100 * time.Millisecond)
+			defer ticker.Stop()
+			tick = ticker.C
+			if hint != "" {
+				MoveCursor(1, loadingHintRow)
+				fmt.Fprint(terminal.Stdout, " "+BlackHi+hint+Reset)
+			}
+		case <-tick:
+		}
+		if tick == nil {
+			continue
+		}
+		frame.Reset()
+		fraction, status := progress.state()
+		style.frame(&frame, n, fraction, status)
+		n++
+		if frame.String() != shown {
+			terminal.Stdout.Write(frame.Bytes())
+			terminal.Flush()
+			shown = frame.String()
 		}
 	}
 }
 
-func fetchHistoricalEvents() ([]WikimediaEvent, error) {
-	now := time.Now()
-	month := fmt.Sprintf("%02d", int(now.Month()))
-	day := fmt.Sprintf("%02d", now.Day())
-
-	url := fmt.Sprintf("https://api.wikimedia.org/feed/v1/wikipedia/en/onthisday/all/%s/%s", month, day)
+// eventListOptions controls how generateEventList fetches and selects events.
+type eventListOptions struct {
+	BypassCache bool
+	Shuffle     bool
+	Strategy    string
+	// Date is the calendar day to display; only the month and day are used.
+	Date time.Time
+	// LeapBlend mixes Feb 28 and Mar 1 events into a sparse Feb 29 feed.
+	LeapBlend bool
+	// Deadline bounds each fetch, retries included.
+	Deadline time.Duration
+	// Sections are the extra screens (births, deaths, holidays, featured, news) shown after the events.
+	Sections []string
+	// Preview, when set, can force the error or empty screens instead of fetching.
+	Preview *previewOptions
+	// OnError, when set, is told when the events can't be fetched.
+	OnError func(error)
+	// Notices write the screens shown in place of the pages when there are
+	// none, or before an old copy of them when the fetch failed; nil uses
+	// the built-in English ones. RefreshKey and CachedKey are the keys that
+	// load the day again and show the old copy, for them to mention.
+	Notices    *notice.Set
+	RefreshKey string
+	CachedKey  string
+	// Categories, when set, limits the picks to events in them.
+	Categories []string
+	// Anniversaries tags the entries that are a round anniversary this year.
+	Anniversaries bool
+	// Scene blends the built-in scene milestones into the events, keeping
+	// one on screen on a day that has any.
+	Scene bool
+	// DailySalt is mixed into the daily strategy's seed: the BBS name when
+	// daily_by_bbs is set, so each board gets its own picks.
+	DailySalt string
+	// Rand is the session's randomness, for strategies not seeded by the day.
+	Rand *rand.Rand
+	// Seen, when set, holds what the caller has been shown today, and Board
+	// what every caller has; those events are picked only when there aren't
+	// enough others, the caller's own last.
+	Seen  *seen.Log
+	Board *seen.Log
+	// Ratings, when set, are the board's votes on events, and Favorites how
+	// many of the best rated to put first.
+	Ratings   *ratings.Book
+	Favorites int
+	// Pinned, when set, is the sysop's Event of the Day, put first on the
+	// events screen ahead of the strategy's picks.
+	Pinned *wikimedia.Event
+	// Keys, when set, are read while loading. A key Keep keeps is put by for
+	// the screens and the fetch goes on; any other stops the fetch, and the
+	// day's cached copy is shown if there is one. Interrupted, when set, is
+	// told each key pressed, in order.
+	Keys        *input.Decoder
+	Keep        func(input.Event) bool
+	Interrupted func(input.Event)
+	// LoadingStyle is how the loading screen is drawn, one of
+	// config.LoadingStyles.
+	LoadingStyle string
+	// Metrics, when set, counts the fetches the cache answered and those
+	// that went to the API.
+	Metrics *metrics.Session
+}
 
-	// Retry strategy
-	const maxAttempts = 3
-	backoff := 500 * time.Millisecond
+// leapBlendMin is the number of Feb 29 events below which neighbouring days are blended in.
+const leapBlendMin = 15
 
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Use context with timeout for each attempt
-		ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
+// isLeapDay reports whether t falls on February 29.
+func isLeapDay(t time.Time) bool {
+	return t.Month() == time.February && t.Day() == 29
+}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// fetchLeapNeighbours fetches Feb 28 and Mar 1 and tags each event with the day it
+// came from. Each day goes through the client under its own month/day so the cache
+// entry for 02/29 only ever holds the leap day's own payload.
+func fetchLeapNeighbours(ctx context.Context, wikiClient *wikimedia.Client, bypassCache bool, deadline time.Duration) []wikimedia.Event {
+	neighbours := []struct{ month, day, label string }{
+		{"02", "28", "Feb 28"},
+		{"03", "01", "Mar 1"},
+	}
+	var out []wikimedia.Event
+	for _, n := range neighbours {
+		ctx, cancel := context.WithTimeout(ctx, deadline)
+		res, err := wikiClient.FetchOnThisDay(ctx, n.month, n.day, bypassCache)
+		cancel()
 		if err != nil {
-			cancel()
-			return nil, err
-		}
-
-		req.Header.Set("User-Agent", "Go Day-in-History BBS Door/1.0 (github.com/robbiew/history)")
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("Accept-Encoding", "identity")
-
-		client := &http.Client{
-			// Let context handle timeouts; keep a reasonable transport timeout if desired.
-			Timeout: 0,
+			log.Printf("leap day: failed to fetch %s events: %v", n.label, err)
+			continue
 		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			cancel()
-			// Retry on transient network errors
-			if attempt < maxAttempts {
-				jitter := time.Duration(rand.Int63n(200))*time.Millisecond - 100*time.Millisecond
-				time.Sleep(backoff + jitter)
-				backoff *= 2
-				continue
-			}
-			return nil, fmt.Errorf("network error: %v", err)
+		for _, e := range res.Events {
+			e.Text = "(" + n.label + ") " + e.Text
+			out = append(out, e)
 		}
+	}
+	return out
+}
 
-		// Ensure body is closed for this attempt
-		body, readErr := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		cancel()
-		if readErr != nil {
-			if attempt < maxAttempts {
-				jitter := time.Duration(rand.Int63n(200))*time.Millisecond - 100*time.Millisecond
-				time.Sleep(backoff + jitter)
-				backoff *= 2
-				continue
-			}
-			return nil, fmt.Errorf("failed to read response: %v", readErr)
-		}
-
-		// Accept HTTP 200. Retry on 429 or 5xx.
-		if resp.StatusCode == http.StatusOK {
-			var wikimediaResp WikimediaResponse
-			if err := json.Unmarshal(body, &wikimediaResp); err != nil {
-				return nil, fmt.Errorf("failed to parse JSON: %v", err)
-			}
-
-			var allEvents []WikimediaEvent
-			for _, event := range wikimediaResp.Events {
-				event.Type = "event"
-				allEvents = append(allEvents, event)
-			}
-			// births/deaths intentionally excluded for a cleaner display
-
-			// Shuffle deterministically seeded at startup
-			if len(allEvents) > 1 {
-				for i := len(allEvents) - 1; i > 0; i-- {
-					j := rand.Intn(i + 1)
-					allEvents[i], allEvents[j] = allEvents[j], allEvents[i]
-				}
-			}
-			return allEvents, nil
-		}
+// fetchDay fetches the events feed and any extra sections concurrently, sharing
+// the client's retry and backoff logic. Only an events failure is returned; an
+// extra section that fails is logged and left out of the map. The fetches end
+// at deadline, or sooner when ctx does.
+func fetchDay(ctx context.Context, wikiClient *wikimedia.Client, month, day string, sections []string, bypassCache bool, deadline time.Duration) (*wikimedia.Result, map[string]*wikimedia.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+	g, ctx := errgroup.WithContext(ctx)
 
-		// Retryable statuses
-		if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && resp.StatusCode < 600) {
-			if attempt < maxAttempts {
-				jitter := time.Duration(rand.Int63n(200))*time.Millisecond - 100*time.Millisecond
-				time.Sleep(backoff + jitter)
-				backoff *= 2
-				continue
+	var events *wikimedia.Result
+	g.Go(func() error {
+		res, err := wikiClient.FetchOnThisDay(ctx, month, day, bypassCache)
+		events = res
+		return err
+	})
+	extras := make([]*wikimedia.Result, len(sections))
+	for i, section := range sections {
+		g.Go(func() error {
+			res, err := wikiClient.FetchSection(ctx, section, month, day, bypassCache)
+			if err != nil {
+				log.Printf("failed to fetch %s for %s/%s: %v", section, month, day, err)
+				return nil
 			}
-			return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
-		}
-
-		// Non-retryable status
-		return nil, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+			extras[i] = res
+			return nil
+		})
 	}
-
-	return nil, fmt.Errorf("failed to fetch events after %d attempts", maxAttempts)
-}
-
-func generateEventList(termCfg terminal.TerminalConfig, wikiClient *wikimedia.Client, bypassCache, shuffle bool, strategy string) {
-	// Start loading animation in background and fetch events concurrently
-	done := make(chan bool)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go displayLoadingAnimation(done, &wg)
-	
-	// Determine month/day and fetch using provided client with a context timeout
-	now := time.Now()
-	monthStr := fmt.Sprintf("%02d", int(now.Month()))
-	dayStr := fmt.Sprintf("%02d", now.Day())
-	
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	events, err := wikiClient.FetchOnThisDay(ctx, monthStr, dayStr, bypassCache)
-	cancel()
-	
-	// Stop the loading animation
-	done <- true
-	close(done)
-	// Wait for the loader to finish clearing the line before continuing
-	wg.Wait()
-	
-	// If fetching failed or no events, render an appropriate message using the existing quick path
-	if err != nil {
-		ClearScreen()
-		MoveCursor(1, 8)
-		fmt.Printf(RedHi+"Error fetching events: %v"+Reset+"\r\n", err)
-		fmt.Print(WhiteHi+"Please check your internet connection and try again."+Reset+"\r\n")
-		MoveCursor(1, 24)
-		fmt.Print("                   " + BgBlueHi + WhiteHi + "<" + Reset + Cyan + "<  " + BlackHi + "... " + Reset + White + "press " + WhiteHi + "ANY KEY " + Reset + White + "to " + WhiteHi + "CONTINUE " + Reset + BlackHi + "... " + Reset + Cyan + ">" + BgBlue + WhiteHi + ">" + Reset)
-		return
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
 	}
 
-	if len(events) == 0 {
-		ClearScreen()
-		MoveCursor(1, 8)
-		fmt.Print(YellowHi + "No historical events found for today." + Reset + "\r\n")
-		MoveCursor(1, 24)
-		fmt.Print("                   " + BgBlueHi + WhiteHi + "<" + Reset + Cyan + "<  " + BlackHi + "... " + Reset + White + "press " + WhiteHi + "ANY KEY " + Reset + White + "to " + WhiteHi + "CONTINUE " + Reset + BlackHi + "... " + Reset + Cyan + ">" + BgBlue + WhiteHi + ">" + Reset)
-		return
+	bySection := make(map[string]*wikimedia.Result, len(sections))
+	for i, section := range sections {
+		if extras[i] != nil {
+			bySection[section] = extras[i]
+		}
 	}
+	return events, bySection, nil
+}
 
+// selectEvents applies the selection strategy and shuffle setting, returning at
+// most five events. Its randomness comes from rng, and anniversaries are
+// counted to thisYear.
+func selectEvents(events []wikimedia.Event, strategy string, shuffle bool, thisYear int, rng *rand.Rand) []wikimedia.Event {
 	// If shuffle requested and strategy is oldest-first, treat it as random selection
 	// so that -shuffle also randomizes which events are chosen (not just ordering).
 	if shuffle && strategy == "oldest-first" {
 		strategy = "random"
 	}
-	// Apply selection strategy (era-based, random, oldest-first)
+	// Apply selection strategy (era-based, daily, random, oldest-first, anniversary, science)
 	switch strategy {
-	case "era-based":
-		if sel := selectEventsByEra(events); len(sel) > 0 {
+	case "era-based", "daily":
+		// daily differs only in rng, which is seeded by the day
+		if sel := selectEventsByEra(events, rng); len(sel) > 0 {
+			events = sel
+		}
+	case "anniversary":
+		events = selectAnniversaries(events, thisYear, rng)
+	case "science":
+		if sel := selectEventsByEra(inCategories(events, []string{"science"}), rng); len(sel) > 0 {
 			events = sel
 		}
 	case "random":
 		if len(events) > 1 {
-			rand.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
+			rng.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
 		}
 		if len(events) > 5 {
 			events = events[:5]
@@ -755,121 +668,861 @@ func generateEventList(termCfg terminal.TerminalConfig, wikiClient *wikimedia.Cl
 	// source-balanced strategy removed (not implemented)
 	default:
 		// Unknown strategy -> fallback to era-based
-		if sel := selectEventsByEra(events); len(sel) > 0 {
+		if sel := selectEventsByEra(events, rng); len(sel) > 0 {
 			events = sel
 		}
 	}
-	
+
 	// If the global shuffle flag is set, randomize the order of the selected events
 	if shuffle && len(events) > 1 {
-		rand.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
+		rng.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
 	}
-	
-	// Convert events to terminal-friendly types and render using the provided terminal config
+	return events
+}
+
+// selectionRand returns the randomness for picking a section's entries on
+// day. The daily strategy seeds it from the day, the section and salt, so
+// every caller that day gets the same picks in the same order; other
+// strategies use the session's own source. A day with no year, from -date,
+// is taken as this year's.
+func selectionRand(strategy string, day time.Time, section, salt string, session *rand.Rand) *rand.Rand {
+	if strategy != "daily" {
+		return session
+	}
+	// From the fields, as time.Date would make 02-29 of a common year March 1
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%04d-%02d-%02d|%s|%s", calendarYear(day), int(day.Month()), day.Day(), section, salt)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// sessionRand returns a source of randomness for one session, seeded from
+// the system's random numbers, or from the clock and node when those can't
+// be read. Doors on different nodes that start in the same instant still
+// shuffle differently, and none of them share the global source's lock.
+func sessionRand(node int) *rand.Rand {
+	var seed [8]byte
+	if _, err := cryptorand.Read(seed[:]); err != nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano() ^ int64(node)<<48))
+	}
+	return rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:]))))
+}
+
+// toTerminalEvents converts feed events to the renderer's type, with their
+// text ready to show in charset.
+func toTerminalEvents(events []wikimedia.Event, charset terminal.Charset) []terminal.Event {
 	var tevents []terminal.Event
 	for _, e := range events {
-		tevents = append(tevents, terminal.Event{Year: e.Year, Text: sanitizeText(e.Text)})
+		te := terminal.Event{Year: e.Year, Text: displayText(e.Text, charset), Key: sanitizeText(e.Text), Era: era.Of(e.Year)}
+		te.Category, _ = categoryOf(e)
+		if len(e.Pages) > 0 {
+			te.Title, te.URL = displayText(e.Pages[0].Title, charset), e.Pages[0].URL
+			for _, p := range e.Pages[1:] {
+				te.Related = append(te.Related, displayText(p.Title, charset))
+			}
+		}
+		tevents = append(tevents, te)
+	}
+	return tevents
+}
+
+// generateEventList fetches the day and returns its pages: the events first,
+// then any extra sections (births, deaths, holidays, featured, news). When there is nothing to
+// show it returns a notice page saying why instead, and when the events are
+// an old copy served because the fetch failed, a notice comes first, offering
+// the copy or another try. It returns nil once ctx has ended. Fetching stops
+// when ctx ends, and nothing more is drawn for a session that is over. A key
+// pressed while loading that opts.Keep doesn't keep stops it too, leaving the
+// day's cached copy if there is one.
+func generateEventList(ctx context.Context, termCfg terminal.TerminalConfig, wikiClient *wikimedia.Client, opts eventListOptions) []terminal.Page {
+	bypassCache := opts.BypassCache
+	fetchCtx, stopped := stopOnKey(ctx, opts.Keys, opts.Keep)
+
+	// Start loading animation in background and fetch events concurrently
+	hint := ""
+	if opts.Keys != nil {
+		hint = "Press an unused key to stop waiting"
+	}
+	done := make(chan bool)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	progress := newLoadProgress(slices.Concat([]string{wikimedia.SectionEvents}, opts.Sections))
+	fetchCtx = wikimedia.WithProgress(fetchCtx, progress.update)
+	go displayLoadingAnimation(newLoadingStyle(opts.LoadingStyle, termCfg.Charset), progress, hint, done, &wg)
+
+	// Determine month/day and fetch using provided client with a context timeout
+	date := opts.Date
+	monthStr := fmt.Sprintf("%02d", int(date.Month()))
+	dayStr := fmt.Sprintf("%02d", date.Day())
+
+	var events []wikimedia.Event
+	var sections map[string]*wikimedia.Result
+	var cachedAt, fetchedAt time.Time
+	var staleErr error // why the events are an old copy, if they are
+	var err error
+	if opts.Preview != nil && opts.Preview.Screen != "" {
+		// Forced preview screen: skip the network so sysops can see the fallback screens
+		time.Sleep(time.Second)
+		err = opts.Preview.simulatedError()
+	} else {
+		var res *wikimedia.Result
+		res, sections, err = fetchDay(fetchCtx, wikiClient, monthStr, dayStr, opts.Sections, bypassCache, opts.Deadline)
+		if err == nil {
+			events, fetchedAt = res.Events, res.FetchedAt
+			if res.Stale && !res.Fallback {
+				// The built-in dataset is shown as it is, with no copy to offer
+				cachedAt, staleErr = res.FetchedAt, res.Err
+			}
+		}
+	}
+
+	// Feb 29 only has a handful of entries; pad it out with the surrounding days.
+	if err == nil && opts.LeapBlend && isLeapDay(date) && len(events) < leapBlendMin {
+		events = append(events, fetchLeapNeighbours(fetchCtx, wikiClient, bypassCache, opts.Deadline)...)
+	}
+
+	// The scene's own milestones join Wikipedia's for the strategy to pick from
+	var milestones []wikimedia.Event
+	if err == nil && opts.Scene {
+		milestones = scene.On(date.Month(), date.Day())
+		events = append(events, milestones...)
+	}
+
+	// Stop the loading animation
+	done <- true
+	close(done)
+	// Wait for the loader to finish clearing the line before continuing
+	wg.Wait()
+	hits, misses := progress.cacheUse()
+	for range hits {
+		opts.Metrics.CacheHit()
+	}
+	for range misses {
+		opts.Metrics.CacheMiss()
+	}
+	for _, key := range stopped() {
+		if opts.Interrupted != nil {
+			opts.Interrupted(key)
+		}
+	}
+	if ctx.Err() != nil {
+		// The caller is gone or out of time; there is no one to tell
+		return nil
+	}
+
+	// With nothing to show, a notice says why and what the caller can do
+	d := notice.Data{Date: date, RefreshKey: opts.RefreshKey, CachedKey: opts.CachedKey, Now: time.Now()}
+	stoppedByKey := errors.Is(context.Cause(fetchCtx), errLoadStopped)
+	if err != nil && stoppedByKey {
+		// Stopped with nothing cached to fall back on; not the API's fault
+		d.LastFetched = wikiClient.LastFetched()
+		return []terminal.Page{noticePage(termCfg, opts.Notices, notice.Stopped, d)}
+	}
+	if err != nil {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+		d.Err = err.Error()
+		d.Cause, d.Status = failureCause(err)
+		d.RetryAt, d.LastFetched = wikiClient.RetryAt(), wikiClient.LastFetched()
+		return []terminal.Page{noticePage(termCfg, opts.Notices, notice.Error, d)}
+	}
+
+	if len(events) == 0 {
+		d.CachedAt = fetchedAt
+		return []terminal.Page{noticePage(termCfg, opts.Notices, notice.Empty, d)}
+	}
+
+	// The sysop's pin takes slot one, in place of the same event in the feed
+	var pinned []terminal.Event
+	if pin := opts.Pinned; pin != nil {
+		events = slices.DeleteFunc(events, func(e wikimedia.Event) bool {
+			return e.Year == pin.Year && sanitizeText(e.Text) == sanitizeText(pin.Text)
+		})
+		pinned = toTerminalEvents([]wikimedia.Event{*pin}, termCfg.Charset)
+		pinned[0].Pinned = true
+	}
+	pool := slices.Concat(pinned, toTerminalEvents(events, termCfg.Charset))
+	picks := withScene(pickEvents(events, wikimedia.SectionEvents, date, opts), milestones, 5-len(pinned), date, opts)
+	picked := slices.Concat(pinned, toTerminalEvents(picks, termCfg.Charset))
+	pages := []terminal.Page{{Kind: terminal.KindEvents, Date: date, Events: picked, Pool: pool, CachedAt: cachedAt}}
+
+	// Extra sections follow in the configured order; holidays and news keep the feed's order
+	for _, section := range opts.Sections {
+		res, ok := sections[section]
+		if !ok || len(res.Events) == 0 {
+			continue
+		}
+		entries := res.Events
+		switch section {
+		case wikimedia.SectionHolidays, wikimedia.SectionNews:
+			if len(entries) > 5 {
+				entries = entries[:5]
+			}
+		case wikimedia.SectionFeatured:
+			// A single article, shown as it comes
+		default:
+			entries = pickEvents(entries, section, date, opts)
+		}
+		page := terminal.Page{Kind: section, Date: date, Events: toTerminalEvents(entries, termCfg.Charset)}
+		if section != wikimedia.SectionFeatured {
+			page.Pool = toTerminalEvents(res.Events, termCfg.Charset)
+		}
+		if res.Stale {
+			page.CachedAt = res.FetchedAt
+		}
+		pages = append(pages, page)
+	}
+
+	if opts.Anniversaries {
+		for _, page := range pages {
+			markAnniversaries(page.Events, calendarYear(date))
+			markAnniversaries(page.Pool, calendarYear(date))
+		}
+	}
+
+	// An old copy served because the fetch failed is offered rather than
+	// forced on the caller, who may rather try again. One they asked for by
+	// stopping the loading is shown straight away, as is any copy offline.
+	if staleErr != nil && !stoppedByKey && !errors.Is(staleErr, wikimedia.ErrOffline) {
+		d.Err, d.CachedAt = staleErr.Error(), cachedAt
+		d.Cause, d.Status = failureCause(staleErr)
+		d.RetryAt, d.LastFetched = wikiClient.RetryAt(), wikiClient.LastFetched()
+		return append([]terminal.Page{noticePage(termCfg, opts.Notices, notice.Error, d)}, pages...)
+	}
+	return pages
+}
+
+// backgroundGrace is how long the door lingers on exit for a background cache refresh.
+const backgroundGrace = 10 * time.Second
+
+// exitDrain is how long the door takes in keys on its way out, to keep
+// those typed ahead from the BBS.
+const exitDrain = 250 * time.Millisecond
+
+// knownStrategies lists the values accepted by -strategy.
+var knownStrategies = []string{"era-based", "daily", "random", "oldest-first", "anniversary", "science"}
+
+// configPathFromArgs finds -config in args ahead of the full flag parse, so the
+// file's values can become the defaults that the remaining flags override.
+func configPathFromArgs(args []string) (path string, explicit bool) {
+	for i, a := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value, true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return config.DefaultPath, false
+}
+
+// loadConfig loads the config file named in args (or the default). A missing
+// default file is fine; a missing file the sysop asked for is not.
+func loadConfig(args []string) (config.Config, string, error) {
+	path, explicit := configPathFromArgs(args)
+	cfg, err := config.Load(path)
+	if err != nil && (explicit || !config.IsNotExist(err)) {
+		return cfg, path, err
+	}
+	return cfg, path, nil
+}
+
+// registerConfigFlags binds the flags that mirror config file settings to cfg,
+// using the loaded values as defaults.
+func registerConfigFlags(fs *flag.FlagSet, cfg *config.Config, configPath string) {
+	fs.String("config", configPath, "path to the JSON config file")
+	// Enable shuffle by default
+	fs.BoolVar(&cfg.Shuffle, "shuffle", cfg.Shuffle, "shuffle events every run")
+	fs.StringVar(&cfg.Strategy, "strategy", cfg.Strategy, "selection strategy: "+strings.Join(knownStrategies, "|"))
+	fs.BoolVar(&cfg.SceneEvents, "scene-events", cfg.SceneEvents, "blend milestones of the BBS, demo, art and warez scenes into the day's events")
+	fs.BoolVar(&cfg.Anniversaries, "anniversaries", cfg.Anniversaries, "call out events that are a round 25, 50 or 100 years old today, whatever the strategy")
+	fs.BoolVar(&cfg.DailyByBBS, "daily-by-bbs", cfg.DailyByBBS, "with -strategy daily, give each BBS its own picks for the day")
+	fs.StringVar(&cfg.SeenDir, "seen-dir", cfg.SeenDir, "directory remembering the events each caller has seen today, so a revisit favours new ones")
+	fs.StringVar(&cfg.RatingsFile, "ratings-file", cfg.RatingsFile, "file keeping callers' likes and dislikes of events, shared by all nodes; empty turns rating off")
+	fs.StringVar(&cfg.BirthYearsFile, "birth-years-file", cfg.BirthYearsFile, "file keeping the year each caller was born, shared by all nodes; empty turns the birth year screen off")
+	fs.IntVar(&cfg.Favorites, "favorites", cfg.Favorites, "how many of the best rated events to put first on each screen (needs -ratings-file)")
+	fs.BoolVar(&cfg.SharedSeen, "shared-seen", cfg.SharedSeen, "keep the events shown today in the shared cache, so callers on different nodes don't get the same ones back to back")
+	fs.StringVar(&cfg.CacheDir, "cache-dir", cfg.CacheDir, "directory for cached API responses, shared by all nodes (default $HISTORY_CACHE_DIR, then the user cache directory)")
+	fs.TextVar(&cfg.CacheMaxSize, "cache-max-size", cfg.CacheMaxSize, "largest the cache may grow, e.g. 50MB; least recently used days are deleted (0 for no cap)")
+	fs.TextVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "cache TTL (e.g., 1h, 30m)")
+	fs.Func("cache-ttl-for", "cache TTL for one feed, as feed=duration (repeatable): events,"+strings.Join(config.KnownSections, ",")+",summary", func(v string) error {
+		name, value, ok := strings.Cut(v, "=")
+		var ttl config.Duration
+		if !ok || ttl.UnmarshalText([]byte(strings.TrimSpace(value))) != nil {
+			return fmt.Errorf("expected feed=duration, got %q", v)
+		}
+		if cfg.CacheTTLs == nil {
+			cfg.CacheTTLs = make(map[string]config.Duration)
+		}
+		cfg.CacheTTLs[strings.TrimSpace(name)] = ttl
+		return nil
+	})
+	fs.BoolVar(&cfg.LeapBlend, "leap-blend", cfg.LeapBlend, "on Feb 29, blend in Feb 28/Mar 1 events when few are available")
+	fs.BoolVar(&cfg.Links, "links", cfg.Links, "number events and list their Wikipedia articles")
+	fs.BoolVar(&cfg.Attribution, "attribution", cfg.Attribution, "with -links off, still name the Wikipedia articles the events come from")
+	fs.Func("sections", "extra screens after the events, comma separated: "+strings.Join(config.KnownSections, ","), func(v string) error {
+		cfg.Sections = nil
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Sections = append(cfg.Sections, name)
+			}
+		}
+		return nil
+	})
+	fs.StringVar(&cfg.Language, "language", cfg.Language, "Wikipedia language edition to fetch, by its code, such as en, de or ru")
+	fs.Func("categories", "show only events in these categories, comma separated: "+strings.Join(category.Names(), ","), func(v string) error {
+		cfg.Categories = nil
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Categories = append(cfg.Categories, name)
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&cfg.CategoryTags, "category-tags", cfg.CategoryTags, "show each event's category beside it")
+	fs.Func("min-level", "minimum security level for a feature, as feature=level (repeatable): "+strings.Join(config.KnownFeatures, ","), func(v string) error {
+		name, level, ok := strings.Cut(v, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(level))
+		if !ok || err != nil {
+			return fmt.Errorf("expected feature=level, got %q", v)
+		}
+		if cfg.MinLevels == nil {
+			cfg.MinLevels = make(map[string]int)
+		}
+		cfg.MinLevels[strings.TrimSpace(name)] = n
+		return nil
+	})
+	fs.StringVar(&cfg.TaglinesFile, "taglines", cfg.TaglinesFile, "file of footer taglines, one per line")
+	fs.StringVar(&cfg.PinnedFile, "pinned", cfg.PinnedFile, "file of events to pin first on the events screen, one per line as MM-DD YEAR text")
+	fs.StringVar(&cfg.SaveDir, "save-dir", cfg.SaveDir, "where the save key puts a copy of the screen; {user} and {node} are filled in")
+	fs.StringVar(&cfg.SaveFormat, "save-format", cfg.SaveFormat, "format for saved screens: "+strings.Join(config.SaveFormats, "|"))
+	fs.StringVar(&cfg.Clock, "clock", cfg.Clock, "clock format for times on screen: 12h|24h")
+	fs.StringVar(&cfg.Timezone, "timezone", cfg.Timezone, "the door's time zone, such as America/New_York (default the system's)")
+	fs.StringVar(&cfg.Locale, "locale", cfg.Locale, "language of the month and day names in dates: "+strings.Join(locale.Names(), "|")+", or a locale file")
+	fs.StringVar(&cfg.NoticesDir, "notices", cfg.NoticesDir, "directory of templates for the error, empty and stopped screens")
+	fs.BoolVar(&cfg.ScreenDiff, "screen-diff", cfg.ScreenDiff, "redraw only the screen cells that change when paging")
+	fs.StringVar(&cfg.YearColors, "year-colors", cfg.YearColors, "year column colors: era|gradient (by century, two-tone without a 256-color palette)")
+	fs.StringVar(&cfg.Layout, "layout", cfg.Layout, "where the 80-column screens sit in a wider window: left or center")
+	fs.StringVar(&cfg.Theme.Prefix.Format, "prefix", cfg.Theme.Prefix.Format, "each entry's year column, with {year} and {era} for its year and era badge, such as \"[{year}]\" or \"{year} |\"")
+	fs.IntVar(&cfg.Theme.Prefix.YearWidth, "prefix-year-width", cfg.Theme.Prefix.YearWidth, "right-align years in at least this many columns")
+	fs.StringVar(&cfg.Theme.Prefix.Color, "prefix-color", cfg.Theme.Prefix.Color, "color of the year column's glyphs: "+strings.Join(terminal.ColorNames(), "|"))
+	fs.Func("era-color", "color an era, as BADGE=color, such as MOD=bright-blue (repeatable): "+strings.Join(terminal.ColorNames(), "|"), func(v string) error {
+		badge, color, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("expected BADGE=color, got %q", v)
+		}
+		if cfg.Theme.Eras == nil {
+			cfg.Theme.Eras = make(map[string]string)
+		}
+		cfg.Theme.Eras[strings.ToUpper(strings.TrimSpace(badge))] = strings.TrimSpace(color)
+		return nil
+	})
+	fs.BoolVar(&cfg.Theme.Legend, "legend", cfg.Theme.Legend, "show the legend of era badges under the footer")
+	fs.BoolVar(&cfg.Theme.Night.Enabled, "night", cfg.Theme.Night.Enabled, "draw in the theme's dimmer night palette during its night hours")
+	fs.IntVar(&cfg.Theme.Night.From, "night-from", cfg.Theme.Night.From, "hour the night palette starts, 0-23")
+	fs.IntVar(&cfg.Theme.Night.To, "night-to", cfg.Theme.Night.To, "hour the night palette ends, 0-23")
+	fs.StringVar(&cfg.Theme.Seasons, "seasons", cfg.Theme.Seasons, "shift the header and footer accents with the season, by its hemisphere: "+strings.Join(config.ThemeSeasons, "|"))
+	fs.StringVar(&cfg.Theme.Wrap.Hang, "wrap-hang", cfg.Theme.Wrap.Hang, "where an entry's wrapped lines start: text (under its first line) or year (under the year column)")
+	fs.IntVar(&cfg.Theme.Wrap.Indent, "wrap-indent", cfg.Theme.Wrap.Indent, "indent an entry's wrapped lines this many more columns")
+	fs.StringVar(&cfg.Theme.Wrap.Justify, "wrap-justify", cfg.Theme.Wrap.Justify, "entry text margins: ragged or filled (spaced out to the right margin)")
+	fs.StringVar(&cfg.Splash.Art, "splash", cfg.Splash.Art, "ANSI art file to show as an intro splash screen before the day's screens (empty for none)")
+	fs.TextVar(&cfg.Splash.MinTime, "splash-min-time", cfg.Splash.MinTime, "shortest the splash screen stays up, unless a key skips it, e.g. 3s")
+	fs.StringVar(&cfg.LoadingStyle, "loading-style", cfg.LoadingStyle, "how the loading screen shows a fetch: "+strings.Join(config.LoadingStyles, "|"))
+	fs.StringVar(&cfg.Newline, "newline", cfg.Newline, "line ending sent to the caller: "+strings.Join(config.Newlines, "|")+"; lf is for local testing")
+	fs.StringVar(&cfg.Charset, "charset", cfg.Charset, "character set for the door's art: "+strings.Join(config.Charsets, "|")+"; auto uses ascii when the dropfile says the terminal is ASCII, and utf8 for -local in a UTF-8 locale")
+	fs.TextVar(&cfg.SessionLimit, "session-limit", cfg.SessionLimit, "longest a caller may stay in the door, e.g. 10m (0 for no limit)")
+	fs.BoolVar(&cfg.Offline, "offline", cfg.Offline, "never use the network; serve every day from the cache, or the built-in almanac")
+	fs.BoolVar(&cfg.StaleWhileRevalidate, "stale-while-revalidate", cfg.StaleWhileRevalidate, "show an expired cached day at once and refresh it in the background")
+	fs.IntVar(&cfg.FetchAttempts, "fetch-attempts", cfg.FetchAttempts, "how many times to try the API per fetch")
+	fs.TextVar(&cfg.FetchAttemptTimeout, "fetch-attempt-timeout", cfg.FetchAttemptTimeout, "time limit for a single API request")
+	fs.TextVar(&cfg.FetchDeadline, "fetch-deadline", cfg.FetchDeadline, "time limit for a whole fetch, including retries")
+	fs.TextVar(&cfg.BackoffBase, "backoff-base", cfg.BackoffBase, "wait before the first retry; doubled for each further retry")
+	fs.TextVar(&cfg.BackoffJitter, "backoff-jitter", cfg.BackoffJitter, "random shift applied to each retry wait, either way")
+	fs.IntVar(&cfg.CircuitThreshold, "circuit-threshold", cfg.CircuitThreshold, "consecutive fetch failures before the API is skipped (0 disables)")
+	fs.TextVar(&cfg.CircuitCooldown, "circuit-cooldown", cfg.CircuitCooldown, "how long the API is skipped once the circuit opens")
+	fs.StringVar(&cfg.LogOutput, "log-output", cfg.LogOutput, "where logs go: file (-log-file, or stderr), syslog or journald")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "write structured JSON logs to this file instead of stderr")
+	fs.StringVar(&cfg.StatsFile, "stats-file", cfg.StatsFile, "append API request stats (JSON lines) to this file")
+	fs.StringVar(&cfg.WebhookURL, "webhook", cfg.WebhookURL, "POST to this URL when the API keeps failing, the cache is damaged or the door crashes")
+	fs.StringVar(&cfg.WebhookFormat, "webhook-format", cfg.WebhookFormat, "webhook body: json or discord")
+	fs.StringVar(&cfg.MetricsDir, "metrics-dir", cfg.MetricsDir, "write a daily summary of sessions, cache use and API errors to this directory")
+	fs.StringVar(&cfg.RecordDir, "record", cfg.RecordDir, "save a capture of each session in this directory")
+	fs.StringVar(&cfg.RecordFormat, "record-format", cfg.RecordFormat, "capture format for -record: "+strings.Join(config.RecordFormats, "|"))
+	fs.StringVar(&cfg.Serial, "serial", cfg.Serial, "reach the caller through this serial device, e.g. /dev/ttyS0, instead of stdin and stdout")
+	fs.IntVar(&cfg.Baud, "baud", cfg.Baud, "speed of the -serial line")
+	fs.StringVar(&cfg.Parity, "parity", cfg.Parity, "parity of the -serial line: "+strings.Join(config.Parities, "|"))
+	fs.StringVar(&cfg.SerialFlow, "serial-flow", cfg.SerialFlow, "flow control on the -serial line: "+strings.Join(config.SerialFlows, "|")+"; hardware also ends the session when the carrier drops")
+}
+
+// cacheDir picks the cache directory: the setting, then $HISTORY_CACHE_DIR,
+// then the per-user cache directory ($XDG_CACHE_HOME or ~/.cache on Unix), so
+// every node shares one cache whatever directory the BBS starts it in.
+func cacheDir(cfg config.Config) string {
+	if dir := cmp.Or(cfg.CacheDir, os.Getenv("HISTORY_CACHE_DIR")); dir != "" {
+		return dir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(".", ".cache", "wikimedia")
+	}
+	return filepath.Join(base, "history", "wikimedia")
+}
+
+// clientOptions configures the Wikimedia client from the settings.
+func clientOptions(cfg config.Config) wikimedia.Options {
+	ttls := make(map[string]time.Duration, len(cfg.CacheTTLs))
+	for feed, ttl := range cfg.CacheTTLs {
+		ttls[feed] = time.Duration(ttl)
+	}
+	return wikimedia.Options{
+		CacheDir:     cacheDir(cfg),
+		Language:     cfg.Language,
+		TTL:          time.Duration(cfg.CacheTTL),
+		SectionTTLs:  ttls,
+		MaxCacheSize: int64(cfg.CacheMaxSize),
+		Retry: wikimedia.RetryPolicy{
+			Attempts:       cfg.FetchAttempts,
+			AttemptTimeout: time.Duration(cfg.FetchAttemptTimeout),
+			Backoff:        time.Duration(cfg.BackoffBase),
+			Jitter:         time.Duration(cfg.BackoffJitter),
+		},
+		StaleWhileRevalidate: cfg.StaleWhileRevalidate,
+		CircuitThreshold:     cfg.CircuitThreshold,
+		CircuitCooldown:      time.Duration(cfg.CircuitCooldown),
+		Offline:              cfg.Offline,
+		Fallback:             almanac.Fallback,
+	}
+}
+
+// charsetFor picks the character set for session's art: the setting, or
+// for "auto", ASCII when the dropfile marks the caller's terminal as ASCII,
+// UTF-8 for a -local session in a UTF-8 locale, and CP437 otherwise. A BBS
+// often runs under a UTF-8 locale whatever its callers use, so the locale
+// only counts for -local.
+func charsetFor(setting string, session *dropfile.DoorSession) terminal.Charset {
+	switch {
+	case setting != "auto":
+		return terminal.Charset(setting)
+	case session.Emulation == dropfile.EmulationASCII:
+		return terminal.ASCII
+	case session.Format == "local" && utf8Locale():
+		return terminal.UTF8
 	}
+	return terminal.CP437
+}
 
-	terminal.RenderEvents(termCfg, tevents)
+// utf8Locale reports whether the environment's locale, from $LC_ALL,
+// $LC_CTYPE or $LANG, uses UTF-8.
+func utf8Locale() bool {
+	locale := strings.ToUpper(cmp.Or(os.Getenv("LC_ALL"), os.Getenv("LC_CTYPE"), os.Getenv("LANG")))
+	return strings.Contains(locale, "UTF-8") || strings.Contains(locale, "UTF8")
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "update-check":
+			os.Exit(runUpdateCheck(os.Args[2:]))
+		case "cache":
+			os.Exit(runCache(os.Args[2:]))
+		case "setup":
+			os.Exit(runSetup(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		}
+	}
+
+	cfg, configPath, err := loadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Parse flags (moved from init)
 	pathPtr := flag.String("path", "", "path to node directory")
 	bypassCachePtr := flag.Bool("bypass-cache", false, "bypass cache and fetch fresh data")
-	// Enable shuffle by default
-	shufflePtr := flag.Bool("shuffle", true, "shuffle events every run (default: true)")
-	strategyPtr := flag.String("strategy", "era-based", "selection strategy: era-based|random|oldest-first")
-	cacheTTLS := flag.String("cache-ttl", "24h", "cache TTL (e.g., 1h, 30m)")
+	datePtr := flag.String("date", "", "show events for this day instead of today (MM-DD)")
+	versionPtr := flag.Bool("version", false, "print version information and exit")
+	formatPtr := flag.String("format", "door", "output: "+strings.Join(outputFormats, ", ")+"; the others print the day to stdout and exit, with no dropfile needed")
+	bannerRowsPtr := flag.Int("banner-rows", 24, "height of -format banner: 24 or 50")
+	registerConfigFlags(flag.CommandLine, &cfg, configPath)
+	preview := registerPreviewFlags(flag.CommandLine)
+	local := registerLocalFlags(flag.CommandLine)
 	flag.Parse()
-	if *pathPtr == "" {
-		fmt.Fprintf(os.Stderr, "missing path to node directory, e.g.: ./history -path /bbs/temp/1\n")
+	if *versionPtr {
+		fmt.Println(versionString())
+		return
+	}
+	if preview.Enabled {
+		dir, err := preview.writeDropFile()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set up preview: %v\n", err)
+			os.Exit(2)
+		}
+		*pathPtr = dir
+	}
+	if !slices.Contains(outputFormats, *formatPtr) {
+		fmt.Fprintf(os.Stderr, "unknown -format %q, expected one of %v\n", *formatPtr, outputFormats)
+		os.Exit(2)
+	}
+	if !slices.Contains(terminal.BannerRows, *bannerRowsPtr) {
+		fmt.Fprintf(os.Stderr, "unsupported -banner-rows %d, expected one of %v\n", *bannerRowsPtr, terminal.BannerRows)
+		os.Exit(2)
+	}
+	if *pathPtr == "" && !local.Enabled && *formatPtr == "door" {
+		if !fileExists(configPath) && stdinIsTerminal() {
+			// A first run from the sysop's shell: set up rather than fail
+			os.Exit(setup(configPath, os.Stdin, os.Stdout))
+		}
+		fmt.Fprintf(os.Stderr, "missing path to node directory, e.g.: ./history -path /bbs/temp/1 (or -local to run without one)\n")
+		os.Exit(2)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %v\n", err)
 		os.Exit(2)
 	}
-	// Parse cache TTL
-	cacheTTLDur, err := time.ParseDuration(*cacheTTLS)
+	if cfg.Timezone != "" {
+		// Already checked by cfg.Validate
+		time.Local, _ = time.LoadLocation(cfg.Timezone)
+	}
+	closeLog, err := setupLogging(cfg.LogOutput, cfg.LogFile)
 	if err != nil {
-		log.Printf("invalid cache-ttl '%s', defaulting to 24h: %v", *cacheTTLS, err)
-		cacheTTLDur = 24 * time.Hour
+		fmt.Fprintf(os.Stderr, "failed to open log: %v\n", err)
+		os.Exit(2)
+	}
+	atExit(closeLog)
+	// A crash still puts the tty back and frees the node
+	defer undoOnPanic()
+	// Parse the optional date override; year 0 is a leap year so 02-29 is always accepted
+	displayDate := time.Now()
+	if *datePtr != "" {
+		d, err := time.Parse("01-02", *datePtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -date '%s', expected MM-DD: %v\n", *datePtr, err)
+			exit(2)
+		}
+		displayDate = d
+	}
+	if *formatPtr != "door" {
+		exit(runDigest(cfg, displayDate, digestOptions{Format: *formatPtr, BypassCache: *bypassCachePtr, BannerRows: *bannerRowsPtr}))
 	}
 
-	// read the drop file and save to local struct
-	commport, _, baudrate, bbsname, usernum, realname, username, seclevel, timeleft, emulation, node, err := DropFileData(*pathPtr)
-	if err != nil {
+	// read the drop file, whichever format the BBS wrote
+	var session *dropfile.DoorSession
+	if local.Enabled {
+		session = local.session()
+	} else if session, err = dropfile.Load(*pathPtr); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read dropfile: %v\n", err)
-		os.Exit(1)
+		exit(1)
+	}
+	if preview.Enabled {
+		// The synthetic dropfile has been read; nothing else needs the directory
+		os.RemoveAll(*pathPtr)
 	}
 
-	// convert some values to int (ignore conversion errors as before)
-	intnode, _ := strconv.Atoi(node)
-	intcommport, _ := strconv.Atoi(commport)
-	intbaudrate, _ := strconv.Atoi(baudrate)
-	intusernum, _ := strconv.Atoi(usernum)
-	intseclevel, _ := strconv.Atoi(seclevel)
-	inttimeleft, _ := strconv.Atoi(timeleft)
-	intemulation, _ := strconv.Atoi(emulation)
+	// Lock the node so a double launch can't garble the caller's screen
+	if !local.Enabled && !preview.Enabled {
+		release, err := nodelock.Acquire(filepath.Dir(session.Path))
+		var locked *nodelock.LockedError
+		switch {
+		case errors.As(err, &locked):
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			exit(1)
+		case err != nil:
+			slog.Warn("could not lock node directory", "error", err)
+		default:
+			atExit(release)
+		}
+	}
+
+	// A caller on dial-up hardware is reached through the serial port
+	var port *os.File
+	if cfg.Serial != "" {
+		port, err = serial.Open(cfg.Serial, serial.Config{Baud: cfg.Baud, Parity: cfg.Parity, Flow: cfg.SerialFlow})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open serial port: %v\n", err)
+			exit(1)
+		}
+		atExit(func() { port.Close() })
+		terminal.SetOutput(port)
+	}
+	terminal.SetLineEnding(cfg.Newline)
 
 	// detect terminal capabilities
-	terminalName, loadableFonts, xtendPalette, cols, rows := DetectTerminalCapabilities()
-
-	// local program state (no globals)
-	localPd := Door32Drop{
-		Node:          intnode,
-		BbsName:       bbsname,
-		UserName:      username,
-		RealName:      realname,
-		SecLevel:      intseclevel,
-		TimeLeft:      inttimeleft,
-		Emulation:     intemulation,
-		CommPort:      intcommport,
-		BaudRate:      intbaudrate,
-		UserNumber:    intusernum,
-		Terminal:      terminalName,
-		LoadableFonts: loadableFonts,
-		XtendPalette:  xtendPalette,
-		Cols:          cols,
-		Rows:          rows,
-	}
-	// Seed global PRNG for non-deterministic shuffling
-	rand.Seed(time.Now().UnixNano())
+	terminalName, _, extendedPalette, cols, rows := DetectTerminalCapabilities()
+	if preview.Enabled {
+		cols, rows = preview.Cols, preview.Rows
+	}
+	if local.Enabled {
+		if local.Cols > 0 {
+			cols = local.Cols
+		}
+		if local.Rows > 0 {
+			rows = local.Rows
+		}
+	}
+
+	// The session's own randomness, for shuffles and the tagline
+	rng := sessionRand(session.Node)
+
+	// The day shown may be one the door dresses up for
+	special := cfg.SpecialDays[displayDate.Format("01-02")]
 
 	// Build terminal config
 	termCfg := terminal.TerminalConfig{
-		BbsName:  localPd.BbsName,
-		UserName: localPd.UserName,
-		RealName: localPd.RealName,
-		Terminal: localPd.Terminal,
-		Cols:     localPd.Cols,
-		Rows:     localPd.Rows,
+		BbsName:  session.BbsName,
+		UserName: session.UserName,
+		RealName: session.RealName,
+		Terminal: terminalName,
+		Cols:     cols,
+		Rows:     rows,
+
+		ShowLinks:    cfg.Links && cfg.Allows("links", session.SecLevel),
+		Attribution:  cfg.Attribution,
+		CategoryTags: cfg.CategoryTags,
+		Clock24:      cfg.Clock == "24h",
+		ScreenDiff:   cfg.ScreenDiff,
+		Charset:      charsetFor(cfg.Charset, session),
+
+		ExtendedPalette: extendedPalette,
+		GradientYears:   cfg.YearColors == "gradient",
+		Theme:           themeFor(cfg.Theme),
+		Header:          headerFor(special),
+	}
+	// A CJK edition's readers likely have terminals that show ambiguous
+	// characters wide, and the event text has plenty
+	textwrap.AmbiguousWide = termCfg.Charset == terminal.UTF8 && eastAsian(cfg.Language)
+	if cfg.Theme.Seasons != "off" {
+		termCfg.Theme.Season = terminal.SeasonOf(displayDate, cfg.Theme.Seasons == "south")
+	}
+	termCfg.Locale = localeFor(cfg.Locale, termCfg.Charset)
+	notices, err := notice.Load(cfg.NoticesDir, locale.Code(cfg.Locale), termCfg.Locale)
+	if err != nil {
+		slog.Warn("could not read notice screens, using the built-in ones", "dir", cfg.NoticesDir, "error", err)
+		notices = notice.Default(termCfg.Locale)
+	}
+	terminal.SetCharset(termCfg.Charset)
+	if cfg.Layout == "center" {
+		terminal.SetCentered(cols)
+	}
+	if p := terminal.Palette(special.Colors).Then(paletteFor(cfg.Theme, time.Now())); len(p) > 0 {
+		terminal.SetPalette(p)
+	}
+	if cfg.TaglinesFile != "" {
+		if lines, err := taglines.Load(cfg.TaglinesFile); err != nil {
+			slog.Warn("could not read taglines", "file", cfg.TaglinesFile, "error", err)
+		} else {
+			termCfg.Tagline, termCfg.TaglineBy = taglines.Split(taglines.Pick(rng, lines))
+		}
+	}
+	bindings, err := cfg.Bindings()
+	if err != nil {
+		// Already checked by cfg.Validate
+		fail(err)
 	}
 
+	// The sysop hears about trouble from the webhook, a crash included
+	alerts := newAlerts(cfg.WebhookURL, cfg.WebhookFormat, session)
+	defer alertPanic(alerts)
+
 	// Create wikimedia client (shared)
-	wikiClient := wikimedia.NewClient("", cacheTTLDur)
+	wikiClient := wikimedia.New(clientOptions(cfg))
+	watchClient(wikiClient, alerts)
+	sessionMetrics := metrics.Start(cfg.MetricsDir, session.UserName)
+	observeRequests(wikiClient, stats.Open(cfg.StatsFile), sessionMetrics, session.Node)
+
+	// Sections the caller's security level doesn't reach are left out
+	sections := slices.DeleteFunc(slices.Clone(cfg.Sections), func(name string) bool {
+		return !cfg.Allows(name, session.SecLevel)
+	})
+
+	// Keys come from the serial port when there is one, then the controlling
+	// tty; a door run with the caller's socket on stdin and no tty reads stdin
+	// directly, as telnet
+	var keys *input.Decoder
+	if port != nil {
+		keys = input.NewDecoder(input.RuneFunc(bufio.NewReader(port)), input.DefaultEscTimeout)
+	} else if t, err := tty.Open(); err == nil {
+		atExit(func() { t.Close() })
+		keys = input.NewDecoder(input.RuneFunc(t), input.DefaultEscTimeout)
+	} else {
+		slog.Debug("no controlling tty, reading keys from stdin", "error", err)
+		keys = input.NewTelnetDecoder(os.Stdin, input.DefaultEscTimeout)
+		if session.CommType == 2 { // telnet
+			// So a resize mid-session redraws the screen to fit
+			terminal.Stdout.Write(input.AskWindowSize)
+			terminal.Flush()
+		}
+	}
+
+	stopRecording := startRecording(cfg.RecordDir, cfg.RecordFormat, termCfg.Charset, session)
+	hooks := newHooks(cfg.Hooks, session)
+	hooks.started()
 
-	// Start the idle timer
-	shortTimer := NewTimer(Idle, func() {
-		fmt.Println("\r\nYou've been idle for too long... exiting!")
+	// The session's work stops as soon as it is over, whatever ends it
+	ctx, end := sessionContext(session.TimeLeft, keys.Done())
+	defer end(nil)
+
+	// leave says goodbye and exits from a timer goroutine; reason is passed
+	// to the on_exit hook
+	leave := func(reason, msg string) {
+		end(fmt.Errorf("%w: %s", errSessionOver, reason))
+		fmt.Fprintln(terminal.Stdout, "\r\n"+msg)
+		terminal.Flush()
 		time.Sleep(1 * time.Second)
-		os.Exit(0)
+		wikiClient.Wait(backgroundGrace)
+		alerts.Wait(backgroundGrace)
+		hooks.exited(reason)
+		endMetrics(sessionMetrics)
+		stopRecording()
+		exit(0)
+	}
+
+	// Being told to stop, as when the BBS shuts down, takes the same way out
+	onStopSignal(func(sig os.Signal) {
+		leave("signal", "The door has been asked to close ("+sig.String()+")... goodbye!")
+	})
+
+	// Start the idle timer; keys on the day's screens wind it back
+	idle := startIdleClock(Idle*time.Second, func() {
+		leave("idle", "You've been idle for too long... exiting!")
+	})
+	defer idle.pause()
+
+	// The door's own session cap, separate from BBS time left
+	startSessionClock(termCfg, time.Duration(cfg.SessionLimit), func() {
+		leave("session_limit", "Your time in the door is up... thanks for visiting!")
+	})
+	startTimeLeftClock(termCfg, session.TimeLeft, func() {
+		leave("time_left", "Your time on the BBS is up... goodbye!")
 	})
-	defer shortTimer.Stop()
 
 	ClearScreen()
 	MoveCursor(0, 0)
-
-	tty, err := tty.Open()
-	if err != nil {
-		log.Fatal(err)
+	if cfg.Splash.Art != "" {
+		ready := warmDay(ctx, wikiClient, displayDate, sections, time.Duration(cfg.FetchDeadline), *bypassCachePtr || preview.Screen != "")
+		showSplash(ctx, termCfg, keys, cfg.Splash, ready)
 	}
-	defer tty.Close()
 
-	for {
-		generateEventList(termCfg, wikiClient, *bypassCachePtr, *shufflePtr, *strategyPtr)
-		_, err := tty.ReadRune()
-		if err != nil {
-			log.Fatal(err)
+	seenLog := openSeen(cfg.SeenDir, session)
+	var boardLog *seen.Log
+	if cfg.SharedSeen {
+		boardLog = openShown(cacheDir(cfg))
+	}
+	book := openRatings(cfg.RatingsFile)
+	record := func(kind string, events []terminal.Event) {
+		markSeen(seenLog, kind, events)
+		markSeen(boardLog, kind, events)
+	}
+	load := func() []terminal.Page {
+		opts := eventListOptions{
+			BypassCache:  *bypassCachePtr,
+			Shuffle:      cfg.Shuffle,
+			Strategy:     cfg.Strategy,
+			Date:         displayDate,
+			LeapBlend:    cfg.LeapBlend,
+			Deadline:     time.Duration(cfg.FetchDeadline),
+			Sections:     sections,
+			Preview:      preview,
+			OnError:      hooks.failed,
+			Notices:      notices,
+			RefreshKey:   barKey(bindings.Keys(keymap.Refresh), "Retry"),
+			CachedKey:    barKey(bindings.Keys(keymap.Cached), "Cached copy"),
+			Keys:         keys,
+			LoadingStyle: cfg.LoadingStyle,
+			Metrics:      sessionMetrics,
+			// A bound key other than Quit waits for the screens without
+			// stopping the fetch, so a caller pressing Next to skip ahead
+			// gets the live day and doesn't lose the press
+			Keep: func(ev input.Event) bool {
+				return bindings.Bound(ev) && bindings.Lookup(ev) != keymap.Quit
+			},
+			Interrupted: func(ev input.Event) {
+				// Quitting while loading leaves without waiting for the rest;
+				// a kept key is acted on once the screens are up
+				switch {
+				case bindings.Lookup(ev) == keymap.Quit:
+					end(errSessionOver)
+				case bindings.Bound(ev):
+					keys.Unread(ev)
+				}
+			},
 		}
-		os.Exit(0)
+		if cfg.DailyByBBS {
+			opts.DailySalt = session.BbsName
+		}
+		opts.Anniversaries = marksAnniversaries(cfg.Strategy, cfg.Anniversaries)
+		opts.Categories = cfg.Categories
+		opts.Scene = cfg.SceneEvents
+		opts.Rand = rng
+		opts.Seen, opts.Board = seenLog, boardLog
+		if err := boardLog.Reload(); err != nil {
+			slog.Warn("could not read the events shown today", "error", err)
+		}
+		opts.Ratings, opts.Favorites = book, cfg.Favorites
+		if cfg.PinnedFile != "" {
+			// Read on every load, so a pin edited mid-session shows on refresh
+			pin, err := pinned.Load(cfg.PinnedFile, displayDate.Month(), displayDate.Day())
+			if err != nil {
+				slog.Warn("could not read pinned events", "file", cfg.PinnedFile, "error", err)
+			}
+			opts.Pinned = pin
+		}
+		if err := book.Reload(); err != nil {
+			slog.Warn("could not read ratings", "error", err)
+		}
+		// The wait for Wikipedia isn't the caller's idling
+		idle.pause()
+		defer idle.reset()
+		pages := generateEventList(ctx, termCfg, wikiClient, opts)
+		if len(pages) > 0 && pages[0].Kind == terminal.KindNotice {
+			// Keys typed ahead were meant for the day's screens, and any key
+			// on a notice leaves; the caller reads it first
+			keys.Discard(0)
+		}
+		return pages
+	}
+	var save func(terminal.Page)
+	if cfg.Allows("save", session.SecLevel) {
+		save = screenSaver(termCfg, saveDir(cfg.SaveDir, session), cfg.SaveFormat)
+	}
+	summarize := summarizer(ctx, termCfg, wikiClient)
+	plugins := pluginScreens(ctx, cfg, termCfg, bindings, session, displayDate, save)
+	if years := openBirthYears(cfg.BirthYearsFile); years != nil && cfg.Allows("birthyear", session.SecLevel) {
+		plugins = append([]pluginScreen{birthYearScreen(termCfg, bindings, notices, years, session.UserName, displayDate, save, summarize)}, plugins...)
+	}
+	plugins = append(plugins, aboutScreen(termCfg, bindings, displayDate, save))
+	egg := newEasterEgg(cfg.EasterEgg, termCfg)
+	if err := browse(ctx, termCfg, keys, bindings, idle, load, save, rater(termCfg, book, session.UserName), summarize, record, plugins, egg); err != nil {
+		hooks.failed(err)
+		hooks.exited("error")
+		endMetrics(sessionMetrics)
+		stopRecording()
+		fail(err)
+	}
+	reason := "quit"
+	select {
+	case <-keys.Done():
+		reason = "hangup"
+	default:
 	}
+	// Let a background cache refresh finish so the next caller gets fresh data
+	terminal.Flush()
+	wikiClient.Wait(backgroundGrace)
+	alerts.Wait(backgroundGrace)
+	hooks.exited(reason)
+	endMetrics(sessionMetrics)
+	stopRecording()
+	// Keys still coming in, from a caller hammering Quit, would otherwise
+	// reach the BBS's menu
+	keys.Discard(exitDrain)
+	exit(0)
 }
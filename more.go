@@ -0,0 +1,175 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// moreBatch is how many events the more key adds at a time.
+const moreBatch = 5
+
+// moreView continues a page past the events that fit on it: each press of
+// the more key adds the next few the caller hasn't seen to a list that
+// scrolls, and is passed to record as it is drawn. Paging past the end of
+// the list, or any key without a binding, goes back to the page.
+type moreView struct {
+	termCfg  terminal.TerminalConfig
+	bindings *keymap.Map
+	from     terminal.Page
+	save     func(terminal.Page)
+	record   func(string, []terminal.Event)
+
+	listed []terminal.Event // added so far
+	rest   []terminal.Event // not yet added
+	lines  []string         // listed, as drawn
+	scroll int              // first line on screen
+}
+
+// newMoreView opens the list for from with its first batch, from the events
+// in its pool that aren't among shown. Dated events come oldest first;
+// holidays and news keep the feed's order. It returns nil when there are
+// none left to show.
+func newMoreView(termCfg terminal.TerminalConfig, bindings *keymap.Map, from terminal.Page, shown []terminal.Event, save func(terminal.Page), record func(string, []terminal.Event)) *moreView {
+	rest := unseen(from.Pool, shown)
+	if len(rest) == 0 {
+		return nil
+	}
+	if from.Kind != terminal.KindHolidays && from.Kind != terminal.KindNews {
+		slices.SortStableFunc(rest, func(a, b terminal.Event) int { return cmp.Compare(a.Year, b.Year) })
+	}
+	v := &moreView{termCfg: termCfg, bindings: bindings, from: from, save: save, record: record, rest: rest}
+	v.add()
+	return v
+}
+
+// unseen returns the events of pool that aren't in shown.
+func unseen(pool, shown []terminal.Event) []terminal.Event {
+	var rest []terminal.Event
+	for _, e := range pool {
+		if !slices.ContainsFunc(shown, func(s terminal.Event) bool { return s.Year == e.Year && s.Text == e.Text }) {
+			rest = append(rest, e)
+		}
+	}
+	return rest
+}
+
+// add lists the next batch and scrolls to its start.
+func (v *moreView) add() {
+	n := min(moreBatch, len(v.rest))
+	v.listed = append(v.listed, v.rest[:n]...)
+	v.rest = v.rest[n:]
+	start := len(v.lines)
+	v.lines = terminal.ListLines(v.termCfg, v.from.Kind, v.listed)
+	v.scroll = min(start, v.maxScroll())
+}
+
+// maxScroll is the furthest down the list can go.
+func (v *moreView) maxScroll() int {
+	return max(len(v.lines)-terminal.ListRows(v.termCfg), 0)
+}
+
+func (v *moreView) draw() {
+	rows := terminal.ListRows(v.termCfg)
+	page := terminal.Page{
+		Kind:     terminal.KindMore,
+		Title:    cmp.Or(v.from.Kind, terminal.KindEvents),
+		Date:     v.from.Date,
+		Events:   v.listed,
+		Lines:    v.lines[v.scroll:min(v.scroll+rows, len(v.lines))],
+		CachedAt: v.from.CachedAt,
+		Commands: v.commands(),
+	}
+	terminal.RenderEvents(v.termCfg, page)
+	v.record(page.Title, v.listed)
+}
+
+func (v *moreView) handle(_ input.Event, action keymap.Action) step {
+	// Scrolling keeps a line of the last screen for context
+	by := max(terminal.ListRows(v.termCfg)-1, 1)
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.More:
+		if len(v.rest) == 0 {
+			return stay
+		}
+		v.add()
+		return redraw
+	case keymap.Up:
+		v.scroll = max(v.scroll-by, 0)
+		return redraw
+	case keymap.Down, keymap.Next:
+		if v.scroll >= v.maxScroll() {
+			if action == keymap.Down {
+				return stay
+			}
+			return back
+		}
+		v.scroll = min(v.scroll+by, v.maxScroll())
+		return redraw
+	case keymap.Save:
+		if v.save == nil {
+			return back
+		}
+		v.save(terminal.Page{Kind: terminal.KindMore, Title: cmp.Or(v.from.Kind, terminal.KindEvents), Date: v.from.Date, Events: v.listed})
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: v.help()})
+	}
+	return back
+}
+
+// moreActions are the actions the list answers to, with their labels there;
+// any other key goes back.
+var moreActions = []struct {
+	action keymap.Action
+	label  string
+	help   string
+}{
+	{keymap.More, "More", "Add the next five to the list"},
+	{keymap.Up, "Up", "Scroll the list up"},
+	{keymap.Down, "Down", "Scroll the list down"},
+	{keymap.Next, "Next", "Scroll down, then back to the day's screens"},
+	{keymap.Save, "Save", "Save the list to your downloads"},
+	{keymap.Help, "Help", "This help"},
+	{keymap.Quit, "Quit", "Back to the BBS"},
+}
+
+// commands lists More while there are events left, one "[Up/Down] Scroll"
+// entry while the list is longer than the screen, and the rest.
+func (v *moreView) commands() []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range moreActions {
+		switch {
+		case a.action == keymap.More && len(v.rest) == 0,
+			a.action == keymap.Save && v.save == nil,
+			a.action == keymap.Down:
+			continue
+		case a.action == keymap.Up:
+			up, down := v.bindings.Keys(keymap.Up), v.bindings.Keys(keymap.Down)
+			if v.maxScroll() > 0 && len(up) > 0 && len(down) > 0 {
+				commands = append(commands, terminal.Command{Key: up[0] + "/" + down[0], Label: "Scroll"})
+			}
+			continue
+		}
+		if key := barKey(v.bindings.Keys(a.action), a.label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.label})
+		}
+	}
+	return commands
+}
+
+func (v *moreView) help() []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range moreActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: v.bindings.Keys(a.action), Text: a.help})
+	}
+	return entries
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+
+	"github.com/robbiew/history/internal/notice"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// noticePage is the notice screen called name, written from d by notices,
+// to show in place of the day's pages. A nil notices, or a sysop's template
+// that fails to run, gives the built-in English screen.
+func noticePage(termCfg terminal.TerminalConfig, notices *notice.Set, name string, d notice.Data) terminal.Page {
+	if notices == nil {
+		notices = notice.Default(termCfg.Locale)
+	}
+	screen, err := notices.Render(name, d)
+	if err != nil {
+		slog.Warn("could not write notice screen, using the built-in one", "screen", name, "error", err)
+		screen, _ = notice.Default(termCfg.Locale).Render(name, d)
+	}
+	page := terminal.Page{Kind: terminal.KindNotice, Date: d.Date, Title: displayText(screen.Title, termCfg.Charset)}
+	for _, line := range screen.Text {
+		page.Lines = append(page.Lines, displayText(line, termCfg.Charset))
+	}
+	return page
+}
+
+// failureCause sorts a failed fetch's error into one of the notice
+// package's causes, with the HTTP status for CauseStatus.
+func failureCause(err error) (cause string, status int) {
+	var se *wikimedia.StatusError
+	switch {
+	case errors.Is(err, wikimedia.ErrOffline):
+		return notice.CauseOffline, 0
+	case errors.Is(err, wikimedia.ErrCircuitOpen):
+		return notice.CauseCircuit, 0
+	case errors.As(err, &se):
+		return notice.CauseStatus, se.Code
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return notice.CauseTimeout, 0
+	}
+	return notice.CauseNetwork, 0
+}
@@ -0,0 +1,257 @@
+// Package textwrap breaks text into lines for fixed-width displays such as
+// terminals and BBS screens. It measures text in the columns a terminal
+// shows it in: wide East Asian characters take two, combining marks and
+// ANSI escape sequences none, so colored text and CJK names wrap where they
+// appear to.
+//
+// By default no text is lost: a word too long for a line is broken across
+// lines. Options.Truncate cuts it short instead.
+package textwrap
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Ellipsis marks text that was cut short.
+const Ellipsis = "..."
+
+// AmbiguousWide measures East Asian ambiguous characters, such as "°",
+// "○", box drawing and Greek and Cyrillic letters, two columns wide, as
+// terminals set up for Chinese, Japanese or Korean show them. Set it, if
+// at all, before any text is measured.
+var AmbiguousWide bool
+
+// Options control how Wrap breaks text.
+type Options struct {
+	// Width is the most columns a line may take, its indent included. Zero
+	// or less leaves the text on one line.
+	Width int
+	// Indent starts the first line and Hang every line after it, such as
+	// spaces to line continuation lines up under a year column.
+	Indent string
+	Hang   string
+	// Truncate cuts a word too long for any line short, ending it with
+	// Ellipsis, rather than breaking it across lines.
+	Truncate bool
+	// Justify widens the spaces between words so that every line but the
+	// last reaches Width, for filled rather than ragged lines.
+	Justify bool
+}
+
+// Wrap breaks text into lines no wider than width, at spaces where it can.
+// It is Options{Width: width}.Wrap.
+func Wrap(text string, width int) []string {
+	return Options{Width: width}.Wrap(text)
+}
+
+// Wrap breaks text into lines, each starting with its indent. Text that
+// fits on the first line is returned as it is; otherwise runs of white
+// space between words become single spaces. Empty text is one empty line.
+func (o Options) Wrap(text string) []string {
+	limit := o.Width - Width(o.Indent)
+	if o.Width <= 0 || Width(text) <= limit {
+		return []string{o.Indent + text}
+	}
+	rest := max(o.Width-Width(o.Hang), 1)
+	limit = max(limit, 1)
+	firstLimit := limit
+
+	words := strings.Fields(text)
+	lines := make([]string, 0, 4)
+	prefix := o.Indent
+	emit := func(line string) {
+		lines = append(lines, prefix+line)
+		prefix, limit = o.Hang, rest
+	}
+	// Each line is joined from the words in place, so wrapping allocates
+	// little more than the lines themselves
+	first, cols := 0, 0 // the current line is words[first:i], cols wide
+	for i := 0; i < len(words); i++ {
+		n := Width(words[i])
+		if cols > 0 && cols+1+n <= limit {
+			cols += 1 + n
+			continue
+		}
+		if cols > 0 {
+			emit(strings.Join(words[first:i], " "))
+		}
+		first, cols = i, n
+		if n <= limit {
+			continue
+		}
+		// A word too long for any line
+		if o.Truncate {
+			emit(Cut(words[i], limit))
+			first, cols = i+1, 0
+			continue
+		}
+		for Width(words[i]) > limit {
+			head, tail := split(words[i], limit)
+			emit(head)
+			words[i] = tail
+		}
+		cols = Width(words[i])
+	}
+	if cols > 0 {
+		emit(strings.Join(words[first:], " "))
+	}
+	if len(lines) == 0 {
+		return []string{o.Indent}
+	}
+	if o.Justify {
+		for i := range lines[:len(lines)-1] {
+			prefix, cols := o.Hang, rest
+			if i == 0 {
+				prefix, cols = o.Indent, firstLimit
+			}
+			lines[i] = prefix + justify(lines[i][len(prefix):], cols)
+		}
+	}
+	return lines
+}
+
+// justify spreads the words of line over cols columns, the leftmost gaps
+// taking a space more than the rest when they can't all be equal. A line
+// of one word is left as it is.
+func justify(line string, cols int) string {
+	words := strings.Split(line, " ")
+	gaps := len(words) - 1
+	extra := cols - Width(line)
+	if gaps == 0 || extra <= 0 {
+		return line
+	}
+	var b strings.Builder
+	b.Grow(len(line) + extra)
+	for i, w := range words {
+		if i > 0 {
+			n := 1 + extra/gaps
+			if i <= extra%gaps {
+				n++
+			}
+			b.WriteString(strings.Repeat(" ", n))
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// Width returns how many columns s takes on a terminal.
+func Width(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == 0x1b:
+			i += escapeLen(s[i:])
+		case c < utf8.RuneSelf:
+			if c >= ' ' && c != 0x7f {
+				n++
+			}
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			n += RuneWidth(r)
+			i += size
+		}
+	}
+	return n
+}
+
+// RuneWidth returns how many columns r takes on a terminal: two for wide
+// and fullwidth characters, and for ambiguous ones with AmbiguousWide,
+// none for controls, combining marks and zero-width characters, and one
+// for the rest.
+func RuneWidth(r rune) int {
+	switch {
+	case r < ' ' || r == 0x7f || (r >= 0x80 && r < 0xa0):
+		return 0
+	case r < 0xa1, r < 0x300 && !AmbiguousWide:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	case width.EastAsianAmbiguous:
+		if AmbiguousWide {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Cut shortens s to at most cols columns, ending it with Ellipsis where
+// there is room for more than the ellipsis. Escape sequences are kept.
+func Cut(s string, cols int) string {
+	if Width(s) <= cols {
+		return s
+	}
+	if cols <= len(Ellipsis) {
+		return Fit(s, cols)
+	}
+	return Fit(s, cols-len(Ellipsis)) + Ellipsis
+}
+
+// Fit returns as much of the start of s as fits in cols columns.
+func Fit(s string, cols int) string {
+	if cols <= 0 {
+		return ""
+	}
+	head, _ := split(s, cols)
+	if Width(head) > cols {
+		return "" // a wide character that doesn't fit at all
+	}
+	return head
+}
+
+// split cuts s after at most cols columns, keeping at least one character
+// in head when cols is positive so breaking a word always moves on. Escape
+// sequences stay with the text they come before.
+func split(s string, cols int) (head, tail string) {
+	n, i := 0, 0
+	escapes := -1 // where the escape sequences just passed start
+	for i < len(s) {
+		if s[i] == 0x1b {
+			if escapes < 0 {
+				escapes = i
+			}
+			i += escapeLen(s[i:])
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := RuneWidth(r)
+		if n+w > cols && (n > 0 || cols <= 0) {
+			if escapes >= 0 {
+				i = escapes
+			}
+			break
+		}
+		escapes = -1
+		n += w
+		i += size
+	}
+	return s[:i], s[i:]
+}
+
+// escapeLen returns the length of the escape sequence s starts with: a
+// control sequence through its final byte, or else the escape and the
+// byte after it.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	if s[1] != '[' {
+		return 2
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
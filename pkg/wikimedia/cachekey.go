@@ -0,0 +1,125 @@
+package wikimedia
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Identifiers that make up a cache key. English Wikipedia is fetched unless
+// Options.Language says otherwise; they are part of every key so that
+// another language or feed can never be served from an entry meant for
+// this one.
+const (
+	DefaultSource   = "wikipedia"
+	DefaultLanguage = "en"
+	feedOnThisDay   = "onthisday"
+	feedFeatured    = "featured"
+)
+
+// cacheKey identifies one cached API response by everything that shapes it.
+type cacheKey struct {
+	Source   string // wiki project, e.g. "wikipedia"
+	Lang     string // language edition, e.g. "en"
+	Endpoint string // feed and section, e.g. "onthisday/events"
+	Month    string // MM
+	Day      string // DD
+	Title    string // article, for a page summary in place of the day
+}
+
+func onThisDayKey(lang, section, month, day string) cacheKey {
+	return cacheKey{Source: DefaultSource, Lang: lang, Endpoint: feedOnThisDay + "/" + section, Month: month, Day: day}
+}
+
+// featuredKey is the featured content feed for a day. Unlike "On this day"
+// it is dated, so the year is part of the endpoint.
+func featuredKey(lang string, year int, month, day string) cacheKey {
+	return cacheKey{Source: DefaultSource, Lang: lang, Endpoint: fmt.Sprintf("%s/%04d", feedFeatured, year), Month: month, Day: day}
+}
+
+// fileName is the key's cache file name, such as
+// "wikipedia_en_onthisday-events_10_16.json", or for a page summary
+// "wikipedia_en_page-summary_Apollo_11.json".
+func (k cacheKey) fileName() string {
+	endpoint := strings.ReplaceAll(k.Endpoint, "/", "-")
+	if k.Title != "" {
+		return fmt.Sprintf("%s_%s_%s_%s.json", k.Source, k.Lang, endpoint, titleFileName(k.Title))
+	}
+	return fmt.Sprintf("%s_%s_%s_%s_%s.json", k.Source, k.Lang, endpoint, k.Month, k.Day)
+}
+
+// url is the API address the key's response comes from. Page summaries
+// come from the wiki's own REST API rather than the feed's.
+func (k cacheKey) url() string {
+	if k.Title != "" {
+		return fmt.Sprintf("https://%s.%s.org/api/rest_v1/%s/%s", k.Lang, k.Source, k.Endpoint, url.PathEscape(strings.ReplaceAll(k.Title, " ", "_")))
+	}
+	return fmt.Sprintf("https://api.wikimedia.org/feed/v1/%s/%s/%s/%s/%s", k.Source, k.Lang, k.Endpoint, k.Month, k.Day)
+}
+
+// maxTitleFileName caps the part of a summary's file name that comes from
+// its title, well inside the 255 bytes file systems allow.
+const maxTitleFileName = 160
+
+// titleFileName writes title for a file name: escaped, so it is safe on
+// any file system, and when that is too long cut and ended with a hash of
+// the whole, so different titles keep different names.
+func titleFileName(title string) string {
+	name := url.QueryEscape(strings.ReplaceAll(title, " ", "_"))
+	if len(name) <= maxTitleFileName {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", name[:maxTitleFileName-9], h.Sum32())
+}
+
+// legacyCacheName matches the cache names used before keys carried the
+// language and source: onthisday_MM_DD.json for events and
+// onthisday_<section>_MM_DD.json for the other sections.
+var legacyCacheName = regexp.MustCompile(`^onthisday_(?:([a-z]+)_)?(\d\d)_(\d\d)\.json$`)
+
+// migrateCache renames cache files from the old naming to full keys. They
+// were all English Wikipedia, so they keep serving callers instead of being
+// fetched again. Once renamed nothing matches, so this only does work the
+// first time a new version runs against an old cache.
+func migrateCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	moved := 0
+	for _, e := range entries {
+		m := legacyCacheName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		section := m[1]
+		if section == "" {
+			section = SectionEvents
+		}
+		old := filepath.Join(dir, e.Name())
+		key := onThisDayKey(DefaultLanguage, section, m[2], m[3])
+		if _, err := os.Stat(filepath.Join(dir, key.fileName())); err == nil {
+			// Another node got there first with a newer copy
+			os.Remove(old)
+			continue
+		}
+		if err := os.Rename(old, filepath.Join(dir, key.fileName())); err != nil {
+			if os.IsNotExist(err) {
+				continue // renamed by another node
+			}
+			log.Printf("migrating cache file %s: %v", old, err)
+			continue
+		}
+		moved++
+	}
+	if moved > 0 {
+		log.Printf("migrated %d cache file(s) in %s to language-aware names", moved, dir)
+	}
+}
@@ -0,0 +1,82 @@
+package wikimedia
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+)
+
+// Each cache entry has a SHA-256 of its contents in a file next to it, so a
+// file cut short or scrambled by a power loss is caught on read and fetched
+// again rather than failing every session until it expires.
+const checksumExt = ".sha256"
+
+// errCacheCorrupt is returned by Client.readCacheFile when an entry doesn't match
+// its checksum.
+var errCacheCorrupt = errors.New("cache entry does not match its checksum")
+
+func checksum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return []byte(hex.EncodeToString(sum[:]) + "\n")
+}
+
+// verifyChecksum checks data, read from path, against its checksum file.
+// Entries written before checksums were kept have none and pass.
+func verifyChecksum(path string, data []byte) error {
+	want, err := os.ReadFile(path + checksumExt)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(checksum(data))) {
+		return errCacheCorrupt
+	}
+	return nil
+}
+
+// A reader can land between the two renames of another process's
+// writeCacheEntry and see a new checksum beside the old entry, so a mismatch
+// is read again a few times, checksumSettle apart, before it counts as
+// damage.
+const (
+	checksumRereads = 3
+	checksumSettle  = 100 * time.Millisecond
+)
+
+// readVerified reads the entry at path and checks it against its checksum,
+// rereading both while they disagree in case a write is still landing.
+func readVerified(path string) ([]byte, error) {
+	for try := 0; ; try++ {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		err = verifyChecksum(path, data)
+		if !errors.Is(err, errCacheCorrupt) || try == checksumRereads {
+			return data, err
+		}
+		time.Sleep(checksumSettle)
+	}
+}
+
+// writeCacheEntry writes a response and its checksum, each atomically. The
+// checksum goes first: if the entry's write is cut short, the two disagree
+// and the entry is refetched. Readers that catch the moment between the two
+// settle it with readVerified.
+func writeCacheEntry(path string, data []byte) error {
+	if err := writeCacheFileAtomic(path+checksumExt, checksum(data)); err != nil {
+		return err
+	}
+	return writeCacheFileAtomic(path, data)
+}
+
+// removeCacheEntry deletes an unusable entry and its checksum.
+func removeCacheEntry(path string) {
+	os.Remove(path)
+	os.Remove(path + checksumExt)
+}
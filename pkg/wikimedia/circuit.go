@@ -0,0 +1,120 @@
+package wikimedia
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while the API is being skipped after repeated
+// failures and no cached copy of the requested day exists.
+var ErrCircuitOpen = errors.New("Wikimedia API temporarily disabled after repeated failures")
+
+// breaker is a circuit breaker whose state lives in the cache directory. Every
+// caller is a separate door process, so keeping the count on disk is what lets
+// one caller's timeouts spare the next caller from waiting them out again.
+type breaker struct {
+	path      string
+	threshold int // consecutive failures before opening; 0 disables the breaker
+	cooldown  time.Duration
+	mu        sync.Mutex // held while updating the state, so no count is lost
+	onOpen    func(failures int, until time.Time)
+}
+
+// breakerState is the on-disk form of the circuit.
+type breakerState struct {
+	Failures  int       `json:"failures"`
+	OpenUntil time.Time `json:"open_until"`
+}
+
+// SetCircuitBreaker opens the circuit after threshold consecutive failed fetches.
+// While open (for cooldown), fetches are answered from cache of any age, or
+// Options.Fallback, or fail immediately with ErrCircuitOpen. A threshold of
+// zero disables the breaker.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.breaker.threshold = threshold
+	c.breaker.cooldown = cooldown
+}
+
+// OnCircuitOpen registers fn to be called when the circuit breaker opens
+// after failures fetches in a row failed, to stay open until until. It is
+// called once an outage, not again each time a failure after the cool-down
+// reopens the circuit; a successful fetch starts a new count.
+func (c *Client) OnCircuitOpen(fn func(failures int, until time.Time)) {
+	c.breaker.onOpen = fn
+}
+
+// RetryAt returns when fetches will next try the API while the circuit
+// breaker is open, or the zero time while it is closed.
+func (c *Client) RetryAt() time.Time {
+	if c.breaker.threshold <= 0 {
+		return time.Time{}
+	}
+	until := c.breaker.load().OpenUntil
+	if !time.Now().Before(until) {
+		return time.Time{}
+	}
+	return until
+}
+
+func (b *breaker) load() breakerState {
+	var st breakerState
+	if data, err := os.ReadFile(b.path); err == nil {
+		_ = json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+func (b *breaker) save(st breakerState) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return
+	}
+	if err := writeCacheFileAtomic(b.path, data); err != nil {
+		log.Printf("circuit breaker: failed to save state %s: %v", b.path, err)
+	}
+}
+
+// isOpen reports whether fetches should skip the network right now.
+func (b *breaker) isOpen() bool {
+	if b.threshold <= 0 {
+		return false
+	}
+	return time.Now().Before(b.load().OpenUntil)
+}
+
+// recordFailure counts a failed fetch and opens the circuit once the threshold is reached.
+func (b *breaker) recordFailure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.load()
+	st.Failures++
+	if st.Failures >= b.threshold {
+		// The count is kept at or above the threshold, so a single failure after
+		// the cool-down reopens the circuit straight away.
+		st.OpenUntil = time.Now().Add(b.cooldown)
+		log.Printf("circuit breaker: opened for %v after %d consecutive failures", b.cooldown, b.threshold)
+		if st.Failures == b.threshold && b.onOpen != nil {
+			b.onOpen(st.Failures, st.OpenUntil)
+		}
+	}
+	b.save(st)
+}
+
+// recordSuccess closes the circuit and clears the failure count.
+func (b *breaker) recordSuccess() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if st := b.load(); st.Failures != 0 || !st.OpenUntil.IsZero() {
+		b.save(breakerState{})
+	}
+}
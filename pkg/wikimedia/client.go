@@ -0,0 +1,705 @@
+package wikimedia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Event is the minimal representation returned to callers.
+type Event struct {
+	Year  int    `json:"year"`
+	Text  string `json:"text"`
+	Pages []Page `json:"pages,omitempty"`
+}
+
+// Page is a Wikipedia article linked from an event. The first page is the
+// event's primary article.
+type Page struct {
+	Title string `json:"title"` // normalized, human-readable title
+	URL   string `json:"url"`   // desktop article URL (percent-encoded)
+	// Description is the article's short description, such as "1066 battle
+	// in England", where the feed has one.
+	Description string `json:"description,omitempty"`
+}
+
+// Client provides fetching with an on-disk TTL cache. Once configured, a
+// Client is safe for concurrent use, so one can serve every session of a
+// long-running process and prefetch alongside them: concurrent fetches of
+// the same response share a single request, and cache writes take turns.
+// Options configure it when it is made by New; the Set methods and the On
+// hooks change that configuration and must be called before it is
+// shared.
+type Client struct {
+	cacheDir  string
+	lang      string
+	agent     string
+	ttl       time.Duration
+	ttls      map[string]time.Duration // per-section overrides of ttl
+	maxSize   int64                    // cache size cap in bytes, 0 for none
+	mem       *memCache
+	client    *http.Client
+	onRequest func(RequestInfo)
+	onDamage  func(path string, err error)
+	breaker   *breaker
+	swr       bool
+	offline   bool                                     // serve only from the cache; see Options.Offline
+	fallback  func(section, month, day string) []Event // for days with no copy; see Options.Fallback
+	bg        sync.WaitGroup                           // background refreshes
+	retry     RetryPolicy
+	flights   singleflight.Group // fetches in progress, one per response
+	writes    sync.Mutex         // held while writing to the cache
+}
+
+// RetryPolicy controls how a fetch retries. The overall deadline is the
+// caller's context; each attempt is additionally bounded by AttemptTimeout.
+type RetryPolicy struct {
+	Attempts       int           // total tries, including the first
+	AttemptTimeout time.Duration // limit for a single request
+	Backoff        time.Duration // wait before the first retry, doubled for each further retry
+	Jitter         time.Duration // each wait is randomly shifted by up to this much either way
+}
+
+// DefaultRetryPolicy is used unless Options.Retry or SetRetryPolicy says otherwise.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:       3,
+		AttemptTimeout: 12 * time.Second,
+		Backoff:        500 * time.Millisecond,
+		Jitter:         100 * time.Millisecond,
+	}
+}
+
+// SetRetryPolicy replaces the retry policy. Attempts below one are treated as one.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	if p.Attempts < 1 {
+		p.Attempts = 1
+	}
+	c.retry = p
+}
+
+// RequestInfo describes a single HTTP attempt made by the client. It is passed to
+// the hook registered with OnRequest so callers can log latency and failures.
+type RequestInfo struct {
+	URL     string
+	Attempt int // 1-based; anything above 1 is a retry
+	Status  int // HTTP status code, 0 if no response was received
+	Bytes   int // response body size
+	Latency time.Duration
+	Err     error // transport or read error, nil for any completed response
+}
+
+// OnRequest registers fn to be called after every HTTP attempt, including
+// retries. Fetches running at once call it from their own goroutines.
+func (c *Client) OnRequest(fn func(RequestInfo)) {
+	c.onRequest = fn
+}
+
+// OnCacheDamage registers fn to be called when a cached entry can't be used
+// because it doesn't match its checksum or doesn't parse. The entry at path
+// is deleted and fetched again.
+func (c *Client) OnCacheDamage(fn func(path string, err error)) {
+	c.onDamage = fn
+}
+
+// NewClient creates a client caching in cacheDir, with responses fresh for
+// ttl and everything else at its default. It is New(Options{CacheDir:
+// cacheDir, TTL: ttl}).
+func NewClient(cacheDir string, ttl time.Duration) *Client {
+	return New(Options{CacheDir: cacheDir, TTL: ttl})
+}
+
+// SetSectionTTL sets how long cached responses for one section of the feed
+// stay fresh, overriding the client's TTL for that section. A ttl of zero
+// goes back to the client's TTL.
+func (c *Client) SetSectionTTL(section string, ttl time.Duration) {
+	if c.ttls == nil {
+		c.ttls = make(map[string]time.Duration)
+	}
+	if ttl <= 0 {
+		delete(c.ttls, section)
+		return
+	}
+	c.ttls[section] = ttl
+}
+
+// ttlFor returns the cache TTL for section.
+func (c *Client) ttlFor(section string) time.Duration {
+	if ttl, ok := c.ttls[section]; ok {
+		return ttl
+	}
+	if section == SectionSummary {
+		return DefaultSummaryTTL
+	}
+	return c.ttl
+}
+
+// CacheDir returns the directory holding cached API responses.
+func (c *Client) CacheDir() string {
+	return c.cacheDir
+}
+
+// LastFetched returns when the newest response in the cache was fetched, or
+// the zero time when the cache is empty.
+func (c *Client) LastFetched() time.Time {
+	files, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return time.Time{}
+	}
+	var newest time.Time
+	for _, f := range files {
+		if !isCacheEntry(f.Name()) {
+			continue
+		}
+		// An entry's time is when it was fetched; see markUsed
+		if info, err := f.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// Ping checks that the Wikimedia feed API is reachable with a single request
+// and no retries. Any non-200 status is returned as an error.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.offline {
+		return ErrOffline
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", onThisDayKey(c.lang, SectionEvents, "01", "01").url(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.agent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
+// Result is the outcome of a fetch along with where the data came from.
+type Result struct {
+	Events []Event
+	// FetchedAt is when the data was retrieved from the API; for cached data it
+	// is the time the cache entry was written.
+	FetchedAt time.Time
+	// Stale is set when the API could not be used and an expired cache entry was
+	// served instead; Err is then why the API couldn't be used.
+	Stale bool
+	Err   error
+	// Fallback is set, along with Stale, when there was no cached copy
+	// either and the events came from Options.Fallback. FetchedAt is zero.
+	Fallback bool
+}
+
+// Sections of the "On this day" feed. Each is served by its own endpoint.
+const (
+	SectionEvents   = "events"
+	SectionBirths   = "births"
+	SectionDeaths   = "deaths"
+	SectionHolidays = "holidays"
+)
+
+// Sections of the featured content feed rather than "On this day". Both
+// come from the same response and carry no year. SectionFeatured is the
+// day's featured article, a single event whose text is its summary;
+// SectionNews is the "In the news" stories, one event each.
+const (
+	SectionFeatured = "featured"
+	SectionNews     = "news"
+)
+
+// Sections lists every section FetchSection serves, "On this day" first.
+var Sections = []string{SectionEvents, SectionBirths, SectionDeaths, SectionHolidays, SectionFeatured, SectionNews}
+
+// FetchOnThisDay fetches events for the given month and day (MM, DD).
+// If bypassCache is false, a fresh cached response (modtime within TTL) will be used.
+// When the API fails (or the circuit is open) an expired cache entry is served
+// with Result.Stale set rather than returning an error.
+func (c *Client) FetchOnThisDay(ctx context.Context, month, day string, bypassCache bool) (*Result, error) {
+	return c.FetchSection(ctx, SectionEvents, month, day, bypassCache)
+}
+
+// FetchSection fetches one section of the feed (SectionEvents, SectionBirths, ...)
+// for the given month and day, with the same caching, retry and fallback
+// behavior as FetchOnThisDay. Holidays, the featured article and the news
+// carry no year.
+//
+// A month or day left empty fails with ErrBadDate and a section not in
+// Sections with ErrUnknownSection. A status other than 200 from the API is
+// a *StatusError, ErrCircuitOpen is returned while the circuit breaker is
+// open, and ErrOffline by an offline client; all only when no cached copy of
+// any age is left to serve. An offline client, or one whose circuit is
+// open, serves Options.Fallback's entries before failing.
+func (c *Client) FetchSection(ctx context.Context, section, month, day string, bypassCache bool) (*Result, error) {
+	if month == "" || day == "" {
+		return nil, ErrBadDate
+	}
+	var key cacheKey
+	switch section {
+	case SectionEvents, SectionBirths, SectionDeaths, SectionHolidays:
+		key = onThisDayKey(c.lang, section, month, day)
+	case SectionFeatured, SectionNews:
+		// Featured content is dated; the door shows this year's
+		key = featuredKey(c.lang, time.Now().Year(), month, day)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownSection, section)
+	}
+	return c.fetchEntry(ctx, key, section, bypassCache)
+}
+
+// fetchEntry fetches the response key names, parsed as section, from the
+// cache or the API, falling back to an expired copy as FetchSection does.
+func (c *Client) fetchEntry(ctx context.Context, key cacheKey, section string, bypassCache bool) (*Result, error) {
+	ctx = forSection(ctx, section)
+	report(ctx, Progress{Stage: StageCache})
+	defer report(ctx, Progress{Stage: StageDone})
+
+	cacheFile := filepath.Join(c.cacheDir, key.fileName())
+	url := key.url()
+
+	// Try cache (use only when not bypassing and cache is fresh)
+	if !bypassCache {
+		res, err := c.readCacheFile(cacheFile, section, c.ttlFor(section))
+		if errors.Is(err, errCacheExpired) && c.swr && !c.offline {
+			// Serve the expired copy now and refresh it for the next caller
+			if res, err = c.readCacheFile(cacheFile, section, 0); err == nil {
+				c.revalidate(url, cacheFile, section)
+				return res, nil
+			}
+		}
+		switch {
+		case err == nil:
+			return res, nil
+		case !os.IsNotExist(err) && !errors.Is(err, errCacheExpired):
+			// A damaged entry is dropped, so it can't be served stale either,
+			// and fetched again
+			log.Printf("FetchOnThisDay: cached file %s unusable, refetching: %v", cacheFile, err)
+			removeCacheEntry(cacheFile)
+			if c.onDamage != nil {
+				c.onDamage(cacheFile, err)
+			}
+		}
+	}
+
+	// Offline, or while the circuit is open, skip the network entirely and
+	// fall back to whatever copy is on disk, however old.
+	if c.offline {
+		return c.staleOrFallback(key, section, ErrOffline)
+	}
+	if c.breaker.isOpen() {
+		return c.staleOrFallback(key, section, ErrCircuitOpen)
+	}
+
+	// Callers wanting the same response wait on one request, which runs to
+	// its deadline even if the caller that started it gives up. Each caller
+	// waits only as long as its own context allows.
+	flight := c.flights.DoChan(section+" "+url+" "+strconv.FormatBool(bypassCache), func() (any, error) {
+		fctx := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			fctx, cancel = context.WithDeadline(fctx, deadline)
+			defer cancel()
+		}
+		return c.fetch(fctx, url, cacheFile, section, bypassCache)
+	})
+	select {
+	case r := <-flight:
+		if r.Err != nil {
+			return c.staleOr(cacheFile, section, r.Err)
+		}
+		f := r.Val.(fetched)
+		if f.err != nil {
+			return nil, f.err
+		}
+		evs := f.events
+		if r.Shared {
+			// Each caller gets its own slice to reorder
+			evs = slices.Clone(evs)
+		}
+		return &Result{Events: evs, FetchedAt: time.Now()}, nil
+	case <-ctx.Done():
+		return c.staleOr(cacheFile, section, ctx.Err())
+	}
+}
+
+// fetched is the outcome of a fetch that reached the API: the parsed
+// section, or why the response couldn't be parsed.
+type fetched struct {
+	events []Event
+	err    error
+}
+
+// fetch requests url for section, counting the outcome against the circuit
+// breaker, and caches a response that parses unless bypassCache is set. The
+// error is for a request that failed; a response that didn't parse is
+// reported in fetched.err, as there is no falling back to the cache for it.
+func (c *Client) fetch(ctx context.Context, url, cacheFile, section string, bypassCache bool) (fetched, error) {
+	body, err := c.fetchRemote(ctx, url)
+	var status *StatusError
+	switch {
+	case err == nil, errors.As(err, &status) && !status.Temporary():
+		// The API is up, if only to say an edition has no such feed
+		c.breaker.recordSuccess()
+	case !errors.Is(err, context.Canceled):
+		c.breaker.recordFailure()
+	}
+	if err != nil {
+		return fetched{}, err
+	}
+
+	report(ctx, Progress{Stage: StageParse})
+	evs, err := parseSection(body, section)
+	if err != nil {
+		return fetched{err: err}, nil
+	}
+
+	// Best-effort cache write (atomic) unless caller requested bypass.
+	if !bypassCache {
+		if err := c.store(cacheFile, body); err != nil {
+			log.Printf("FetchOnThisDay: failed to write cache file %s: %v", cacheFile, err)
+		}
+	}
+	return fetched{events: evs}, nil
+}
+
+// store writes a response to the cache and trims the cache to its size cap.
+// Fetches and background refreshes take turns, so an entry and its checksum
+// always come from the same response.
+func (c *Client) store(cacheFile string, body []byte) error {
+	c.writes.Lock()
+	defer c.writes.Unlock()
+	defer c.evict(cacheFile)
+	return writeCacheEntry(cacheFile, body)
+}
+
+// staleOr serves the cache entry at path regardless of age, marked stale, or
+// returns fetchErr when there is no usable copy.
+func (c *Client) staleOr(path, section string, fetchErr error) (*Result, error) {
+	res, err := c.readCacheFile(path, section, 0)
+	if err != nil {
+		return nil, fetchErr
+	}
+	log.Printf("FetchOnThisDay: serving stale cache %s (from %s): %v", path, res.FetchedAt.Format(time.RFC3339), fetchErr)
+	res.Stale, res.Err = true, fetchErr
+	return res, nil
+}
+
+// staleOrFallback is staleOr for the key's entry, serving the fallback's
+// events for the day when there is no usable copy either.
+func (c *Client) staleOrFallback(key cacheKey, section string, fetchErr error) (*Result, error) {
+	res, err := c.staleOr(filepath.Join(c.cacheDir, key.fileName()), section, fetchErr)
+	if err == nil || c.fallback == nil || key.Month == "" {
+		return res, err
+	}
+	evs := c.fallback(section, key.Month, key.Day)
+	if len(evs) == 0 {
+		return nil, err
+	}
+	log.Printf("FetchOnThisDay: serving %d fallback %s for %s-%s: %v", len(evs), section, key.Month, key.Day, fetchErr)
+	return &Result{Events: evs, Stale: true, Err: fetchErr, Fallback: true}, nil
+}
+
+// errCacheExpired is returned by readCacheFile for a cache entry older than the TTL.
+var errCacheExpired = errors.New("cache entry expired")
+
+// readCacheFile parses a cached response, or takes it from the memory cache
+// when the file hasn't changed since it was last parsed. A maxAge of zero
+// accepts any age.
+func (c *Client) readCacheFile(path, section string, maxAge time.Duration) (*Result, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && time.Since(fi.ModTime()) > maxAge {
+		return nil, errCacheExpired
+	}
+	memKey := path + "#" + section
+	if evs, ok := c.mem.get(memKey, fi.ModTime(), fi.Size()); ok {
+		markUsed(path)
+		return &Result{Events: evs, FetchedAt: fi.ModTime()}, nil
+	}
+	data, err := readVerified(path)
+	if err != nil {
+		return nil, err
+	}
+	evs, err := parseSection(data, section)
+	if err != nil {
+		return nil, err
+	}
+	markUsed(path)
+	c.mem.put(memKey, fi.ModTime(), fi.Size(), evs)
+	return &Result{Events: evs, FetchedAt: fi.ModTime()}, nil
+}
+
+// fetchRemote GETs url with retries and backoff, returning the body of a 200 response.
+func (c *Client) fetchRemote(ctx context.Context, url string) ([]byte, error) {
+	policy := c.retry
+	backoff := policy.Backoff
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		// retry with backoff unless this was the last attempt or ctx is done
+		if attempt > 1 {
+			report(ctx, Progress{Stage: StageRetry, Attempt: attempt})
+			if err := sleepContext(ctx, backoff, policy.Jitter); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+		}
+
+		body, status, err := c.attempt(ctx, url, attempt)
+		switch {
+		case err != nil:
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, lastErr
+			}
+		case status == http.StatusOK:
+			return body, nil
+		case temporaryStatus(status):
+			// Retry on 429 or 5xx
+			lastErr = &StatusError{Code: status}
+		default:
+			// Non-retryable error: include body for diagnostics
+			return nil, &StatusError{Code: status, Body: string(body[:min(len(body), maxErrorBody)])}
+		}
+	}
+
+	return nil, lastErr
+}
+
+// attempt makes a single request bounded by the policy's per-attempt timeout.
+func (c *Client) attempt(ctx context.Context, url string, attempt int) ([]byte, int, error) {
+	if c.retry.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.retry.AttemptTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", c.agent)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "identity")
+
+	start := time.Now()
+	done := func(status, n int, err error) {
+		if c.onRequest != nil {
+			c.onRequest(RequestInfo{URL: url, Attempt: attempt, Status: status, Bytes: n, Latency: time.Since(start), Err: err})
+		}
+	}
+
+	report(ctx, Progress{Stage: StageRequest, Attempt: attempt})
+	resp, err := c.client.Do(req)
+	if err != nil {
+		done(0, 0, err)
+		return nil, 0, fmt.Errorf("network error: %w", err)
+	}
+	received := func(n int64) {
+		report(ctx, Progress{Stage: StageReceive, Attempt: attempt, Bytes: n, Total: resp.ContentLength})
+	}
+	received(0)
+	body, err := io.ReadAll(&countingReader{r: resp.Body, progress: received})
+	resp.Body.Close()
+	done(resp.StatusCode, len(body), err)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// parseSection extracts one section array (e.g. "events") from a Wikimedia API
+// payload. Payloads from the combined "all" endpoint parse the same way.
+func parseSection(body []byte, section string) ([]Event, error) {
+	switch section {
+	case SectionFeatured:
+		return parseFeatured(body)
+	case SectionNews:
+		return parseNews(body)
+	case SectionSummary:
+		return parseSummary(body)
+	}
+	type rawEvent struct {
+		Year  int    `json:"year"`
+		Text  string `json:"text"`
+		Pages []struct {
+			Titles struct {
+				Normalized string `json:"normalized"`
+			} `json:"titles"`
+			Description string `json:"description"`
+			ContentURLs struct {
+				Desktop struct {
+					Page string `json:"page"`
+				} `json:"desktop"`
+			} `json:"content_urls"`
+		} `json:"pages"`
+	}
+	var apiResp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	var raw []rawEvent
+	if data, ok := apiResp[section]; ok {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %v", err)
+		}
+	}
+	out := make([]Event, 0, len(raw))
+	for _, e := range raw {
+		ev := Event{Year: e.Year, Text: e.Text}
+		for _, p := range e.Pages {
+			ev.Pages = append(ev.Pages, Page{Title: p.Titles.Normalized, URL: p.ContentURLs.Desktop.Page, Description: p.Description})
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// parseFeatured extracts the featured article ("tfa") from a featured
+// content response. A day without one yields no events.
+func parseFeatured(body []byte) ([]Event, error) {
+	var apiResp struct {
+		TFA *struct {
+			Titles struct {
+				Normalized string `json:"normalized"`
+			} `json:"titles"`
+			Extract     string `json:"extract"`
+			ContentURLs struct {
+				Desktop struct {
+					Page string `json:"page"`
+				} `json:"desktop"`
+			} `json:"content_urls"`
+		} `json:"tfa"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	tfa := apiResp.TFA
+	if tfa == nil || tfa.Extract == "" {
+		return nil, nil
+	}
+	return []Event{{
+		Text:  tfa.Extract,
+		Pages: []Page{{Title: tfa.Titles.Normalized, URL: tfa.ContentURLs.Desktop.Page}},
+	}}, nil
+}
+
+// parseNews extracts the "In the news" stories from a featured content
+// response. Stories are HTML; they come back as plain text, each linked to
+// the articles it mentions.
+func parseNews(body []byte) ([]Event, error) {
+	var apiResp struct {
+		News []struct {
+			Story string `json:"story"`
+			Links []struct {
+				Titles struct {
+					Normalized string `json:"normalized"`
+				} `json:"titles"`
+				ContentURLs struct {
+					Desktop struct {
+						Page string `json:"page"`
+					} `json:"desktop"`
+				} `json:"content_urls"`
+			} `json:"links"`
+		} `json:"news"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	out := make([]Event, 0, len(apiResp.News))
+	for _, n := range apiResp.News {
+		ev := Event{Text: stripHTML(n.Story)}
+		if ev.Text == "" {
+			continue
+		}
+		for _, l := range n.Links {
+			ev.Pages = append(ev.Pages, Page{Title: l.Titles.Normalized, URL: l.ContentURLs.Desktop.Page})
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTag     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripHTML reduces a fragment of HTML to its text.
+func stripHTML(s string) string {
+	s = htmlComment.ReplaceAllString(s, "")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// writeCacheFileAtomic writes data to a temp file and renames it into place.
+func writeCacheFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(dir, "tmp-*.json")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	// Rename is atomic on most platforms
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// sleepContext sleeps for d plus or minus up to jitter, but returns early if ctx is cancelled.
+func sleepContext(ctx context.Context, d, jitter time.Duration) error {
+	if jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(2*jitter))) - jitter
+	}
+	if d < 0 {
+		d = 0
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
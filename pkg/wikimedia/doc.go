@@ -0,0 +1,30 @@
+// Package wikimedia fetches Wikipedia's "On this day" and featured content
+// feeds from the Wikimedia API, keeping each response in an on-disk cache
+// that many processes can share, such as every node of a BBS.
+//
+// A Client is made with New from Options and fetches one section of a
+// day's feed at a time with FetchSection: the day's events, births,
+// deaths and holidays, and the featured article and news. Fetches are
+// retried with backoff, and when the API can't be reached an expired
+// cached copy is served with Result.Stale set rather than failing.
+//
+//	c := wikimedia.New(wikimedia.Options{
+//		CacheDir:  "/var/cache/mydoor",
+//		TTL:       24 * time.Hour,
+//		UserAgent: "My Door/1.0 (https://example.com/mydoor)",
+//	})
+//	res, err := c.FetchSection(ctx, wikimedia.SectionBirths, "10", "16", false)
+//
+// FetchSummary fetches the summary of an article an event links to, cached
+// the same way by the article's title.
+//
+// # Stability
+//
+// The package follows semantic versioning with the module: exported names
+// keep their meaning and signatures within a major version. New Options
+// fields, sections and errors may be added, with zero values that keep
+// the behavior before them. Error text is not part of the API; test errors
+// with errors.Is and errors.As. The cache's layout on disk is not part of
+// the API either, beyond that a cache written by an older version is
+// still read.
+package wikimedia
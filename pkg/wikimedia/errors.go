@@ -0,0 +1,49 @@
+package wikimedia
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by FetchSection and FetchSummary for a request they
+// can't make. They are wrapped with the offending value; test for them with
+// errors.Is.
+var (
+	ErrBadDate        = errors.New("month and day required")
+	ErrUnknownSection = errors.New("unknown feed section")
+	ErrNoTitle        = errors.New("article title required")
+)
+
+// ErrOffline is returned by a client made with Options.Offline for a fetch
+// with no cached copy to serve, and by its Ping.
+var ErrOffline = errors.New("offline: the Wikimedia API is not used")
+
+// StatusError is returned when the API answers with a status other than
+// 200 OK. Test for it with errors.As.
+type StatusError struct {
+	Code int
+	// Body is the start of the response, for diagnostics. It is empty for
+	// statuses that were retried.
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("API returned status code: %d", e.Code)
+	}
+	return fmt.Sprintf("API returned status code: %d, body: %s", e.Code, e.Body)
+}
+
+// Temporary reports whether the status is one worth retrying later: 429
+// Too Many Requests or a server error.
+func (e *StatusError) Temporary() bool {
+	return temporaryStatus(e.Code)
+}
+
+func temporaryStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code < 600)
+}
+
+// maxErrorBody caps how much of a response body a StatusError keeps.
+const maxErrorBody = 512
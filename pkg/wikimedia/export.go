@@ -0,0 +1,136 @@
+package wikimedia
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxImportFile bounds a single file read from an import, well above any
+// real day's feed.
+const maxImportFile = 16 << 20
+
+// isCacheEntry reports whether name is a cached response: not the circuit
+// state, the door's list of events shown today, a lock, a temp file or a
+// checksum.
+func isCacheEntry(name string) bool {
+	return strings.HasSuffix(name, ".json") && name != "circuit.json" && name != "shown.json" && !strings.HasPrefix(name, "tmp-")
+}
+
+// ExportCache writes every cached response in dir, with its checksum, to w
+// as a gzipped tarball, keeping file times so the entries age the same way
+// wherever they are imported. It returns the number of responses written.
+func ExportCache(dir string, w io.Writer) (int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	n := 0
+	for _, f := range files {
+		if !isCacheEntry(f.Name()) {
+			continue
+		}
+		for _, name := range []string{f.Name(), f.Name() + checksumExt} {
+			err := addToTar(tw, filepath.Join(dir, name), name)
+			if errors.Is(err, os.ErrNotExist) && name != f.Name() {
+				continue // entries from before checksums have none
+			}
+			if err != nil {
+				return n, err
+			}
+		}
+		n++
+	}
+	if err := tw.Close(); err != nil {
+		return n, err
+	}
+	return n, gz.Close()
+}
+
+func addToTar(tw *tar.Writer, path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: fi.ModTime(), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// ImportCache unpacks a tarball made by ExportCache into dir. Only cache
+// entries and their checksums are taken, by base name; an entry is skipped
+// when dir already has a newer copy. It returns the number of responses
+// imported.
+func ImportCache(dir string, r io.Reader) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	n := 0
+	skipped := map[string]bool{} // entries kept at their local copy
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		name := hdr.Name
+		entry := strings.TrimSuffix(name, checksumExt)
+		if hdr.Typeflag != tar.TypeReg || name != filepath.Base(name) || !isCacheEntry(entry) {
+			return n, fmt.Errorf("%s is not a cache file; is this a cache export?", name)
+		}
+		if hdr.Size > maxImportFile {
+			return n, fmt.Errorf("%s is too large (%d bytes)", name, hdr.Size)
+		}
+		path := filepath.Join(dir, name)
+		if name == entry {
+			if fi, err := os.Stat(path); err == nil && !fi.ModTime().Before(hdr.ModTime) {
+				skipped[entry] = true
+				continue
+			}
+		} else if skipped[entry] {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxImportFile))
+		if err != nil {
+			return n, err
+		}
+		if name == entry {
+			// The local checksum belongs to the copy being replaced; the
+			// export's, if it has one, follows the entry
+			os.Remove(path + checksumExt)
+		}
+		if err := writeCacheFileAtomic(path, data); err != nil {
+			return n, err
+		}
+		// The entry's time is its age for the TTL
+		if err := os.Chtimes(path, time.Now(), hdr.ModTime); err != nil {
+			return n, err
+		}
+		if name == entry {
+			n++
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package wikimedia
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memoryCacheDays is how many parsed responses a client keeps in memory.
+// A day's feed is a few hundred KB of JSON, so re-reading and re-parsing it
+// for every refresh, or for every session of a long-running process sharing
+// the client, is worth avoiding.
+const memoryCacheDays = 16
+
+// memCache is a small LRU of parsed cache entries, layered over the disk
+// cache and keyed by file and section, since one featured content file
+// holds both the featured article and the news. An entry is only used while
+// the file's time and size are unchanged, so a copy rewritten by another
+// node or a background refresh is picked up.
+type memCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of *memEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memEntry struct {
+	key     string
+	modTime time.Time
+	size    int64
+	events  []Event
+}
+
+func newMemCache(max int) *memCache {
+	return &memCache{max: max, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the events cached under key if they were parsed from the file
+// as it is now. The slice is the caller's to reorder.
+func (m *memCache) get(key string, modTime time.Time, size int64) ([]Event, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memEntry)
+	if !e.modTime.Equal(modTime) || e.size != size {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return slices.Clone(e.events), true
+}
+
+// put caches the events parsed from a file under key, dropping the least
+// recently used entry when full.
+func (m *memCache) put(key string, modTime time.Time, size int64, events []Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &memEntry{key: key, modTime: modTime, size: size, events: slices.Clone(events)}
+	if el, ok := m.entries[key]; ok {
+		el.Value = e
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(e)
+	if m.order.Len() > m.max {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memEntry).key)
+	}
+}
@@ -0,0 +1,116 @@
+package wikimedia
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultUserAgent identifies requests made by a client whose Options name
+// no agent of their own. Wikimedia asks API users to identify themselves, so
+// programs other than the door should set Options.UserAgent.
+const DefaultUserAgent = "Go Day-in-History BBS Door/1.0 (github.com/robbiew/history)"
+
+// Options configure a Client made with New. The zero value is a usable
+// configuration: every field left unset takes the default described on it.
+type Options struct {
+	// CacheDir holds cached API responses. It defaults to
+	// "./.cache/wikimedia" and is created if missing.
+	CacheDir string
+	// TTL is how long a cached response stays fresh; SectionTTLs override
+	// it for single sections, keyed by their Section names. Zero keeps
+	// nothing fresh, so every fetch asks the API first.
+	TTL         time.Duration
+	SectionTTLs map[string]time.Duration
+	// MaxCacheSize caps the bytes the cache may hold, as SetMaxCacheSize.
+	// Zero means no cap.
+	MaxCacheSize int64
+	// StaleWhileRevalidate serves expired entries while refreshing them in
+	// the background, as SetStaleWhileRevalidate.
+	StaleWhileRevalidate bool
+	// Retry is how fetches retry. The zero value is DefaultRetryPolicy.
+	Retry RetryPolicy
+	// CircuitThreshold and CircuitCooldown set up the circuit breaker, as
+	// SetCircuitBreaker. A threshold of zero leaves it off.
+	CircuitThreshold int
+	CircuitCooldown  time.Duration
+	// Language is the Wikipedia language edition to fetch, such as "de".
+	// It defaults to DefaultLanguage. Not every edition has every section.
+	Language string
+	// UserAgent is sent with every request. It defaults to DefaultUserAgent.
+	UserAgent string
+	// HTTPClient makes the requests. It defaults to HTTPClient(); a client
+	// given here should have no overall Timeout, as fetches are bounded by
+	// their contexts and the retry policy.
+	HTTPClient *http.Client
+	// OnRequest, if set, is called after every HTTP attempt, as OnRequest.
+	OnRequest func(RequestInfo)
+	// OnCircuitOpen and OnCacheDamage, if set, are called when the circuit
+	// breaker opens and when a damaged cache entry is dropped, as the
+	// methods of the same names.
+	OnCircuitOpen func(failures int, until time.Time)
+	OnCacheDamage func(path string, err error)
+	// Offline keeps the client off the network: fetches are served from
+	// the cache only, a fresh copy as usual and an expired one marked
+	// Stale, with Result.Err set to ErrOffline. With no copy they go to
+	// Fallback, or fail with ErrOffline. Stale-while-revalidate is off.
+	Offline bool
+	// LeaveCache has New leave the cache directory as it finds it: it is
+	// neither created nor are entries cached by older versions renamed.
+	// It is for a client made to look, such as one only asked to Ping.
+	LeaveCache bool
+	// Fallback, if set, supplies a day's entries for section when the
+	// client is offline or its circuit is open, and the cache has no copy
+	// of any age. Its events are served
+	// with Result.Fallback and Stale set; when it returns none, the fetch
+	// fails as it would without it. Month and day are "MM" and "DD".
+	Fallback func(section, month, day string) []Event
+}
+
+// New creates a client configured by opts.
+func New(opts Options) *Client {
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(".", ".cache", "wikimedia")
+	}
+	if !opts.LeaveCache {
+		_ = os.MkdirAll(cacheDir, 0o755)
+		migrateCache(cacheDir)
+	}
+
+	c := &Client{
+		cacheDir:  cacheDir,
+		lang:      opts.Language,
+		agent:     opts.UserAgent,
+		mem:       newMemCache(memoryCacheDays),
+		ttl:       opts.TTL,
+		maxSize:   opts.MaxCacheSize,
+		swr:       opts.StaleWhileRevalidate,
+		offline:   opts.Offline,
+		fallback:  opts.Fallback,
+		breaker:   &breaker{path: filepath.Join(cacheDir, "circuit.json")},
+		client:    opts.HTTPClient,
+		onRequest: opts.OnRequest,
+		onDamage:  opts.OnCacheDamage,
+	}
+	if c.lang == "" {
+		c.lang = DefaultLanguage
+	}
+	if c.agent == "" {
+		c.agent = DefaultUserAgent
+	}
+	if c.client == nil {
+		c.client = HTTPClient()
+	}
+	if opts.Retry == (RetryPolicy{}) {
+		opts.Retry = DefaultRetryPolicy()
+	}
+	c.SetRetryPolicy(opts.Retry)
+	c.SetCircuitBreaker(opts.CircuitThreshold, opts.CircuitCooldown)
+	c.breaker.onOpen = opts.OnCircuitOpen
+	for section, ttl := range opts.SectionTTLs {
+		c.SetSectionTTL(section, ttl)
+	}
+	return c
+}
@@ -0,0 +1,84 @@
+package wikimedia
+
+import (
+	"context"
+	"io"
+)
+
+// Stage is how far a fetch has got.
+type Stage int
+
+const (
+	// StageCache is looking for a fresh copy in the cache.
+	StageCache Stage = iota
+	// StageRequest is waiting for the API to answer a request.
+	StageRequest
+	// StageReceive is reading the API's answer; Progress.Bytes says how
+	// much has arrived.
+	StageReceive
+	// StageRetry is waiting to try the API again after a failed attempt.
+	StageRetry
+	// StageParse is reading the events out of the answer.
+	StageParse
+	// StageDone is a fetch that has returned, whatever its outcome.
+	StageDone
+)
+
+// Progress reports a fetch moving on.
+type Progress struct {
+	Section string
+	Stage   Stage
+	// Attempt is the request being made, from 1, once one has been.
+	Attempt int
+	// Bytes is how much of the answer has arrived, and Total its size when
+	// the API says, or -1.
+	Bytes int64
+	Total int64
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context that has every fetch made with it report to
+// fn as it moves through its stages, so a caller can show real progress
+// rather than a guess. Fetches running at once call fn from their own
+// goroutines. A fetch that joins one already under way for the same answer
+// reports only its cache check and when it is done.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// forSection has the progress reported through the returned context name
+// section.
+func forSection(ctx context.Context, section string) context.Context {
+	fn, ok := ctx.Value(progressKey{}).(func(Progress))
+	if !ok {
+		return ctx
+	}
+	return WithProgress(ctx, func(p Progress) {
+		p.Section = section
+		fn(p)
+	})
+}
+
+// report tells ctx's progress function, if it has one, of p.
+func report(ctx context.Context, p Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		fn(p)
+	}
+}
+
+// countingReader reports the bytes read through it as StageReceive progress.
+type countingReader struct {
+	r        io.Reader
+	progress func(n int64)
+	n        int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.n += int64(n)
+		c.progress(c.n)
+	}
+	return n, err
+}
@@ -0,0 +1,85 @@
+package wikimedia
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// SetMaxCacheSize caps the bytes the cache may hold. After each write the
+// least recently used days are deleted until it fits again. Zero, the
+// default, means no cap.
+func (c *Client) SetMaxCacheSize(n int64) {
+	c.maxSize = n
+}
+
+// markUsed records a read of the entry at path for LRU eviction. The time on
+// its checksum file doubles as the last-used time; the entry's own time is
+// its age for the TTL and must stay put.
+func markUsed(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path+checksumExt, now, now)
+}
+
+// cacheEntry is one cached response on disk, with its checksum file.
+type cacheEntry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// evict deletes least recently used entries until the cache fits within the
+// size cap. keep, the entry just written, is never deleted.
+func (c *Client) evict(keep string) {
+	if c.maxSize <= 0 {
+		return
+	}
+	files, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return
+	}
+	var entries []cacheEntry
+	var total int64
+	for _, f := range files {
+		name := f.Name()
+		if !isCacheEntry(name) {
+			continue
+		}
+		path := filepath.Join(c.cacheDir, name)
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		e := cacheEntry{path: path, size: info.Size(), lastUsed: info.ModTime()}
+		if sum, err := os.Stat(path + checksumExt); err == nil {
+			e.size += sum.Size()
+			if sum.ModTime().After(e.lastUsed) {
+				e.lastUsed = sum.ModTime()
+			}
+		}
+		total += e.size
+		entries = append(entries, e)
+	}
+	if total <= c.maxSize {
+		return
+	}
+
+	slices.SortFunc(entries, func(a, b cacheEntry) int { return a.lastUsed.Compare(b.lastUsed) })
+	removed := 0
+	for _, e := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		if e.path == keep {
+			continue
+		}
+		removeCacheEntry(e.path)
+		total -= e.size
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("cache over %d bytes, evicted %d least recently used file(s)", c.maxSize, removed)
+	}
+}
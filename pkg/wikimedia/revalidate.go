@@ -0,0 +1,73 @@
+package wikimedia
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/robbiew/history/internal/filelock"
+)
+
+const (
+	// revalidateTimeout bounds a background refresh.
+	revalidateTimeout = 45 * time.Second
+	// staleLockAge is how old a refresh lock must be before it is assumed to
+	// belong to a process that died mid-refresh.
+	staleLockAge = 2 * time.Minute
+)
+
+// SetStaleWhileRevalidate makes an expired cache entry be served immediately
+// while a single background refresh updates it for the next caller. Refreshes
+// are coordinated through a lock file, so only one process refreshes a given
+// day at a time.
+func (c *Client) SetStaleWhileRevalidate(on bool) {
+	c.swr = on
+}
+
+// Wait blocks until background refreshes finish or timeout passes. Call it
+// before exiting so a refresh started for this caller isn't cut off.
+func (c *Client) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.bg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// revalidate refreshes cacheFile from url in the background unless another
+// process is already doing so or the circuit is open.
+func (c *Client) revalidate(url, cacheFile, section string) {
+	if c.breaker.isOpen() {
+		return
+	}
+	unlock, err := filelock.TryLock(cacheFile+".lock", staleLockAge)
+	if err != nil {
+		return
+	}
+	c.bg.Add(1)
+	go func() {
+		defer c.bg.Done()
+		defer unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+		defer cancel()
+
+		body, err := c.fetchRemote(ctx, url)
+		if err != nil {
+			c.breaker.recordFailure()
+			log.Printf("revalidate: refresh of %s failed: %v", cacheFile, err)
+			return
+		}
+		c.breaker.recordSuccess()
+		if _, err := parseSection(body, section); err != nil {
+			log.Printf("revalidate: refresh of %s returned bad data: %v", cacheFile, err)
+			return
+		}
+		if err := c.store(cacheFile, body); err != nil {
+			log.Printf("revalidate: failed to write cache file %s: %v", cacheFile, err)
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+package wikimedia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SectionSummary names page summaries, fetched with FetchSummary, for
+// SetSectionTTL and Options.SectionTTLs. They are not a section of the feed,
+// and FetchSection doesn't serve them.
+const SectionSummary = "summary"
+
+// DefaultSummaryTTL is how long a page summary stays fresh unless
+// SectionSummary's TTL is set. An article's lead changes far more slowly
+// than the feeds, so a summary is kept a month rather than a day.
+const DefaultSummaryTTL = 30 * 24 * time.Hour
+
+const endpointSummary = "page/summary"
+
+// summaryKey is the summary of the article called title.
+func summaryKey(lang, title string) cacheKey {
+	return cacheKey{Source: DefaultSource, Lang: lang, Endpoint: endpointSummary, Title: title}
+}
+
+// FetchSummary fetches the summary of the article called title, with
+// spaces or underscores between its words: a single event whose text is
+// the article's lead and whose page is the article. It is cached by title,
+// for DefaultSummaryTTL unless SectionSummary's TTL says otherwise, and
+// fetched, retried and served stale when the API fails as FetchSection's
+// responses are, so a summary read once is there offline too. An article
+// without a summary yields no events.
+//
+// An empty title fails with ErrNoTitle. The other errors are FetchSection's.
+func (c *Client) FetchSummary(ctx context.Context, title string, bypassCache bool) (*Result, error) {
+	title = strings.TrimSpace(strings.ReplaceAll(title, "_", " "))
+	if title == "" {
+		return nil, ErrNoTitle
+	}
+	return c.fetchEntry(ctx, summaryKey(c.lang, title), SectionSummary, bypassCache)
+}
+
+// parseSummary extracts the lead of a page summary response.
+func parseSummary(body []byte) ([]Event, error) {
+	var apiResp struct {
+		Titles struct {
+			Normalized string `json:"normalized"`
+		} `json:"titles"`
+		Description string `json:"description"`
+		Extract     string `json:"extract"`
+		ContentURLs struct {
+			Desktop struct {
+				Page string `json:"page"`
+			} `json:"desktop"`
+		} `json:"content_urls"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	if apiResp.Extract == "" {
+		return nil, nil
+	}
+	return []Event{{
+		Text:  apiResp.Extract,
+		Pages: []Page{{Title: apiResp.Titles.Normalized, URL: apiResp.ContentURLs.Desktop.Page, Description: apiResp.Description}},
+	}}, nil
+}
@@ -0,0 +1,32 @@
+package wikimedia
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// sharedTransport is the one Transport behind every Client and HTTPClient, so
+// connections to the API are pooled and reused across fetches (sections fetched
+// in parallel, background refreshes, update checks) instead of each request
+// paying for its own DNS lookup and TLS handshake.
+var sharedTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          20,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 15 * time.Second,
+	ExpectContinueTimeout: time.Second,
+}
+
+// HTTPClient returns an http.Client on the shared transport. It has no overall
+// Timeout; callers bound requests with a context.
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: sharedTransport}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/plugin"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// pluginScreen is a plugin, or another screen beside the day's, the caller
+// may open from the day's screens. open is given the day's pages; help,
+// when set, is the help screen's line for it in place of label.
+type pluginScreen struct {
+	action keymap.Action
+	label  string
+	help   string
+	open   func([]terminal.Page) view
+}
+
+// pluginScreens lists the configured plugins the caller's security level
+// reaches, each opening a pluginView for date whose command runs under ctx.
+func pluginScreens(ctx context.Context, cfg config.Config, termCfg terminal.TerminalConfig, bindings *keymap.Map, session *dropfile.DoorSession, date time.Time, save func(terminal.Page)) []pluginScreen {
+	// A -date override has no year; plugins get this year's
+	day := datedDay(date)
+	var screens []pluginScreen
+	for _, p := range cfg.Plugins {
+		if session.SecLevel < p.MinLevel {
+			continue
+		}
+		label := p.Label
+		if label == "" {
+			label = p.Name
+		}
+		req := plugin.Request{
+			Screen:   p.Name,
+			Date:     day.Format(time.DateOnly),
+			User:     session.UserName,
+			Node:     session.Node,
+			SecLevel: session.SecLevel,
+			TimeLeft: session.TimeLeft,
+			Width:    terminal.LinesWidth,
+			Height:   terminal.LinesHeight,
+		}
+		screens = append(screens, pluginScreen{
+			action: keymap.PluginAction(p.Name),
+			label:  label,
+			open: func([]terminal.Page) view {
+				return &pluginView{ctx: ctx, termCfg: termCfg, bindings: bindings, plugin: p, label: label, req: req, date: date, save: save}
+			},
+		})
+	}
+	return screens
+}
+
+// pluginView shows what a plugin's command drew. The command runs when the
+// view opens and again on refresh; any key without a binding goes back.
+type pluginView struct {
+	ctx      context.Context
+	termCfg  terminal.TerminalConfig
+	bindings *keymap.Map
+	plugin   config.Plugin
+	label    string
+	req      plugin.Request
+	date     time.Time
+	save     func(terminal.Page)
+
+	page *terminal.Page // nil until the command has run
+}
+
+func (v *pluginView) draw() {
+	if v.page == nil {
+		v.page = v.run()
+	}
+	page := *v.page
+	page.Commands = v.commands()
+	terminal.RenderEvents(v.termCfg, page)
+}
+
+// run runs the command and turns its answer, or its failure, into a page.
+func (v *pluginView) run() *terminal.Page {
+	terminal.SetStatus(v.termCfg, "plugin", "Loading "+v.label+"...")
+	defer terminal.SetStatus(v.termCfg, "plugin", "")
+
+	page := &terminal.Page{Kind: terminal.KindPlugin, Date: v.date, Title: v.label}
+	resp, err := plugin.Run(v.ctx, v.plugin.Command, v.plugin.RunTimeout(), v.req)
+	switch {
+	case err != nil:
+		slog.Warn("plugin failed", "plugin", v.plugin.Name, "error", err)
+		page.Lines = []string{"Sorry, " + v.label + " isn't available right now."}
+	case resp.Error != "":
+		page.Lines = []string{displayText(resp.Error, v.termCfg.Charset)}
+	default:
+		if resp.Title != "" {
+			page.Title = displayText(resp.Title, v.termCfg.Charset)
+		}
+		for _, line := range resp.Lines {
+			page.Lines = append(page.Lines, displayText(line, v.termCfg.Charset))
+		}
+	}
+	return page
+}
+
+func (v *pluginView) handle(_ input.Event, action keymap.Action) step {
+	switch action {
+	case keymap.Quit:
+		return quit
+	case keymap.Refresh:
+		v.page = nil
+		return redraw
+	case keymap.Save:
+		if v.save == nil {
+			return back
+		}
+		v.save(*v.page)
+		return stay
+	case keymap.Help:
+		return open(helpView{termCfg: v.termCfg, entries: v.help()})
+	}
+	return back
+}
+
+// pluginActions are the actions a plugin screen answers to, with their
+// labels there; any other key goes back.
+var pluginActions = []struct {
+	action keymap.Action
+	label  string
+	help   string
+}{
+	{keymap.Next, "Back", "Back to the day's screens"},
+	{keymap.Refresh, "Refresh", "Run this screen again"},
+	{keymap.Save, "Save", "Save this screen to your downloads"},
+	{keymap.Help, "Help", "This help"},
+	{keymap.Quit, "Quit", "Back to the BBS"},
+}
+
+func (v *pluginView) commands() []terminal.Command {
+	var commands []terminal.Command
+	for _, a := range pluginActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		if key := barKey(v.bindings.Keys(a.action), a.label); key != "" {
+			commands = append(commands, terminal.Command{Key: key, Label: a.label})
+		}
+	}
+	return commands
+}
+
+func (v *pluginView) help() []terminal.HelpEntry {
+	var entries []terminal.HelpEntry
+	for _, a := range pluginActions {
+		if a.action == keymap.Save && v.save == nil {
+			continue
+		}
+		entries = append(entries, terminal.HelpEntry{Keys: v.bindings.Keys(a.action), Text: a.help})
+	}
+	return entries
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// previewOptions describes the fake caller used by -preview.
+type previewOptions struct {
+	Enabled   bool
+	UserName  string
+	Cols      int
+	Rows      int
+	Emulation int
+	SecLevel  int
+	// Screen forces one of the non-event screens: "" (normal), "error" or "empty".
+	Screen string
+}
+
+// registerPreviewFlags adds the -preview flag family to fs.
+func registerPreviewFlags(fs *flag.FlagSet) *previewOptions {
+	p := &previewOptions{}
+	fs.BoolVar(&p.Enabled, "preview", false, "run locally as a fake caller using a synthetic dropfile (no -path needed)")
+	fs.StringVar(&p.UserName, "preview-user", "Sysop", "user name for -preview")
+	fs.IntVar(&p.Cols, "preview-cols", 80, "terminal width for -preview")
+	fs.IntVar(&p.Rows, "preview-rows", 25, "terminal height for -preview")
+	fs.IntVar(&p.Emulation, "preview-emulation", 1, "door32.sys emulation for -preview (0=ASCII, 1=ANSI)")
+	fs.IntVar(&p.SecLevel, "preview-seclevel", 255, "security level for -preview, to check min_levels")
+	fs.StringVar(&p.Screen, "preview-screen", "", "force a screen for -preview: error|empty")
+	return p
+}
+
+// writeDropFile writes a synthetic door32.sys for the preview caller into a new
+// temporary directory and returns that directory.
+func (p *previewOptions) writeDropFile() (string, error) {
+	switch p.Screen {
+	case "", "error", "empty":
+	default:
+		return "", fmt.Errorf("unknown -preview-screen %q, expected error or empty", p.Screen)
+	}
+	dir, err := os.MkdirTemp("", "history-preview-")
+	if err != nil {
+		return "", err
+	}
+	// comm type, handle, baud, BBS name, user #, real name, handle, security, minutes left, emulation, node
+	drop := fmt.Sprintf("0\n0\n38400\nPreview BBS\n1\n%s\n%s\n%d\n60\n%d\n1\n", p.UserName, p.UserName, p.SecLevel, p.Emulation)
+	if err := os.WriteFile(filepath.Join(dir, "door32.sys"), []byte(drop), 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// simulatedError returns the fetch error a forced preview screen should produce.
+func (p *previewOptions) simulatedError() error {
+	if p.Screen == "error" {
+		return errors.New("preview: simulated fetch failure")
+	}
+	return nil
+}
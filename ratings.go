@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/robbiew/history/internal/ratings"
+	"github.com/robbiew/history/internal/seen"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// rateNoticeTime is how long the status row shows a rating's new score.
+const rateNoticeTime = 5 * time.Second
+
+// rateFunc records the caller's vote, 1 for up or -1 for down, on e, an
+// entry of a page of kind.
+type rateFunc func(kind string, e terminal.Event, vote int)
+
+// openRatings opens the board's ratings at path. It returns nil, which
+// leaves rating off, when path is empty. Ratings that can't be read are
+// logged and start empty.
+func openRatings(path string) *ratings.Book {
+	book, err := ratings.Open(path)
+	if err != nil {
+		slog.Warn("could not read ratings", "file", path, "error", err)
+	}
+	return book
+}
+
+// rater returns the rate action for detail pages: it records user's vote in
+// book and says on the status row where the entry's score now stands. It
+// returns nil when book is nil.
+func rater(termCfg terminal.TerminalConfig, book *ratings.Book, user string) rateFunc {
+	if book == nil {
+		return nil
+	}
+	return func(kind string, e terminal.Event, vote int) {
+		score, err := book.Rate(seen.ID(kind, e.Year, e.Key), user, vote)
+		notice := fmt.Sprintf("Thanks! Callers here rate this %+d", score)
+		if err != nil {
+			slog.Warn("could not save rating", "error", err)
+			notice = "Sorry, your rating could not be saved"
+		}
+		terminal.SetStatus(termCfg, "rate", notice)
+		time.AfterFunc(rateNoticeTime, func() { terminal.SetStatus(termCfg, "rate", "") })
+	}
+}
+
+// favorites returns up to n of events that callers have rated above zero,
+// best first, with ties in the feed's order.
+func favorites(events []wikimedia.Event, section string, book *ratings.Book, n int) []wikimedia.Event {
+	if n <= 0 || book == nil {
+		return nil
+	}
+	type rated struct {
+		e     wikimedia.Event
+		score int
+	}
+	var liked []rated
+	for _, e := range events {
+		if score := book.Score(seen.ID(section, e.Year, sanitizeText(e.Text))); score > 0 {
+			liked = append(liked, rated{e, score})
+		}
+	}
+	slices.SortStableFunc(liked, func(a, b rated) int { return cmp.Compare(b.score, a.score) })
+	var picked []wikimedia.Event
+	for _, r := range liked[:min(n, len(liked))] {
+		picked = append(picked, r.e)
+	}
+	return picked
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/robbiew/history/internal/capture"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// startRecording tees the caller's output into a new capture file in dir and
+// returns a func that finishes it. format is "ans" for the raw bytes or
+// "asciicast" for an asciinema recording with timing, which needs to know
+// the session's charset to turn its output into UTF-8. An empty dir records
+// nothing. A capture that can't be written is logged and dropped; the
+// session carries on.
+func startRecording(dir, format string, charset terminal.Charset, session *dropfile.DoorSession) (stop func()) {
+	if dir == "" {
+		return func() {}
+	}
+	ext := ".ans"
+	if format == "asciicast" {
+		ext = ".cast"
+	}
+	start := time.Now()
+	f, err := capture.Create(dir, session.Node, ext, start)
+	if err != nil {
+		slog.Warn("could not start recording", "dir", dir, "error", err)
+		return func() {}
+	}
+	var w io.Writer = f
+	if format == "asciicast" {
+		title := "This Day in History: " + session.UserName + " on " + session.BbsName
+		cast, err := capture.NewCast(f, 80, 25, start, title)
+		if err != nil {
+			slog.Warn("could not start recording", "file", f.Name(), "error", err)
+			f.Close()
+			return func() {}
+		}
+		cast.UTF8 = charset == terminal.UTF8
+		cast.Codepage = charset.Codepage()
+		w = cast
+	}
+	slog.Info("recording session", "file", f.Name())
+	terminal.Tee(capture.NewWriter(w, func(err error) {
+		slog.Warn("recording stopped", "file", f.Name(), "error", err)
+	}))
+	return func() {
+		terminal.Flush()
+		f.Close()
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// saveNoticeTime is how long the status row shows where a screen was saved.
+const saveNoticeTime = 5 * time.Second
+
+// saveDir fills the {user} and {node} placeholders of a save_dir setting.
+func saveDir(pattern string, session *dropfile.DoorSession) string {
+	return strings.NewReplacer("{user}", fileSafe(session.UserName), "{node}", strconv.Itoa(session.Node)).Replace(pattern)
+}
+
+// fileSafe reduces a user name to characters safe in a file name on any
+// BBS OS: spaces and dots become underscores and other punctuation goes.
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ' || r == '.':
+			return '_'
+		}
+		return -1
+	}, name)
+}
+
+// screenSaver returns the save action for browse: it writes the screen in
+// format ("txt" or "ans") to a new file in dir and says where on the status
+// row. It returns nil when dir is empty, which leaves saving switched off.
+func screenSaver(termCfg terminal.TerminalConfig, dir, format string) func(terminal.Page) {
+	if dir == "" {
+		return nil
+	}
+	return func(page terminal.Page) {
+		name, err := saveScreen(dir, format, page)
+		notice := "Saved as " + name
+		if err != nil {
+			slog.Warn("could not save screen", "dir", dir, "error", err)
+			notice = "Sorry, the screen could not be saved"
+		}
+		terminal.SetStatus(termCfg, "save", notice)
+		time.AfterFunc(saveNoticeTime, func() { terminal.SetStatus(termCfg, "save", "") })
+	}
+}
+
+// saveScreen writes the current screen to dir, named for the page's date and
+// kind, and returns the file name. An existing file is never overwritten; a
+// second save of the same screen gets a number on the end.
+func saveScreen(dir, format string, page terminal.Page) (string, error) {
+	data := terminal.Snapshot(format == "ans")
+	if data == nil {
+		return "", errors.New("nothing on screen to save")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	kind := page.Kind
+	if kind == "" {
+		kind = terminal.KindEvents
+	}
+	base := fmt.Sprintf("history-%s-%s", page.Date.Format("0102"), kind)
+	for n := 1; ; n++ {
+		name := base + "." + format
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.%s", base, n, format)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return name, f.Close()
+	}
+}
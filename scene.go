@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/robbiew/history/internal/seen"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// withScene fits picked, the events screen's picks, to slots, putting one
+// of milestones, the day's scene milestones, first when the strategy left
+// them all out, so a day that has any shows one however few entries fit on
+// screen. One the caller has been shown today isn't forced on them again,
+// and none is when the picks are limited to categories, which a milestone
+// may not be in.
+func withScene(picked, milestones []wikimedia.Event, slots int, date time.Time, opts eventListOptions) []wikimedia.Event {
+	picked = picked[:min(len(picked), slots)]
+	isMilestone := func(e wikimedia.Event) bool {
+		return slices.ContainsFunc(milestones, func(m wikimedia.Event) bool { return m.Year == e.Year && m.Text == e.Text })
+	}
+	if slots <= 0 || len(opts.Categories) > 0 || slices.ContainsFunc(picked, isMilestone) {
+		return picked
+	}
+	unseen := slices.DeleteFunc(slices.Clone(milestones), func(e wikimedia.Event) bool {
+		return opts.Seen.Has(seen.ID(wikimedia.SectionEvents, e.Year, sanitizeText(e.Text)))
+	})
+	if len(unseen) == 0 {
+		return picked
+	}
+	rng := selectionRand(opts.Strategy, date, "scene", opts.DailySalt, opts.Rand)
+	m := unseen[rng.Intn(len(unseen))]
+	return append([]wikimedia.Event{m}, picked[:min(len(picked), slots-1)]...)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/keymap"
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// A view is one screen of the door: the day's pages, the help screen, and
+// whatever else a key can open. Views are kept on a stack; the top one is
+// on screen and gets the keys.
+type view interface {
+	// draw paints the whole screen.
+	draw()
+	// handle acts on a keystroke and its bound action, and says what the
+	// stack should do next.
+	handle(ev input.Event, action keymap.Action) step
+}
+
+// step is a view's answer to a key. The zero step leaves the screen as it is.
+type step struct {
+	push   view // open push on top of this view
+	pop    bool // close this view and go back to the one below
+	redraw bool // draw this view again
+	quit   bool // leave the door
+}
+
+var (
+	stay   = step{}
+	redraw = step{redraw: true}
+	back   = step{pop: true}
+	quit   = step{quit: true}
+)
+
+// open is the step that puts v on top of the stack.
+func open(v view) step {
+	return step{push: v}
+}
+
+// runViews draws root and passes keys to the top of the stack until the
+// caller quits, closes root, or hangs up. It also stops once ctx has ended,
+// rather than waiting for a key in a session that is over. Each key winds
+// idle back.
+func runViews(ctx context.Context, keys *input.Decoder, bindings *keymap.Map, idle *idleClock, root view) error {
+	stack := []view{root}
+	root.draw()
+	for {
+		ev, err := keys.ReadKeyContext(ctx)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		idle.reset()
+		top := stack[len(stack)-1]
+		if ev.Key == input.KeyResize {
+			// Draw the same screen again for the new window
+			terminal.Resize(ev.Cols, ev.Rows)
+			top.draw()
+			continue
+		}
+		s := top.handle(ev, bindings.Lookup(ev))
+		switch {
+		case s.quit:
+			return nil
+		case s.push != nil:
+			stack = append(stack, s.push)
+			s.push.draw()
+		case s.pop:
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return nil
+			}
+			stack[len(stack)-1].draw()
+		case s.redraw:
+			top.draw()
+		}
+	}
+}
+
+// helpView lists the key bindings; any key goes back.
+type helpView struct {
+	termCfg terminal.TerminalConfig
+	entries []terminal.HelpEntry
+}
+
+func (h helpView) draw() {
+	terminal.RenderHelp(h.termCfg, h.entries)
+}
+
+func (h helpView) handle(input.Event, keymap.Action) step {
+	return back
+}
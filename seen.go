@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/internal/seen"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// openSeen opens the caller's log of today's events in dir, named after
+// them. It returns nil, which remembers nothing, when dir is empty or the
+// caller's name has nothing usable in a file name. A log that can't be read
+// is logged and starts empty.
+func openSeen(dir string, session *dropfile.DoorSession) *seen.Log {
+	name := fileSafe(session.UserName)
+	if dir == "" || name == "" {
+		return nil
+	}
+	l, err := seen.Open(filepath.Join(dir, name+".json"), time.Now())
+	if err != nil {
+		slog.Warn("could not read what the caller has seen", "dir", dir, "error", err)
+	}
+	return l
+}
+
+// openShown opens the list of events shown to every caller today, kept in
+// the cache directory dir that all nodes share. A list that can't be read
+// is logged and starts empty.
+func openShown(dir string) *seen.Log {
+	l, err := seen.Open(filepath.Join(dir, "shown.json"), time.Now())
+	if err != nil {
+		slog.Warn("could not read the events shown today", "dir", dir, "error", err)
+	}
+	return l
+}
+
+// pickEvents selects a section's entries as selectEvents does, first from
+// the ones nobody on the board has been shown today, then from ones only
+// other callers have, and last from ones this caller has, as far as each
+// is needed to make up five. With opts.Favorites, up to that many of the
+// best rated that the caller hasn't seen today take the first slots.
+func pickEvents(events []wikimedia.Event, section string, date time.Time, opts eventListOptions) []wikimedia.Event {
+	rng := selectionRand(opts.Strategy, date, section, opts.DailySalt, opts.Rand)
+	events = inCategories(events, opts.Categories)
+	id := func(e wikimedia.Event) string { return seen.ID(section, e.Year, sanitizeText(e.Text)) }
+	unseenByCaller := slices.DeleteFunc(slices.Clone(events), func(e wikimedia.Event) bool { return opts.Seen.Has(id(e)) })
+	picked := favorites(unseenByCaller, section, opts.Ratings, opts.Favorites)
+
+	var fresh, others, own []wikimedia.Event
+	for _, e := range events {
+		switch {
+		case slices.ContainsFunc(picked, func(p wikimedia.Event) bool { return p.Year == e.Year && p.Text == e.Text }):
+		case opts.Seen.Has(id(e)):
+			own = append(own, e)
+		case opts.Board.Has(id(e)):
+			others = append(others, e)
+		default:
+			fresh = append(fresh, e)
+		}
+	}
+	for _, tier := range [][]wikimedia.Event{fresh, others, own} {
+		if len(picked) >= 5 {
+			break
+		}
+		if len(tier) > 0 {
+			sel := selectEvents(tier, opts.Strategy, opts.Shuffle, calendarYear(date), rng)
+			picked = append(picked, sel[:min(5-len(picked), len(sel))]...)
+		}
+	}
+	return picked
+}
+
+// markSeen records events, shown on a page of kind, in l.
+func markSeen(l *seen.Log, kind string, events []terminal.Event) {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = seen.ID(kind, e.Year, e.Key)
+	}
+	if err := l.Add(ids...); err != nil {
+		slog.Warn("could not record what the caller has seen", "error", err)
+	}
+}
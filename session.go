@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robbiew/history/internal/terminal"
+)
+
+// idleClock logs off a caller who has pressed no key for a while. A key
+// winds it back with reset; pause holds it, as while the day loads, until
+// the next reset.
+type idleClock struct {
+	timer *time.Timer
+	after time.Duration
+}
+
+// startIdleClock starts an idleClock that calls expire after a quiet spell
+// of after.
+func startIdleClock(after time.Duration, expire func()) *idleClock {
+	return &idleClock{timer: time.AfterFunc(after, expire), after: after}
+}
+
+// reset starts the quiet spell again.
+func (c *idleClock) reset() {
+	if c != nil {
+		c.timer.Reset(c.after)
+	}
+}
+
+// pause stops the clock until the next reset.
+func (c *idleClock) pause() {
+	if c != nil {
+		c.timer.Stop()
+	}
+}
+
+// startSessionClock counts down the door's own session limit on the status
+// row and calls expire when it runs out. A limit of 0 disables it.
+func startSessionClock(termCfg terminal.TerminalConfig, limit time.Duration, expire func()) {
+	if limit <= 0 {
+		return
+	}
+	countdown(time.Now().Add(limit), sessionCountdown, func(text string) {
+		terminal.SetStatus(termCfg, "session", text)
+	}, expire)
+}
+
+// startTimeLeftClock shows the caller's remaining BBS time from the dropfile
+// in the footer, counting it down live, and calls expire when it runs out.
+// Dropfiles that report no time left get no clock.
+func startTimeLeftClock(termCfg terminal.TerminalConfig, minutes int, expire func()) {
+	if minutes <= 0 {
+		return
+	}
+	countdown(time.Now().Add(time.Duration(minutes)*time.Minute), timeLeftCountdown, func(text string) {
+		terminal.SetFooterStatus(termCfg, "timeleft", text)
+	}, expire)
+}
+
+// countdown calls show with format's text for the time left until deadline,
+// only when the text changes, and calls expire at the deadline.
+func countdown(deadline time.Time, format func(time.Duration) string, show func(string), expire func()) {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		shown := ""
+		for {
+			left := time.Until(deadline)
+			if left <= 0 {
+				expire()
+				return
+			}
+			if text := format(left); text != shown {
+				show(text)
+				shown = text
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// sessionCountdown formats the time left, rounded up, in minutes until the
+// last minute and in seconds after that.
+func sessionCountdown(left time.Duration) string {
+	if left > time.Minute {
+		return fmt.Sprintf("Door time left: %d min", (left+time.Minute-1)/time.Minute)
+	}
+	return fmt.Sprintf("Door time left: %d sec", (left+time.Second-1)/time.Second)
+}
+
+// timeLeftCountdown formats BBS time in whole minutes, rounded up, so the
+// footer changes once a minute.
+func timeLeftCountdown(left time.Duration) string {
+	return fmt.Sprintf(" Time left: %d min ", (left+time.Minute-1)/time.Minute)
+}
+
+// Reasons a session's context ends, as its cause.
+var (
+	errHangUp      = errors.New("caller hung up")
+	errTimeUp      = errors.New("caller's BBS time is up")
+	errSessionOver = errors.New("session over")
+)
+
+// sessionContext returns the context a session's work runs under, so that
+// nothing it starts outlives the caller: fetches and plugin commands are
+// cut off the moment it ends. It ends when the caller hangs up, closing
+// hungUp, at the end of the caller's BBS time when the dropfile gives one,
+// or when end is called with the reason the session is over.
+func sessionContext(minutes int, hungUp <-chan struct{}) (ctx context.Context, end context.CancelCauseFunc) {
+	ctx, end = context.WithCancelCause(context.Background())
+	if minutes > 0 {
+		timed, cancel := context.WithDeadlineCause(ctx, time.Now().Add(time.Duration(minutes)*time.Minute), errTimeUp)
+		context.AfterFunc(ctx, cancel)
+		ctx = timed
+	}
+	go func() {
+		select {
+		case <-hungUp:
+			end(errHangUp)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, end
+}
+
+// onStopSignal calls stop, from its own goroutine, when the door is told to
+// stop: SIGINT, SIGTERM, or SIGHUP, as when the BBS shuts down or the line
+// the caller was on is dropped.
+func onStopSignal(stop func(os.Signal)) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		stop(<-sigs)
+	}()
+}
+
+// cleanups are what exit undoes before the door exits, last first, as
+// deferred calls would be if main returned rather than calling os.Exit:
+// the tty's mode, the node lock, the serial port and the log.
+var (
+	cleanups []func()
+	exiting  sync.Mutex
+)
+
+// atExit has exit call undo before the door exits.
+func atExit(undo func()) {
+	exiting.Lock()
+	defer exiting.Unlock()
+	cleanups = append(cleanups, undo)
+}
+
+// exit undoes what atExit was given and exits with code. Every way out of
+// a session goes through it. A second call, from another goroutine, waits
+// for the first to exit.
+func exit(code int) {
+	exiting.Lock()
+	undo()
+	os.Exit(code)
+}
+
+// undo calls the cleanups, last first. The caller holds exiting.
+func undo() {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	cleanups = nil
+}
+
+// undoOnPanic, deferred in main, undoes what atExit was given when the
+// door crashes, and lets the crash go on.
+func undoOnPanic() {
+	if r := recover(); r != nil {
+		exiting.Lock()
+		undo()
+		panic(r)
+	}
+}
+
+// fail logs err, which ended the session, and exits with status 1.
+func fail(err error) {
+	log.Print(err)
+	exit(1)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/dropfile"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// nodeDirGlobs are where BBS packages usually keep their node directories,
+// searched by the setup wizard. A leading ~ is the sysop's home directory.
+var nodeDirGlobs = []string{
+	"/sbbs/node*", "/sbbs/node/*", "/mystic/temp*", "/bbs/node*", "/bbs/temp*",
+	"~/sbbs/node*", "~/mystic/temp*", "~/bbs/node*", "~/bbs/temp*",
+	"C:/sbbs/node*", "C:/mystic/temp*", "C:/bbs/node*",
+	"node*", "temp*",
+}
+
+// runSetup implements "history setup", which the door also runs on its first
+// launch from a terminal with no config file: it looks for the BBS's node
+// directories, tests the API, asks for the charset, year colors and
+// strategy, and writes the config file. It returns the process exit code.
+func runSetup(args []string) int {
+	fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+	configPath := fs.String("config", config.DefaultPath, "config file to write")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	return setup(*configPath, os.Stdin, os.Stdout)
+}
+
+func setup(configPath string, stdin io.Reader, out io.Writer) int {
+	in := bufio.NewReader(stdin)
+	fmt.Fprintln(out, "This Day in History setup")
+	fmt.Fprintln(out)
+	if fileExists(configPath) {
+		fmt.Fprintf(out, "%s already exists.\n", configPath)
+		if ask(in, out, "Replace it", []string{"no", "yes"}, "no") != "yes" {
+			return 1
+		}
+	} else {
+		fmt.Fprintf(out, "No config file found; this writes %s.\n", configPath)
+	}
+
+	fmt.Fprintln(out, "\nLooking for node directories...")
+	dirs := findNodeDirs()
+	for _, d := range dirs {
+		if session, err := dropfile.Load(d); err == nil {
+			fmt.Fprintf(out, "  %s (%s for node %d)\n", d, session.Format, session.Node)
+		} else {
+			fmt.Fprintf(out, "  %s\n", d)
+		}
+	}
+	if len(dirs) == 0 {
+		fmt.Fprintln(out, "  none found in the usual places; the BBS tells the door its node directory with -path")
+	}
+
+	cfg := config.Default()
+	fmt.Fprintln(out, "\nChecking the cache and the Wikimedia API...")
+	dir := cacheDir(cfg)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintf(out, "  FAIL  cache: %v\n", err)
+	} else {
+		status, detail := checkCacheDir(dir)
+		fmt.Fprintf(out, "  %-4s  cache: %s\n", status, detail)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	start := time.Now()
+	err := wikimedia.NewClient(dir, time.Duration(cfg.CacheTTL)).Ping(ctx)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(out, "  FAIL  network: %v\n", err)
+		fmt.Fprintln(out, "        The door needs to reach api.wikimedia.org; check the firewall before callers try it.")
+	} else {
+		fmt.Fprintf(out, "  PASS  network: reachable in %v\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	fmt.Fprintln(out)
+	settings := map[string]any{
+		"charset":     ask(in, out, "Character set (auto picks from the dropfile)", config.Charsets, cfg.Charset),
+		"year_colors": ask(in, out, "Year colors", []string{"era", "gradient"}, cfg.YearColors),
+		"strategy":    ask(in, out, "Selection strategy", knownStrategies, cfg.Strategy),
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err == nil {
+		err = os.WriteFile(configPath, append(data, '\n'), 0o644)
+	}
+	if err == nil {
+		_, err = config.Load(configPath)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "\ncould not write %s: %v\n", configPath, err)
+		return 1
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "history"
+	}
+	node := "/path/to/node"
+	if len(dirs) > 0 {
+		node = dirs[0]
+	}
+	fmt.Fprintf(out, "\nWrote %s. Every other setting is described in the README.\n", configPath)
+	fmt.Fprintln(out, "Set the door up in your BBS to run it from this directory with the node's directory, as in:")
+	fmt.Fprintf(out, "  %s -path %s\n", exe, node)
+	fmt.Fprintln(out, "using your BBS's placeholder for the node number. To try it here first:")
+	fmt.Fprintf(out, "  %s -local\n", exe)
+	return 0
+}
+
+// stdinIsTerminal reports whether stdin is a terminal, so someone is there
+// to answer the setup wizard.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// findNodeDirs returns the directories matching nodeDirGlobs.
+func findNodeDirs() []string {
+	home, _ := os.UserHomeDir()
+	var found []string
+	for _, glob := range nodeDirGlobs {
+		if rest, ok := strings.CutPrefix(glob, "~/"); ok {
+			if home == "" {
+				continue
+			}
+			glob = filepath.Join(home, rest)
+		}
+		matches, _ := filepath.Glob(glob)
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err != nil || !fi.IsDir() {
+				continue
+			}
+			if abs, err := filepath.Abs(m); err == nil {
+				m = abs
+			}
+			if !slices.Contains(found, m) {
+				found = append(found, m)
+			}
+		}
+	}
+	return found
+}
+
+// ask asks question with its choices until the answer is one of them; an
+// empty answer, or the end of the input, picks def.
+func ask(in *bufio.Reader, out io.Writer, question string, choices []string, def string) string {
+	for {
+		fmt.Fprintf(out, "%s [%s] (%s): ", question, strings.Join(choices, "/"), def)
+		line, err := in.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "" || (err != nil && !slices.Contains(choices, answer)) {
+			if err != nil {
+				fmt.Fprintln(out)
+			}
+			return def
+		}
+		if slices.Contains(choices, answer) {
+			return answer
+		}
+		fmt.Fprintf(out, "  please answer one of %s\n", strings.Join(choices, ", "))
+	}
+}
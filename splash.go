@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/robbiew/history/internal/config"
+	"github.com/robbiew/history/internal/input"
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// showSplash shows the sysop's splash screen as the session starts: for at
+// least its minimum time, and then until ready is closed, once the day has
+// been fetched behind it, unless the caller presses a key first. The key
+// only skips the splash. It returns early once ctx, the session's, has
+// ended.
+func showSplash(ctx context.Context, termCfg terminal.TerminalConfig, keys *input.Decoder, splash config.Splash, ready <-chan struct{}) {
+	art, err := terminal.LoadArt(splash.Art)
+	if err != nil {
+		// Checked by cfg.Validate, but the file may have gone since
+		slog.Warn("could not read the splash screen's art, leaving it out", "file", splash.Art, "error", err)
+		return
+	}
+	terminal.RenderArt(termCfg, art, "CONTINUE")
+
+	ctx, shown := context.WithCancel(ctx)
+	defer shown()
+	go func() {
+		timer := time.NewTimer(time.Duration(splash.MinTime))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-ready:
+			shown()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		ev, err := keys.ReadKeyContext(ctx)
+		if err != nil {
+			return
+		}
+		if ev.Key != input.KeyResize {
+			return
+		}
+		// Not a key; the splash is drawn again for the new window
+		terminal.Resize(ev.Cols, ev.Rows)
+		terminal.RenderArt(termCfg, art, "CONTINUE")
+	}
+}
+
+// warmDay fetches the sections of date into the cache in the background,
+// so the day's screens are ready by the time a splash screen ends, and
+// closes the channel it returns when it is done. Loading the day while it
+// is still fetching waits for the same fetches rather than asking again.
+// When skip is set, as when the cache is bypassed, it fetches nothing.
+func warmDay(ctx context.Context, wikiClient *wikimedia.Client, date time.Time, sections []string, deadline time.Duration, skip bool) <-chan struct{} {
+	ready := make(chan struct{})
+	if skip {
+		close(ready)
+		return ready
+	}
+	go func() {
+		defer close(ready)
+		fetchDay(ctx, wikiClient, fmt.Sprintf("%02d", int(date.Month())), fmt.Sprintf("%02d", date.Day()), sections, false, deadline)
+	}()
+	return ready
+}
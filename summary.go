@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/internal/terminal"
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// summaryDeadline bounds fetching an article's summary for a detail page,
+// which the caller waits on; one that takes longer is left out.
+const summaryDeadline = 4 * time.Second
+
+// summaryFunc returns the lead of the Wikipedia article at link, ready to
+// show, or "" when there is none to be had.
+type summaryFunc func(link string) string
+
+// summarizer returns the summaryFunc for detail pages, fetching through
+// client under ctx, the session's. Summaries are cached by article, so
+// opening an event again, or another caller opening it, reads the cache.
+func summarizer(ctx context.Context, termCfg terminal.TerminalConfig, client *wikimedia.Client) summaryFunc {
+	return func(link string) string {
+		title := articleTitle(link)
+		if title == "" {
+			return ""
+		}
+		terminal.SetStatus(termCfg, "summary", "Loading article...")
+		defer terminal.SetStatus(termCfg, "summary", "")
+		ctx, cancel := context.WithTimeout(ctx, summaryDeadline)
+		defer cancel()
+		res, err := client.FetchSummary(ctx, title, false)
+		if err != nil {
+			slog.Warn("could not fetch article summary", "title", title, "error", err)
+			return ""
+		}
+		if len(res.Events) == 0 {
+			return ""
+		}
+		return displayText(res.Events[0].Text, termCfg.Charset)
+	}
+}
+
+// articleTitle is the title of the article at a /wiki/ link, or "" for
+// any other link.
+func articleTitle(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	_, title, ok := strings.Cut(u.Path, "/wiki/")
+	if !ok {
+		return ""
+	}
+	return title
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robbiew/history/pkg/wikimedia"
+)
+
+// Build information, set at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%d)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// releasesURL is the GitHub API endpoint for the newest published release.
+const releasesURL = "https://api.github.com/repos/robbiew/This-Day-in-History-Door/releases/latest"
+
+// versionString describes this build on one line.
+func versionString() string {
+	rev, date := buildStamp()
+	out := "history " + version
+	if rev != "" {
+		out += " (" + rev
+		if date != "" {
+			out += ", " + date
+		}
+		out += ")"
+	}
+	return out + " " + runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// buildStamp returns the commit this build is from and when it was made.
+// When they weren't injected with -ldflags, the VCS stamp recorded by the Go
+// toolchain is used; either may be empty.
+func buildStamp() (rev, date string) {
+	rev, date = commit, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && rev == "":
+				rev = s.Value
+				if len(rev) > 7 {
+					rev = rev[:7]
+				}
+			case s.Key == "vcs.time" && date == "":
+				date = s.Value
+			}
+		}
+	}
+	return rev, date
+}
+
+// runUpdateCheck implements "history update-check": it asks GitHub for the
+// latest release and reports whether it is newer than this build. It never
+// downloads or installs anything. It returns the process exit code.
+func runUpdateCheck(args []string) int {
+	fs := flag.NewFlagSet("update-check", flag.ContinueOnError)
+	timeoutPtr := fs.Duration("timeout", 10*time.Second, "how long to wait for GitHub")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeoutPtr)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", releasesURL, nil)
+	if err != nil {
+		fmt.Printf("update check failed: %v\n", err)
+		return 1
+	}
+	req.Header.Set("User-Agent", "Go Day-in-History BBS Door/1.0 (github.com/robbiew/history)")
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := wikimedia.HTTPClient().Do(req)
+	if err != nil {
+		fmt.Printf("update check failed: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("update check failed: GitHub returned status code: %d\n", resp.StatusCode)
+		return 1
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		fmt.Printf("update check failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("installed: %s\nlatest:    %s\n", version, release.TagName)
+	switch newer, ok := isNewerVersion(release.TagName, version); {
+	case !ok:
+		fmt.Printf("cannot compare against a %q build; see %s\n", version, release.HTMLURL)
+	case newer:
+		fmt.Printf("a newer release is available: %s\n", release.HTMLURL)
+	default:
+		fmt.Println("you are running the latest release")
+	}
+	return 0
+}
+
+// isNewerVersion reports whether latest is a higher semantic version than
+// current. ok is false when either isn't a vMAJOR.MINOR.PATCH style version.
+func isNewerVersion(latest, current string) (newer, ok bool) {
+	l, lok := parseVersion(latest)
+	c, cok := parseVersion(current)
+	if !lok || !cok {
+		return false, false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], true
+		}
+	}
+	return false, true
+}
+
+// parseVersion splits "v1.2.3" (or "1.2", pre-release suffix ignored) into numbers.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}